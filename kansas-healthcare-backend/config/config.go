@@ -1,34 +1,97 @@
 package config
 
 import (
+	"fmt"
 	"os"
+	"time"
 )
 
 type Config struct {
-	Port       string
-	DataSource string // "json" or "db"
-	DBHost     string
-	DBPort     string
-	DBUser     string
-	DBPassword string
-	DBName     string
+	Port               string
+	DataSource         string            // "json" or "db"
+	DataDir            string            // Directory holding the JSON datasets; empty uses ./data with the embedded dataset as fallback
+	DataFiles          map[string]string // Per-dataset file overrides keyed by file name, e.g. "providers.json"
+	DataReloadInterval time.Duration     // How often JSON data files are polled for changes; 0 disables reloading
+	AllowDegradedData  bool              // Start without optional datasets (county areas, density standards) if they fail to load
+	DBDriver           string            // "postgres" or "sqlite"
+	DBHost             string
+	DBPort             string
+	DBUser             string
+	DBPassword         string
+	DBName             string
+	DBSSLMode          string
+	DBPath             string // SQLite database file, used when DBDriver is "sqlite"
+	DBSeed             bool   // Import the JSON dataset into an empty database on startup
 }
 
 func Load() *Config {
 	return &Config{
-		Port:       getEnv("PORT", "8080"),
-		DataSource: getEnv("DATA_SOURCE", "json"),
-		DBHost:     getEnv("DB_HOST", "localhost"),
-		DBPort:     getEnv("DB_PORT", "5432"),
-		DBUser:     getEnv("DB_USER", "postgres"),
-		DBPassword: getEnv("DB_PASSWORD", "password"),
-		DBName:     getEnv("DB_NAME", "healthcare_network"),
+		Port:               getEnv("PORT", "8080"),
+		DataSource:         getEnv("DATA_SOURCE", "json"),
+		DataDir:            getEnv("DATA_DIR", ""),
+		DataFiles:          getDataFileOverrides(),
+		DataReloadInterval: getEnvDuration("DATA_RELOAD_INTERVAL", 30*time.Second),
+		AllowDegradedData:  getEnv("DATA_ALLOW_DEGRADED", "false") == "true",
+		DBDriver:           getEnv("DB_DRIVER", "postgres"),
+		DBHost:             getEnv("DB_HOST", "localhost"),
+		DBPort:             getEnv("DB_PORT", "5432"),
+		DBUser:             getEnv("DB_USER", "postgres"),
+		DBPassword:         getEnv("DB_PASSWORD", "password"),
+		DBName:             getEnv("DB_NAME", "healthcare_network"),
+		DBSSLMode:          getEnv("DB_SSLMODE", "disable"),
+		DBPath:             getEnv("DB_PATH", "kansas_healthcare.db"),
+		DBSeed:             getEnv("DB_SEED", "false") == "true",
 	}
 }
 
+// DatabaseDSN builds the connection string for the configured database driver
+func (c *Config) DatabaseDSN() string {
+	if c.DBDriver == "sqlite" {
+		return c.DBPath
+	}
+	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		c.DBHost, c.DBPort, c.DBUser, c.DBPassword, c.DBName, c.DBSSLMode)
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
 	}
 	return defaultValue
 }
+
+// dataFileEnv names the environment variable that overrides the location of each dataset
+var dataFileEnv = map[string]string{
+	"providers.json":                   "DATA_PROVIDERS_FILE",
+	"provider_networks.json":           "DATA_PROVIDER_NETWORKS_FILE",
+	"provider_service_locations.json":  "DATA_PROVIDER_SERVICE_LOCATIONS_FILE",
+	"claims.json":                      "DATA_CLAIMS_FILE",
+	"county_areas.json":                "DATA_COUNTY_AREAS_FILE",
+	"specialty_density_standards.json": "DATA_SPECIALTY_DENSITY_STANDARDS_FILE",
+	"adequacy_standards.json":          "DATA_ADEQUACY_STANDARDS_FILE",
+	"county_designations.json":         "DATA_COUNTY_DESIGNATIONS_FILE",
+	"county_boundaries.json":           "DATA_COUNTY_BOUNDARIES_FILE",
+	"county_population.json":           "DATA_COUNTY_POPULATION_FILE",
+	"zip_centroids.json":               "DATA_ZIP_CENTROIDS_FILE",
+	"city_centroids.json":              "DATA_CITY_CENTROIDS_FILE",
+	"out_of_state_locations.json":      "DATA_OUT_OF_STATE_LOCATIONS_FILE",
+}
+
+func getDataFileOverrides() map[string]string {
+	overrides := make(map[string]string)
+	for file, key := range dataFileEnv {
+		if path := os.Getenv(key); path != "" {
+			overrides[file] = path
+		}
+	}
+	return overrides
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if duration, err := time.ParseDuration(value); err == nil {
+			return duration
+		}
+	}
+	return defaultValue
+}
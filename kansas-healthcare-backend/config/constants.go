@@ -13,10 +13,157 @@ const (
 	
 	// Network termination reason
 	LeftNetworkReason = "Left Network"
+
+	// Breakdown labels for affiliations terminated without a reason, and for providers counted
+	// as terminated because they have no affiliation with the network at all
+	UnspecifiedReason   = "Unspecified"
+	NoAffiliationReason = "No Affiliation"
+)
+
+// Denominators for termination percentages. Both count distinct providers affiliated with the
+// network: at any time during the analysis window, or on the day the window opened.
+const (
+	DenominatorInWindow      = "in_window"
+	DenominatorAtWindowStart = "at_window_start"
+
+	// DenominatorLegacy marks percentages computed the original way: the statewide count over
+	// every active provider times 100, the county count divided by 100
+	DenominatorLegacy = "legacy"
+)
+
+// Bounding box of the state of Kansas, used to flag implausible service location coordinates
+const (
+	KansasMinLatitude  = 36.99
+	KansasMaxLatitude  = 40.01
+	KansasMinLongitude = -102.06
+	KansasMaxLongitude = -94.58
+)
+
+// Coordinate sources of service locations geocoded from the offline centroid tables
+const (
+	CoordinateSourceZip  = "zip_centroid"
+	CoordinateSourceCity = "city_centroid"
+)
+
+// County designations used by network adequacy standards, most to least densely populated
+const (
+	DesignationLargeMetro = "Large Metro"
+	DesignationMetro      = "Metro"
+	DesignationMicro      = "Micro"
+	DesignationRural      = "Rural"
+	DesignationCEAC       = "CEAC"
+)
+
+// Network adequacy evaluation. A county passes for a specialty when at least AdequacyMinPercentage
+// of the points sampled across it lie within the distance standard of an in-network provider;
+// AdequacySampleGridSize is the number of points along each side of the sampling grid.
+const (
+	AdequacyMinPercentage  = 90.0
+	AdequacySampleGridSize = 5
+)
+
+// Number of service locations returned by the nearest-provider lookup by default, and at most
+const (
+	DefaultNearestProviderLimit = 10
+	MaxNearestProviderLimit     = 100
+)
+
+// Coverage gap grid analysis: the default side of each grid cell and the range allowed, and the
+// default and largest distance to the nearest provider beyond which a cell counts as a gap, all
+// in miles
+const (
+	DefaultCoverageCellMiles = 10.0
+	MinCoverageCellMiles     = 1.0
+	MaxCoverageCellMiles     = 50.0
+	DefaultCoverageGapMiles  = 30.0
+	MaxCoverageGapMiles      = 500.0
+)
+
+// Distance decay functions the two-step floating catchment area (2SFCA) accessibility index
+// weighs supply and demand by: step counts everything in the catchment equally (the original
+// 2SFCA), linear falls to zero at its edge and gaussian falls off gently near the centre and
+// steeply towards the edge
+const (
+	DecayStep     = "step"
+	DecayLinear   = "linear"
+	DecayGaussian = "gaussian"
+)
+
+// Accessibility index: the default and largest catchment radius in miles and the default decay
+const (
+	DefaultAccessibilityCatchmentMiles = 30.0
+	MaxAccessibilityCatchmentMiles     = 120.0
+	DefaultAccessibilityDecay          = DecayGaussian
+)
+
+// Recruitment site optimization: the default and largest number of sites to pick, the default and
+// largest distance in miles a site serves residents within, and the kinds of candidate site
+const (
+	DefaultRecruitmentSites       = 5
+	MaxRecruitmentSites           = 25
+	DefaultRecruitmentMiles       = 30.0
+	MaxRecruitmentMiles           = 120.0
+	RecruitmentCandidateProviders = "providers"
+	RecruitmentCandidateZips      = "zips"
+)
+
+// PopulationDemandGridSize is the size of the grid of points each county's population is spread
+// over when measuring access and coverage
+const PopulationDemandGridSize = 5
+
+// States bordering Kansas. Members in border counties cross into them for care, so the
+// out-of-state provider dataset lists locations there.
+const (
+	StateColorado = "CO"
+	StateMissouri = "MO"
+	StateNebraska = "NE"
+	StateOklahoma = "OK"
+)
+
+// Intervals a network time series is bucketed into, and the attributes network memberships can be
+// grouped by: time series split by county or specialty, retention curves also by network
+const (
+	IntervalMonth    = "month"
+	IntervalQuarter  = "quarter"
+	IntervalYear     = "year"
+	GroupByNetwork   = "network"
+	GroupByCounty    = "county"
+	GroupBySpecialty = "specialty"
+)
+
+// Network time series: the months covered when no start date is given, and the most periods one
+// request may cover
+const (
+	DefaultTimeSeriesMonths = 24
+	MaxTimeSeriesPeriods    = 600
+)
+
+// RetentionConfidenceLevel is the confidence level of the intervals around provider retention
+// curves and median tenures. RetentionConfidenceZ is the matching standard normal quantile.
+const (
+	RetentionConfidenceLevel = 0.95
+	RetentionConfidenceZ     = 1.959964
 )
 
 // GetTerminatedAnalysisTimeRange returns the time range for terminated analysis
 func GetTerminatedAnalysisTimeRange() (time.Time, time.Time) {
-	now := time.Now()
-	return now.AddDate(-TerminatedAnalysisMaxYears, 0, 0), now.AddDate(-TerminatedAnalysisMinYears, 0, 0)
+	return TerminatedAnalysisTimeRange(time.Now())
+}
+
+// TerminatedAnalysisTimeRange returns the terminated analysis window as seen from asOf
+func TerminatedAnalysisTimeRange(asOf time.Time) (time.Time, time.Time) {
+	return YearsBefore(asOf, TerminatedAnalysisMaxYears, TerminatedAnalysisMinYears)
+}
+
+// YearsBefore returns the window running from maxYears to minYears before asOf
+func YearsBefore(asOf time.Time, maxYears, minYears int) (time.Time, time.Time) {
+	return asOf.AddDate(-maxYears, 0, 0), asOf.AddDate(-minYears, 0, 0)
+}
+
+// AsOfDateFormat is the layout of the as_of query parameter accepted by analytics endpoints
+const AsOfDateFormat = "2006-01-02"
+
+// Today returns the start of the current UTC day, the default as_of date
+func Today() time.Time {
+	return time.Now().UTC().Truncate(24 * time.Hour)
 }
\ No newline at end of file
@@ -1,10 +1,16 @@
 package controllers
 
 import (
+	"errors"
+	"fmt"
+	"kansas-healthcare-api/config"
+	"kansas-healthcare-api/models"
 	"kansas-healthcare-api/services"
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -17,9 +23,111 @@ func NewAnalyticsController(service services.AnalyticsServiceInterface) *Analyti
 	return &AnalyticsController{service: service}
 }
 
+// asOf reads the optional as_of query parameter (YYYY-MM-DD), defaulting to today. An invalid date
+// is answered with 400 and reported as false.
+func asOf(ctx *gin.Context) (time.Time, bool) {
+	value := ctx.Query("as_of")
+	if value == "" {
+		return config.Today(), true
+	}
+	date, err := time.Parse(config.AsOfDateFormat, value)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "as_of must be a date in YYYY-MM-DD format"})
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+// terminationCriteria reads the terminated analysis parameters: as_of, a window given either as
+// from/to dates or as min_years/max_years before as_of (default 2-5 years), a comma separated
+// reasons list (default "Left Network", "all" for any reason), the percentage denominator
+// (in_window or at_window_start) and the legacy_percentage compatibility flag. Invalid parameters
+// are answered with 400 and reported as false.
+func terminationCriteria(ctx *gin.Context) (models.TerminationCriteria, bool) {
+	date, ok := asOf(ctx)
+	if !ok {
+		return models.TerminationCriteria{}, false
+	}
+	criteria := models.TerminationCriteria{AsOf: date, Reasons: []string{config.LeftNetworkReason}}
+
+	fail := func(message string) (models.TerminationCriteria, bool) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": message})
+		return models.TerminationCriteria{}, false
+	}
+
+	years := func(name string, fallback int) (int, bool) {
+		value := ctx.Query(name)
+		if value == "" {
+			return fallback, true
+		}
+		n, err := strconv.Atoi(value)
+		return n, err == nil && n >= 0
+	}
+	minYears, ok := years("min_years", config.TerminatedAnalysisMinYears)
+	if !ok {
+		return fail("min_years must be a non-negative whole number")
+	}
+	maxYears, ok := years("max_years", config.TerminatedAnalysisMaxYears)
+	if !ok {
+		return fail("max_years must be a non-negative whole number")
+	}
+	criteria.From, criteria.To = config.YearsBefore(date, maxYears, minYears)
+
+	from, to := ctx.Query("from"), ctx.Query("to")
+	if (from != "" || to != "") && (ctx.Query("min_years") != "" || ctx.Query("max_years") != "") {
+		return fail("use either from/to or min_years/max_years, not both")
+	}
+	var err error
+	if from != "" {
+		if criteria.From, err = time.Parse(config.AsOfDateFormat, from); err != nil {
+			return fail("from must be a date in YYYY-MM-DD format")
+		}
+	}
+	if to != "" {
+		if criteria.To, err = time.Parse(config.AsOfDateFormat, to); err != nil {
+			return fail("to must be a date in YYYY-MM-DD format")
+		}
+	}
+	if !criteria.From.Before(criteria.To) {
+		return fail("the termination window must start before it ends")
+	}
+
+	var reasons []string
+	for _, value := range ctx.QueryArray("reasons") {
+		for _, reason := range strings.Split(value, ",") {
+			if reason = strings.TrimSpace(reason); reason != "" {
+				reasons = append(reasons, reason)
+			}
+		}
+	}
+	switch {
+	case len(reasons) == 1 && strings.EqualFold(reasons[0], "all"):
+		criteria.Reasons = nil
+	case len(reasons) > 0:
+		criteria.Reasons = reasons
+	}
+
+	switch denominator := ctx.DefaultQuery("denominator", config.DenominatorInWindow); denominator {
+	case config.DenominatorInWindow, config.DenominatorAtWindowStart:
+		criteria.Denominator = denominator
+	default:
+		return fail("denominator must be " + config.DenominatorInWindow + " or " + config.DenominatorAtWindowStart)
+	}
+	if value := ctx.Query("legacy_percentage"); value != "" {
+		if criteria.LegacyPercentage, err = strconv.ParseBool(value); err != nil {
+			return fail("legacy_percentage must be true or false")
+		}
+	}
+	return criteria, true
+}
+
 func (c *AnalyticsController) GetAllCountyData(ctx *gin.Context) {
+	date, ok := asOf(ctx)
+	if !ok {
+		return
+	}
 	log.Printf("[INFO] Getting all county data")
-	data, err := c.service.GetAllCountyData()
+	data, err := c.service.GetAllCountyData(date)
 	if err != nil {
 		log.Printf("[ERROR] Failed to get county data: %v", err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -31,9 +139,13 @@ func (c *AnalyticsController) GetAllCountyData(ctx *gin.Context) {
 
 func (c *AnalyticsController) GetCountyData(ctx *gin.Context) {
 	county := ctx.Param("county")
+	date, ok := asOf(ctx)
+	if !ok {
+		return
+	}
 	log.Printf("[INFO] Getting data for county: %s", county)
 
-	data, err := c.service.GetCountyData(county)
+	data, err := c.service.GetCountyData(county, date)
 	if err != nil {
 		log.Printf("[ERROR] Failed to get data for county %s: %v", county, err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -52,12 +164,20 @@ func (c *AnalyticsController) GetCountyData(ctx *gin.Context) {
 
 func (c *AnalyticsController) GetRecommendations(ctx *gin.Context) {
 	county := ctx.Param("county")
-	recommendations := c.service.GetRecommendations(county)
+	date, ok := asOf(ctx)
+	if !ok {
+		return
+	}
+	recommendations := c.service.GetRecommendations(county, date)
 	ctx.JSON(http.StatusOK, recommendations)
 }
 
 func (c *AnalyticsController) GetActiveProviderCount(ctx *gin.Context) {
-	count, err := c.service.GetActiveProviderCount()
+	date, ok := asOf(ctx)
+	if !ok {
+		return
+	}
+	count, err := c.service.GetActiveProviderCount(date)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -72,8 +192,12 @@ func (c *AnalyticsController) GetTerminatedNetworkAnalysis(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "network_id query parameter is required"})
 		return
 	}
+	criteria, ok := terminationCriteria(ctx)
+	if !ok {
+		return
+	}
 
-	result, err := c.service.GetTerminatedNetworkAnalysis(networkId)
+	result, err := c.service.GetTerminatedNetworkAnalysis(networkId, criteria)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -89,8 +213,12 @@ func (c *AnalyticsController) GetCountyTerminatedNetworkAnalysis(ctx *gin.Contex
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "network_id query parameter is required"})
 		return
 	}
+	criteria, ok := terminationCriteria(ctx)
+	if !ok {
+		return
+	}
 
-	result, err := c.service.GetCountyTerminatedNetworkAnalysis(county, networkId)
+	result, err := c.service.GetCountyTerminatedNetworkAnalysis(county, networkId, criteria)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -100,8 +228,12 @@ func (c *AnalyticsController) GetCountyTerminatedNetworkAnalysis(ctx *gin.Contex
 
 func (c *AnalyticsController) GetSpecialtyDensityAnalysis(ctx *gin.Context) {
 	county := ctx.Param("county")
+	date, ok := asOf(ctx)
+	if !ok {
+		return
+	}
 
-	result, err := c.service.GetSpecialtyDensityAnalysis(county)
+	result, err := c.service.GetSpecialtyDensityAnalysis(county, date)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -109,6 +241,38 @@ func (c *AnalyticsController) GetSpecialtyDensityAnalysis(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, result)
 }
 
+// pointQuery reads the optional lat and lng query parameters, which must be given together. A
+// missing pair is reported as nil; an invalid one is answered with 400 and reported as false.
+func pointQuery(ctx *gin.Context) (*models.Coordinate, bool) {
+	lat, lng := ctx.Query("lat"), ctx.Query("lng")
+	if lat == "" && lng == "" {
+		return nil, true
+	}
+	latitude, latErr := strconv.ParseFloat(lat, 64)
+	longitude, lngErr := strconv.ParseFloat(lng, 64)
+	if latErr != nil || lngErr != nil || latitude < -90 || latitude > 90 || longitude < -180 || longitude > 180 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "lat and lng must be given together as decimal degrees"})
+		return nil, false
+	}
+	return &models.Coordinate{Latitude: latitude, Longitude: longitude}, true
+}
+
+// outOfStateQuery reads the include_out_of_state flag, which adds the providers in neighboring
+// states to radius, nearest-provider and adequacy lookups. It responds 400 and returns false when
+// the flag is not a boolean.
+func outOfStateQuery(ctx *gin.Context) (bool, bool) {
+	value := ctx.Query("include_out_of_state")
+	if value == "" {
+		return false, true
+	}
+	include, err := strconv.ParseBool(value)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "include_out_of_state must be true or false"})
+		return false, false
+	}
+	return include, true
+}
+
 func (c *AnalyticsController) GetRadiusAnalysis(ctx *gin.Context) {
 	county := ctx.Param("county")
 	radius := ctx.DefaultQuery("radius", "25")
@@ -118,19 +282,327 @@ func (c *AnalyticsController) GetRadiusAnalysis(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "network query parameter is required"})
 		return
 	}
-
-	// Convert radius to int
-	radiusInt := 25
-	if radius != "" {
-		if r, err := strconv.Atoi(radius); err == nil {
-			radiusInt = r
-		}
+	radiusInt, err := strconv.Atoi(radius)
+	if err != nil || radiusInt <= 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "radius must be a positive whole number of miles"})
+		return
+	}
+	center, ok := pointQuery(ctx)
+	if !ok {
+		return
+	}
+	date, ok := asOf(ctx)
+	if !ok {
+		return
+	}
+	includeOutOfState, ok := outOfStateQuery(ctx)
+	if !ok {
+		return
 	}
 
-	result, err := c.service.GetRadiusAnalysis(county, radiusInt, network)
+	result, err := c.service.GetRadiusAnalysis(county, center, radiusInt, network, date, includeOutOfState)
+	if errors.Is(err, services.ErrNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 	ctx.JSON(http.StatusOK, result)
 }
+
+// GetNetworkAdequacy reports which counties meet the time and distance standards, optionally
+// narrowed to one county, specialty or network
+func (c *AnalyticsController) GetNetworkAdequacy(ctx *gin.Context) {
+	date, ok := asOf(ctx)
+	if !ok {
+		return
+	}
+	includeOutOfState, ok := outOfStateQuery(ctx)
+	if !ok {
+		return
+	}
+	filter := models.AdequacyFilter{
+		County:            ctx.Query("county"),
+		Specialty:         ctx.Query("specialty"),
+		Network:           ctx.Query("network"),
+		AsOf:              date,
+		IncludeOutOfState: includeOutOfState,
+	}
+
+	report, err := c.service.GetNetworkAdequacy(filter)
+	if errors.Is(err, services.ErrNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, report)
+}
+
+// GetCountyBoundary returns a county's centroid and boundary polygons
+func (c *AnalyticsController) GetCountyBoundary(ctx *gin.Context) {
+	boundary, err := c.service.GetCountyBoundary(ctx.Param("county"))
+	if errors.Is(err, services.ErrNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, boundary)
+}
+
+// GetCountyDataGeoJSON returns the statistics of every county as a GeoJSON FeatureCollection of
+// county outlines
+func (c *AnalyticsController) GetCountyDataGeoJSON(ctx *gin.Context) {
+	date, ok := asOf(ctx)
+	if !ok {
+		return
+	}
+	collection, err := c.service.GetCountyFeatures(date)
+	if err != nil {
+		log.Printf("[ERROR] Failed to build county GeoJSON: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.Header("Content-Type", services.GeoJSONContentType)
+	ctx.JSON(http.StatusOK, collection)
+}
+
+// milesQuery reads an optional distance in miles, which must lie within [min, max]. An invalid
+// value is answered with 400 and reported as false.
+func milesQuery(ctx *gin.Context, name string, fallback, min, max float64) (float64, bool) {
+	value := ctx.Query(name)
+	if value == "" {
+		return fallback, true
+	}
+	miles, err := strconv.ParseFloat(value, 64)
+	if err != nil || miles < min || miles > max {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s must be a number of miles from %g to %g", name, min, max)})
+		return 0, false
+	}
+	return miles, true
+}
+
+// GetCoverageGaps returns the grid cells further than threshold_miles from an in-network provider
+// of the specialty as a GeoJSON FeatureCollection, with per-county summaries
+func (c *AnalyticsController) GetCoverageGaps(ctx *gin.Context) {
+	network := ctx.Query("network")
+	if network == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "network query parameter is required"})
+		return
+	}
+	cellMiles, ok := milesQuery(ctx, "cell_miles", config.DefaultCoverageCellMiles, config.MinCoverageCellMiles, config.MaxCoverageCellMiles)
+	if !ok {
+		return
+	}
+	threshold, ok := milesQuery(ctx, "threshold_miles", config.DefaultCoverageGapMiles, 0, config.MaxCoverageGapMiles)
+	if !ok {
+		return
+	}
+	date, ok := asOf(ctx)
+	if !ok {
+		return
+	}
+
+	query := models.CoverageGapQuery{
+		Network:        network,
+		Specialty:      ctx.DefaultQuery("specialty", "All"),
+		CellMiles:      cellMiles,
+		ThresholdMiles: threshold,
+		AsOf:           date,
+	}
+	report, err := c.service.GetCoverageGaps(query)
+	if err != nil {
+		writeError(ctx, "analyze coverage gaps", err)
+		return
+	}
+	ctx.Header("Content-Type", services.GeoJSONContentType)
+	ctx.JSON(http.StatusOK, report)
+}
+
+// GetAccessibility returns the 2SFCA accessibility score of each county, optionally narrowed to
+// one county and network, for a specialty
+func (c *AnalyticsController) GetAccessibility(ctx *gin.Context) {
+	catchment, ok := milesQuery(ctx, "catchment_miles", config.DefaultAccessibilityCatchmentMiles, 1, config.MaxAccessibilityCatchmentMiles)
+	if !ok {
+		return
+	}
+	date, ok := asOf(ctx)
+	if !ok {
+		return
+	}
+
+	query := models.AccessibilityQuery{
+		County:         ctx.Query("county"),
+		Specialty:      ctx.DefaultQuery("specialty", "All"),
+		Network:        ctx.Query("network"),
+		CatchmentMiles: catchment,
+		Decay:          ctx.DefaultQuery("decay", config.DefaultAccessibilityDecay),
+		AsOf:           date,
+	}
+	report, err := c.service.GetAccessibility(query)
+	if err != nil {
+		writeError(ctx, "compute accessibility", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, report)
+}
+
+// GetRecruitmentSites returns the candidate sites that would bring the most residents within
+// max_miles of a network provider of the specialty, best first
+func (c *AnalyticsController) GetRecruitmentSites(ctx *gin.Context) {
+	network := ctx.Query("network")
+	if network == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "network query parameter is required"})
+		return
+	}
+	maxMiles, ok := milesQuery(ctx, "max_miles", config.DefaultRecruitmentMiles, 1, config.MaxRecruitmentMiles)
+	if !ok {
+		return
+	}
+	sites := config.DefaultRecruitmentSites
+	if value := ctx.Query("sites"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > config.MaxRecruitmentSites {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("sites must be a whole number from 1 to %d", config.MaxRecruitmentSites)})
+			return
+		}
+		sites = n
+	}
+	date, ok := asOf(ctx)
+	if !ok {
+		return
+	}
+
+	query := models.RecruitmentQuery{
+		Specialty:  ctx.DefaultQuery("specialty", "All"),
+		Network:    network,
+		MaxMiles:   maxMiles,
+		Sites:      sites,
+		Candidates: ctx.DefaultQuery("candidates", config.RecruitmentCandidateProviders),
+		AsOf:       date,
+	}
+	plan, err := c.service.GetRecruitmentSites(query)
+	if err != nil {
+		writeError(ctx, "optimize recruitment sites", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, plan)
+}
+
+// GetCatchments returns the Voronoi catchment of each network service location of the specialty
+// as GeoJSON, with the area and population it serves
+func (c *AnalyticsController) GetCatchments(ctx *gin.Context) {
+	network := ctx.Query("network")
+	if network == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "network query parameter is required"})
+		return
+	}
+	date, ok := asOf(ctx)
+	if !ok {
+		return
+	}
+
+	query := models.CatchmentQuery{
+		Network:   network,
+		Specialty: ctx.DefaultQuery("specialty", "All"),
+		AsOf:      date,
+	}
+	report, err := c.service.GetCatchments(query)
+	if err != nil {
+		writeError(ctx, "draw catchments", err)
+		return
+	}
+	ctx.Header("Content-Type", services.GeoJSONContentType)
+	ctx.JSON(http.StatusOK, report)
+}
+
+// GetNetworkTimeSeries returns the network's active provider count, joins and terminations per
+// period between from and to (default the last two years up to today), optionally split by county
+// or specialty
+func (c *AnalyticsController) GetNetworkTimeSeries(ctx *gin.Context) {
+	query := models.TimeSeriesQuery{
+		Network:  ctx.Param("network"),
+		To:       config.Today(),
+		Interval: ctx.DefaultQuery("interval", config.IntervalMonth),
+		GroupBy:  ctx.Query("group_by"),
+	}
+	var err error
+	if value := ctx.Query("to"); value != "" {
+		if query.To, err = time.Parse(config.AsOfDateFormat, value); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "to must be a date in YYYY-MM-DD format"})
+			return
+		}
+	}
+	query.From = query.To.AddDate(0, 1-config.DefaultTimeSeriesMonths, 0)
+	if value := ctx.Query("from"); value != "" {
+		if query.From, err = time.Parse(config.AsOfDateFormat, value); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "from must be a date in YYYY-MM-DD format"})
+			return
+		}
+	}
+
+	report, err := c.service.GetNetworkTimeSeries(query)
+	if err != nil {
+		writeError(ctx, "chart network membership", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, report)
+}
+
+// GetRetention returns Kaplan-Meier provider retention curves for the network, or every network,
+// stratified by network, county or specialty
+func (c *AnalyticsController) GetRetention(ctx *gin.Context) {
+	date, ok := asOf(ctx)
+	if !ok {
+		return
+	}
+
+	query := models.RetentionQuery{
+		Network:    ctx.Query("network"),
+		StratifyBy: ctx.DefaultQuery("stratify_by", config.GroupByNetwork),
+		AsOf:       date,
+	}
+	report, err := c.service.GetRetention(query)
+	if err != nil {
+		writeError(ctx, "estimate provider retention", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, report)
+}
+
+// GetNetworkCohorts returns the network's cohort retention matrix by join year, as JSON or, with
+// format=csv, as a CSV table
+func (c *AnalyticsController) GetNetworkCohorts(ctx *gin.Context) {
+	format := ctx.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or csv"})
+		return
+	}
+	date, ok := asOf(ctx)
+	if !ok {
+		return
+	}
+
+	report, err := c.service.GetNetworkCohorts(models.CohortQuery{Network: ctx.Param("network"), AsOf: date})
+	if err != nil {
+		writeError(ctx, "build network cohorts", err)
+		return
+	}
+	if format == "json" {
+		ctx.JSON(http.StatusOK, report)
+		return
+	}
+	body, err := services.CohortCSV(report)
+	if err != nil {
+		writeError(ctx, "render network cohorts", err)
+		return
+	}
+	ctx.Data(http.StatusOK, services.CSVContentType, body)
+}
@@ -2,10 +2,14 @@ package controllers
 
 import (
 	"encoding/json"
+	"fmt"
+	"kansas-healthcare-api/config"
 	"kansas-healthcare-api/models"
+	"kansas-healthcare-api/services"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -16,46 +20,97 @@ type MockAnalyticsService struct {
 	mock.Mock
 }
 
-func (m *MockAnalyticsService) GetAllCountyData() ([]models.CountyStats, error) {
-	args := m.Called()
+func (m *MockAnalyticsService) GetAllCountyData(asOf time.Time) ([]models.CountyStats, error) {
+	args := m.Called(asOf)
 	return args.Get(0).([]models.CountyStats), args.Error(1)
 }
 
-func (m *MockAnalyticsService) GetCountyData(county string) (*models.CountyStats, error) {
-	args := m.Called(county)
+func (m *MockAnalyticsService) GetCountyData(county string, asOf time.Time) (*models.CountyStats, error) {
+	args := m.Called(county, asOf)
 	return args.Get(0).(*models.CountyStats), args.Error(1)
 }
 
-func (m *MockAnalyticsService) GetRecommendations(county string) []models.Recommendation {
-	args := m.Called(county)
+func (m *MockAnalyticsService) GetRecommendations(county string, asOf time.Time) []models.Recommendation {
+	args := m.Called(county, asOf)
 	return args.Get(0).([]models.Recommendation)
 }
 
-func (m *MockAnalyticsService) GetActiveProviderCount() (int, error) {
-	args := m.Called()
+func (m *MockAnalyticsService) GetActiveProviderCount(asOf time.Time) (int, error) {
+	args := m.Called(asOf)
 	return args.Int(0), args.Error(1)
 }
 
-func (m *MockAnalyticsService) GetTerminatedNetworkAnalysis(networkId string) (*models.TerminatedAnalysisResult, error) {
-	args := m.Called(networkId)
+func (m *MockAnalyticsService) GetTerminatedNetworkAnalysis(networkId string, criteria models.TerminationCriteria) (*models.TerminatedAnalysisResult, error) {
+	args := m.Called(networkId, criteria)
 	return args.Get(0).(*models.TerminatedAnalysisResult), args.Error(1)
 }
 
-func (m *MockAnalyticsService) GetCountyTerminatedNetworkAnalysis(county, networkId string) (*models.TerminatedAnalysisResult, error) {
-	args := m.Called(county, networkId)
+func (m *MockAnalyticsService) GetCountyTerminatedNetworkAnalysis(county, networkId string, criteria models.TerminationCriteria) (*models.TerminatedAnalysisResult, error) {
+	args := m.Called(county, networkId, criteria)
 	return args.Get(0).(*models.TerminatedAnalysisResult), args.Error(1)
 }
 
-func (m *MockAnalyticsService) GetSpecialtyDensityAnalysis(county string) (map[string]interface{}, error) {
-	args := m.Called(county)
+func (m *MockAnalyticsService) GetSpecialtyDensityAnalysis(county string, asOf time.Time) (map[string]interface{}, error) {
+	args := m.Called(county, asOf)
 	return args.Get(0).(map[string]interface{}), args.Error(1)
 }
 
-func (m *MockAnalyticsService) GetRadiusAnalysis(county string, radius int, networkId string) (map[string]interface{}, error) {
-	args := m.Called(county, radius, networkId)
+func (m *MockAnalyticsService) GetRadiusAnalysis(county string, center *models.Coordinate, radius int, networkId string, asOf time.Time, includeOutOfState bool) (map[string]interface{}, error) {
+	args := m.Called(county, center, radius, networkId, asOf, includeOutOfState)
 	return args.Get(0).(map[string]interface{}), args.Error(1)
 }
 
+func (m *MockAnalyticsService) GetNetworkAdequacy(filter models.AdequacyFilter) (*models.AdequacyReport, error) {
+	args := m.Called(filter)
+	return args.Get(0).(*models.AdequacyReport), args.Error(1)
+}
+
+func (m *MockAnalyticsService) GetCountyFeatures(asOf time.Time) (*models.FeatureCollection, error) {
+	args := m.Called(asOf)
+	return args.Get(0).(*models.FeatureCollection), args.Error(1)
+}
+
+func (m *MockAnalyticsService) GetCoverageGaps(query models.CoverageGapQuery) (*models.CoverageGapReport, error) {
+	args := m.Called(query)
+	report, _ := args.Get(0).(*models.CoverageGapReport)
+	return report, args.Error(1)
+}
+
+func (m *MockAnalyticsService) GetAccessibility(query models.AccessibilityQuery) (*models.AccessibilityReport, error) {
+	args := m.Called(query)
+	return args.Get(0).(*models.AccessibilityReport), args.Error(1)
+}
+
+func (m *MockAnalyticsService) GetRecruitmentSites(query models.RecruitmentQuery) (*models.RecruitmentPlan, error) {
+	args := m.Called(query)
+	return args.Get(0).(*models.RecruitmentPlan), args.Error(1)
+}
+
+func (m *MockAnalyticsService) GetCatchments(query models.CatchmentQuery) (*models.CatchmentReport, error) {
+	args := m.Called(query)
+	return args.Get(0).(*models.CatchmentReport), args.Error(1)
+}
+
+func (m *MockAnalyticsService) GetNetworkTimeSeries(query models.TimeSeriesQuery) (*models.TimeSeriesReport, error) {
+	args := m.Called(query)
+	return args.Get(0).(*models.TimeSeriesReport), args.Error(1)
+}
+
+func (m *MockAnalyticsService) GetRetention(query models.RetentionQuery) (*models.RetentionReport, error) {
+	args := m.Called(query)
+	return args.Get(0).(*models.RetentionReport), args.Error(1)
+}
+
+func (m *MockAnalyticsService) GetNetworkCohorts(query models.CohortQuery) (*models.CohortReport, error) {
+	args := m.Called(query)
+	return args.Get(0).(*models.CohortReport), args.Error(1)
+}
+
+func (m *MockAnalyticsService) GetCountyBoundary(county string) (*models.CountyBoundary, error) {
+	args := m.Called(county)
+	return args.Get(0).(*models.CountyBoundary), args.Error(1)
+}
+
 func TestGetAllCountyData(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	
@@ -66,7 +121,7 @@ func TestGetAllCountyData(t *testing.T) {
 		{County: "Sedgwick", ProviderCount: 100, ClaimsCount: 5000, AvgClaimAmount: 250.50, Density: "high"},
 	}
 	
-	mockService.On("GetAllCountyData").Return(expectedData, nil)
+	mockService.On("GetAllCountyData", config.Today()).Return(expectedData, nil)
 	
 	router := gin.New()
 	router.GET("/county-data", controller.GetAllCountyData)
@@ -99,7 +154,7 @@ func TestGetCountyData(t *testing.T) {
 		Density: "high",
 	}
 	
-	mockService.On("GetCountyData", "Sedgwick").Return(expectedData, nil)
+	mockService.On("GetCountyData", "Sedgwick", config.Today()).Return(expectedData, nil)
 	
 	router := gin.New()
 	router.GET("/county-data/:county", controller.GetCountyData)
@@ -131,12 +186,13 @@ func TestGetSpecialtyDensityAnalysis(t *testing.T) {
 		},
 	}
 	
-	mockService.On("GetSpecialtyDensityAnalysis", "Sedgwick").Return(expectedData, nil)
+	asOfDate := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	mockService.On("GetSpecialtyDensityAnalysis", "Sedgwick", asOfDate).Return(expectedData, nil)
 	
 	router := gin.New()
 	router.GET("/specialty-density/:county", controller.GetSpecialtyDensityAnalysis)
 	
-	req, _ := http.NewRequest("GET", "/specialty-density/Sedgwick", nil)
+	req, _ := http.NewRequest("GET", "/specialty-density/Sedgwick?as_of=2024-06-30", nil)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 	
@@ -148,4 +204,626 @@ func TestGetSpecialtyDensityAnalysis(t *testing.T) {
 	assert.Equal(t, expectedData, response)
 	
 	mockService.AssertExpectations(t)
-}
\ No newline at end of file
+}
+func TestGetActiveProviderCountAsOf(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockAnalyticsService)
+	controller := NewAnalyticsController(mockService)
+	mockService.On("GetActiveProviderCount", time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)).Return(42, nil)
+
+	router := gin.New()
+	router.GET("/active-providers", controller.GetActiveProviderCount)
+
+	req, _ := http.NewRequest("GET", "/active-providers?as_of=2024-06-30", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"total_active_providers": 42}`, w.Body.String())
+
+	req, _ = http.NewRequest("GET", "/active-providers?as_of=June", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestGetTerminatedNetworkAnalysisAsOf(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	
+	mockService := new(MockAnalyticsService)
+	controller := NewAnalyticsController(mockService)
+	
+	asOf := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	criteria := models.TerminationCriteria{
+		AsOf:    asOf,
+		From:    time.Date(2019, 6, 30, 0, 0, 0, 0, time.UTC),
+		To:      time.Date(2022, 6, 30, 0, 0, 0, 0, time.UTC),
+		Reasons: []string{"Left Network"},
+
+		Denominator: "in_window",
+	}
+	expected := &models.TerminatedAnalysisResult{TermNetworkCount: 50, TotalActiveProviders: 1000}
+	mockService.On("GetTerminatedNetworkAnalysis", "Commercial", criteria).Return(expected, nil)
+	
+	router := gin.New()
+	router.GET("/terminated-analysis", controller.GetTerminatedNetworkAnalysis)
+	
+	req, _ := http.NewRequest("GET", "/terminated-analysis?network_id=Commercial&as_of=2024-06-30", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestInvalidAsOfDate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	
+	mockService := new(MockAnalyticsService)
+	controller := NewAnalyticsController(mockService)
+	
+	router := gin.New()
+	router.GET("/county-data", controller.GetAllCountyData)
+	
+	for _, value := range []string{"06/30/2024", "2024-02-30", "yesterday"} {
+		req, _ := http.NewRequest("GET", "/county-data?as_of="+value, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		
+		assert.Equal(t, http.StatusBadRequest, w.Code, value)
+		assert.Contains(t, w.Body.String(), "as_of")
+	}
+	
+	mockService.AssertNotCalled(t, "GetAllCountyData", mock.Anything)
+}
+
+func TestTerminationCriteriaParameters(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	
+	asOf := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		query    string
+		expected models.TerminationCriteria
+	}{
+		{
+			"min_years=1&max_years=3&reasons=Retired,Relocated",
+			models.TerminationCriteria{AsOf: asOf, From: asOf.AddDate(-3, 0, 0), To: asOf.AddDate(-1, 0, 0), Reasons: []string{"Retired", "Relocated"}, Denominator: "in_window"},
+		},
+		{
+			"from=2021-01-01&to=2022-01-01&reasons=all",
+			models.TerminationCriteria{AsOf: asOf, From: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), To: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), Denominator: "in_window"},
+		},
+		{
+			"denominator=at_window_start&legacy_percentage=true",
+			models.TerminationCriteria{AsOf: asOf, From: asOf.AddDate(-5, 0, 0), To: asOf.AddDate(-2, 0, 0), Reasons: []string{"Left Network"}, Denominator: "at_window_start", LegacyPercentage: true},
+		},
+	}
+	
+	for _, test := range tests {
+		mockService := new(MockAnalyticsService)
+		controller := NewAnalyticsController(mockService)
+		mockService.On("GetCountyTerminatedNetworkAnalysis", "Sedgwick", "Commercial", test.expected).Return(&models.TerminatedAnalysisResult{}, nil)
+		
+		router := gin.New()
+		router.GET("/terminated-analysis/:county", controller.GetCountyTerminatedNetworkAnalysis)
+		
+		req, _ := http.NewRequest("GET", "/terminated-analysis/Sedgwick?network_id=Commercial&as_of=2024-06-30&"+test.query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		
+		assert.Equal(t, http.StatusOK, w.Code, test.query)
+		mockService.AssertExpectations(t)
+	}
+	
+	for _, query := range []string{"from=2022-01-01&to=2021-01-01", "from=2021-01-01&max_years=4", "min_years=-1", "to=soon", "denominator=everyone", "legacy_percentage=maybe"} {
+		mockService := new(MockAnalyticsService)
+		controller := NewAnalyticsController(mockService)
+		
+		router := gin.New()
+		router.GET("/terminated-analysis", controller.GetTerminatedNetworkAnalysis)
+		
+		req, _ := http.NewRequest("GET", "/terminated-analysis?network_id=Commercial&"+query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
+
+func TestGetRadiusAnalysisParameters(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	
+	mockService := new(MockAnalyticsService)
+	controller := NewAnalyticsController(mockService)
+	
+	router := gin.New()
+	router.GET("/radius-analysis/:county", controller.GetRadiusAnalysis)
+	
+	center := &models.Coordinate{Latitude: 37.69, Longitude: -97.33}
+	mockService.On("GetRadiusAnalysis", "Sedgwick", center, 30, "Commercial", mock.Anything, false).Return(map[string]interface{}{"provider_count": 3}, nil)
+	mockService.On("GetRadiusAnalysis", "Atlantis", (*models.Coordinate)(nil), 25, "Commercial", mock.Anything, false).Return(map[string]interface{}(nil), fmt.Errorf("centroid for county Atlantis %w", services.ErrNotFound))
+	mockService.On("GetRadiusAnalysis", "Cherokee", (*models.Coordinate)(nil), 25, "Commercial", mock.Anything, true).Return(map[string]interface{}{"out_of_state_location_count": 2}, nil)
+	
+	for _, test := range []struct {
+		query    string
+		expected int
+	}{
+		{"/radius-analysis/Sedgwick?network=Commercial&radius=30&lat=37.69&lng=-97.33", http.StatusOK},
+		{"/radius-analysis/Atlantis?network=Commercial", http.StatusNotFound},
+		{"/radius-analysis/Sedgwick?network=Commercial&radius=0", http.StatusBadRequest},
+		{"/radius-analysis/Sedgwick?network=Commercial&radius=ten", http.StatusBadRequest},
+		{"/radius-analysis/Sedgwick?network=Commercial&lat=37.69", http.StatusBadRequest},
+		{"/radius-analysis/Sedgwick?network=Commercial&lat=97.33&lng=-97.33", http.StatusBadRequest},
+		{"/radius-analysis/Cherokee?network=Commercial&include_out_of_state=true", http.StatusOK},
+		{"/radius-analysis/Cherokee?network=Commercial&include_out_of_state=maybe", http.StatusBadRequest},
+	} {
+		req, _ := http.NewRequest("GET", test.query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		
+		assert.Equal(t, test.expected, w.Code, test.query)
+	}
+	
+	mockService.AssertExpectations(t)
+}
+
+func TestGetNetworkAdequacy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	
+	mockService := new(MockAnalyticsService)
+	controller := NewAnalyticsController(mockService)
+	
+	router := gin.New()
+	router.GET("/network-adequacy", controller.GetNetworkAdequacy)
+	
+	asOfDate := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	report := &models.AdequacyReport{AsOf: "2024-06-30", MinPercentage: 90, Evaluated: 1, Passed: 1, Results: []models.AdequacyResult{
+		{County: "Sedgwick", Designation: "Metro", Specialty: "Cardiology", Network: "Commercial", MaxMinutes: 30, MaxMiles: 20, SampledPoints: 25, PointsMeetingStandard: 25, PercentMeetingStandard: 100, Pass: true},
+	}}
+	mockService.On("GetNetworkAdequacy", models.AdequacyFilter{County: "Sedgwick", Specialty: "Cardiology", Network: "Commercial", AsOf: asOfDate}).Return(report, nil)
+	mockService.On("GetNetworkAdequacy", models.AdequacyFilter{County: "Atlantis", AsOf: asOfDate}).Return((*models.AdequacyReport)(nil), fmt.Errorf("designation for county Atlantis %w", services.ErrNotFound))
+	mockService.On("GetNetworkAdequacy", models.AdequacyFilter{County: "Cherokee", AsOf: asOfDate, IncludeOutOfState: true}).Return(&models.AdequacyReport{AsOf: "2024-06-30", IncludeOutOfState: true}, nil)
+	
+	req, _ := http.NewRequest("GET", "/network-adequacy?county=Sedgwick&specialty=Cardiology&network=Commercial&as_of=2024-06-30", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response models.AdequacyReport
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, *report, response)
+	
+	req, _ = http.NewRequest("GET", "/network-adequacy?county=Atlantis&as_of=2024-06-30", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	
+	req, _ = http.NewRequest("GET", "/network-adequacy?as_of=yesterday", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	
+	req, _ = http.NewRequest("GET", "/network-adequacy?county=Cherokee&as_of=2024-06-30&include_out_of_state=true", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	
+	req, _ = http.NewRequest("GET", "/network-adequacy?include_out_of_state=yes", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	
+	mockService.AssertExpectations(t)
+}
+
+func TestGetCountyBoundary(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	
+	mockService := new(MockAnalyticsService)
+	controller := NewAnalyticsController(mockService)
+	
+	router := gin.New()
+	router.GET("/county-boundaries/:county", controller.GetCountyBoundary)
+	
+	boundary := &models.CountyBoundary{
+		County:   "Sedgwick",
+		Centroid: models.Coordinate{Latitude: 37.684, Longitude: -97.461},
+		Polygons: []models.Polygon{{{{-97.8, 37.47}, {-97.15, 37.47}, {-97.15, 37.91}, {-97.8, 37.91}, {-97.8, 37.47}}}},
+	}
+	mockService.On("GetCountyBoundary", "Sedgwick").Return(boundary, nil)
+	mockService.On("GetCountyBoundary", "Atlantis").Return((*models.CountyBoundary)(nil), fmt.Errorf("boundary for county Atlantis %w", services.ErrNotFound))
+	
+	req, _ := http.NewRequest("GET", "/county-boundaries/Sedgwick", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response models.CountyBoundary
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, *boundary, response)
+	
+	req, _ = http.NewRequest("GET", "/county-boundaries/Atlantis", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	
+	mockService.AssertExpectations(t)
+}
+
+func TestGetCountyDataGeoJSON(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	
+	mockService := new(MockAnalyticsService)
+	controller := NewAnalyticsController(mockService)
+	
+	router := gin.New()
+	router.GET("/geojson/county-data", controller.GetCountyDataGeoJSON)
+	
+	collection := &models.FeatureCollection{Type: "FeatureCollection", Features: []models.Feature{{
+		Type:       "Feature",
+		Geometry:   &models.Geometry{Type: "Polygon", Coordinates: models.Polygon{{{-97.81, 37.47}, {-97.15, 37.47}, {-97.15, 37.91}, {-97.81, 37.47}}}},
+		Properties: models.CountyStats{County: "Sedgwick", ProviderCount: 120},
+	}}}
+	mockService.On("GetCountyFeatures", time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)).Return(collection, nil)
+	
+	req, _ := http.NewRequest("GET", "/geojson/county-data?as_of=2024-06-30", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, services.GeoJSONContentType, w.Header().Get("Content-Type"))
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	feature := response["features"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "Polygon", feature["geometry"].(map[string]interface{})["type"])
+	assert.Equal(t, "Sedgwick", feature["properties"].(map[string]interface{})["county"])
+	
+	mockService.AssertExpectations(t)
+}
+
+func TestGetCoverageGaps(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	
+	mockService := new(MockAnalyticsService)
+	controller := NewAnalyticsController(mockService)
+	
+	router := gin.New()
+	router.GET("/coverage-gaps", controller.GetCoverageGaps)
+	
+	asOfDate := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	distance := 41.5
+	report := &models.CoverageGapReport{
+		FeatureCollection: models.FeatureCollection{Type: "FeatureCollection", Features: []models.Feature{{
+			Type:       "Feature",
+			Geometry:   &models.Geometry{Type: "Polygon", Coordinates: models.Polygon{{{-101.5, 37.0}, {-101.3, 37.0}, {-101.3, 37.1}, {-101.5, 37.0}}}},
+			Properties: map[string]interface{}{"county": "Stevens", "distance_miles": distance},
+		}}},
+		Network: "Commercial", Specialty: "Cardiology", AsOf: "2024-06-30", CellMiles: 5, ThresholdMiles: 30, TotalCells: 3300, GapCells: 1,
+		Counties: []models.CountyCoverage{{County: "Stevens", Cells: 30, GapCells: 1, GapPercent: 3.33, MaxDistanceMiles: &distance}},
+	}
+	mockService.On("GetCoverageGaps", models.CoverageGapQuery{Network: "Commercial", Specialty: "Cardiology", CellMiles: 5, ThresholdMiles: 30, AsOf: asOfDate}).Return(report, nil)
+	mockService.On("GetCoverageGaps", models.CoverageGapQuery{Network: "Medicaid", Specialty: "All", CellMiles: 10, ThresholdMiles: 45.5, AsOf: asOfDate}).Return(report, nil)
+	mockService.On("GetCoverageGaps", models.CoverageGapQuery{Network: "Medicare", Specialty: "All", CellMiles: 10, ThresholdMiles: 30, AsOf: asOfDate}).Return(nil, fmt.Errorf("network Medicare %w", services.ErrNotFound))
+	mockService.On("GetCoverageGaps", models.CoverageGapQuery{Network: "Commercial", Specialty: "Dentistry", CellMiles: 10, ThresholdMiles: 30, AsOf: asOfDate}).Return(nil, &services.ValidationError{Field: "specialty", Message: "is not known"})
+	
+	req, _ := http.NewRequest("GET", "/coverage-gaps?network=Commercial&specialty=Cardiology&cell_miles=5&as_of=2024-06-30", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, services.GeoJSONContentType, w.Header().Get("Content-Type"))
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "FeatureCollection", response["type"])
+	assert.Equal(t, 41.5, response["features"].([]interface{})[0].(map[string]interface{})["properties"].(map[string]interface{})["distance_miles"])
+	assert.Equal(t, "Stevens", response["counties"].([]interface{})[0].(map[string]interface{})["county"])
+	
+	req, _ = http.NewRequest("GET", "/coverage-gaps?network=Medicaid&threshold_miles=45.5&as_of=2024-06-30", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	
+	// Service errors map to the status they stand for
+	req, _ = http.NewRequest("GET", "/coverage-gaps?network=Medicare&as_of=2024-06-30", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	req, _ = http.NewRequest("GET", "/coverage-gaps?network=Commercial&specialty=Dentistry&as_of=2024-06-30", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	
+	for _, query := range []string{"", "?network=Commercial&cell_miles=0.5", "?network=Commercial&cell_miles=wide", "?network=Commercial&threshold_miles=-1", "?network=Commercial&as_of=yesterday"} {
+		req, _ = http.NewRequest("GET", "/coverage-gaps"+query, nil)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+	
+	mockService.AssertExpectations(t)
+}
+
+func TestGetAccessibility(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	
+	mockService := new(MockAnalyticsService)
+	controller := NewAnalyticsController(mockService)
+	
+	router := gin.New()
+	router.GET("/accessibility", controller.GetAccessibility)
+	
+	asOfDate := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	report := &models.AccessibilityReport{AsOf: "2024-06-30", Specialty: "Cardiology", CatchmentMiles: 45, Decay: "linear", Results: []models.AccessibilityResult{
+		{County: "Sedgwick", Network: "Commercial", Population: 523824, ProvidersPer100k: 12.5, RelativeToState: 1.2},
+	}}
+	mockService.On("GetAccessibility", models.AccessibilityQuery{County: "Sedgwick", Specialty: "Cardiology", Network: "Commercial", CatchmentMiles: 45, Decay: "linear", AsOf: asOfDate}).Return(report, nil)
+	mockService.On("GetAccessibility", models.AccessibilityQuery{Specialty: "All", CatchmentMiles: 30, Decay: "gaussian", AsOf: asOfDate}).Return(report, nil)
+	mockService.On("GetAccessibility", models.AccessibilityQuery{Specialty: "All", CatchmentMiles: 30, Decay: "cubic", AsOf: asOfDate}).Return((*models.AccessibilityReport)(nil), &services.ValidationError{Field: "decay", Message: "must be step, linear or gaussian"})
+	mockService.On("GetAccessibility", models.AccessibilityQuery{County: "Atlantis", Specialty: "All", CatchmentMiles: 30, Decay: "gaussian", AsOf: asOfDate}).Return((*models.AccessibilityReport)(nil), fmt.Errorf("population for county Atlantis %w", services.ErrNotFound))
+	
+	req, _ := http.NewRequest("GET", "/accessibility?county=Sedgwick&specialty=Cardiology&network=Commercial&catchment_miles=45&decay=linear&as_of=2024-06-30", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response models.AccessibilityReport
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, *report, response)
+	
+	for query, status := range map[string]int{
+		"?as_of=2024-06-30":                     http.StatusOK,
+		"?decay=cubic&as_of=2024-06-30":         http.StatusBadRequest,
+		"?county=Atlantis&as_of=2024-06-30":     http.StatusNotFound,
+		"?catchment_miles=500&as_of=2024-06-30": http.StatusBadRequest,
+	} {
+		req, _ = http.NewRequest("GET", "/accessibility"+query, nil)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, status, w.Code, query)
+	}
+	
+	mockService.AssertExpectations(t)
+}
+
+func TestGetRecruitmentSites(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	
+	mockService := new(MockAnalyticsService)
+	controller := NewAnalyticsController(mockService)
+	
+	router := gin.New()
+	router.GET("/recruitment-sites", controller.GetRecruitmentSites)
+	
+	asOfDate := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	plan := &models.RecruitmentPlan{AsOf: "2024-06-30", Specialty: "Cardiology", Network: "Commercial", MaxMiles: 45, Candidates: "zips", CandidatesEvaluated: 12,
+		TotalPopulation: 2937880, CoveredPopulation: 2500000, CoveredPercent: 85.1, Sites: []models.RecruitmentSite{
+			{Rank: 1, Kind: "zip", ID: "67846", City: "Garden City", County: "Finney", Latitude: 37.97, Longitude: -100.87, MarginalPopulation: 45000, CoveredPopulation: 2545000, CoveredPercent: 86.63},
+		}}
+	mockService.On("GetRecruitmentSites", models.RecruitmentQuery{Specialty: "Cardiology", Network: "Commercial", MaxMiles: 45, Sites: 3, Candidates: "zips", AsOf: asOfDate}).Return(plan, nil)
+	mockService.On("GetRecruitmentSites", models.RecruitmentQuery{Specialty: "All", Network: "Commercial", MaxMiles: 30, Sites: 5, Candidates: "providers", AsOf: asOfDate}).Return(plan, nil)
+	mockService.On("GetRecruitmentSites", models.RecruitmentQuery{Specialty: "All", Network: "Commercial", MaxMiles: 30, Sites: 5, Candidates: "hospitals", AsOf: asOfDate}).Return((*models.RecruitmentPlan)(nil), &services.ValidationError{Field: "candidates", Message: "must be providers or zips"})
+	
+	req, _ := http.NewRequest("GET", "/recruitment-sites?network=Commercial&specialty=Cardiology&max_miles=45&sites=3&candidates=zips&as_of=2024-06-30", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response models.RecruitmentPlan
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, *plan, response)
+	
+	for query, status := range map[string]int{
+		"?network=Commercial&as_of=2024-06-30":                      http.StatusOK,
+		"?network=Commercial&candidates=hospitals&as_of=2024-06-30": http.StatusBadRequest,
+		"?as_of=2024-06-30":                                         http.StatusBadRequest,
+		"?network=Commercial&sites=0":                               http.StatusBadRequest,
+		"?network=Commercial&sites=26":                              http.StatusBadRequest,
+		"?network=Commercial&max_miles=far":                         http.StatusBadRequest,
+	} {
+		req, _ = http.NewRequest("GET", "/recruitment-sites"+query, nil)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, status, w.Code, query)
+	}
+	
+	mockService.AssertExpectations(t)
+}
+
+func TestGetCatchments(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	
+	mockService := new(MockAnalyticsService)
+	controller := NewAnalyticsController(mockService)
+	
+	router := gin.New()
+	router.GET("/catchments", controller.GetCatchments)
+	
+	asOfDate := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	report := &models.CatchmentReport{
+		FeatureCollection: models.FeatureCollection{Type: "FeatureCollection", Features: []models.Feature{{
+			Type:       "Feature",
+			Geometry:   &models.Geometry{Type: "MultiPolygon", Coordinates: []models.Polygon{{{{-97.81, 37.47}, {-97.15, 37.47}, {-97.15, 37.91}, {-97.81, 37.47}}}}},
+			Properties: map[string]interface{}{"city": "Wichita", "population_served": 523824},
+		}}},
+		Network: "Commercial", Specialty: "Cardiology", AsOf: "2024-06-30", Sites: 1,
+	}
+	mockService.On("GetCatchments", models.CatchmentQuery{Network: "Commercial", Specialty: "Cardiology", AsOf: asOfDate}).Return(report, nil)
+	mockService.On("GetCatchments", models.CatchmentQuery{Network: "Medicaid", Specialty: "All", AsOf: asOfDate}).Return(report, nil)
+	mockService.On("GetCatchments", models.CatchmentQuery{Network: "Broken", Specialty: "All", AsOf: asOfDate}).Return((*models.CatchmentReport)(nil), fmt.Errorf("database is locked"))
+	
+	req, _ := http.NewRequest("GET", "/catchments?network=Commercial&specialty=Cardiology&as_of=2024-06-30", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, services.GeoJSONContentType, w.Header().Get("Content-Type"))
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	feature := response["features"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "MultiPolygon", feature["geometry"].(map[string]interface{})["type"])
+	assert.Equal(t, 523824.0, feature["properties"].(map[string]interface{})["population_served"])
+	assert.Equal(t, 1.0, response["sites"])
+	
+	for query, status := range map[string]int{
+		"?network=Medicaid&as_of=2024-06-30": http.StatusOK,
+		"?network=Broken&as_of=2024-06-30":   http.StatusInternalServerError,
+		"?as_of=2024-06-30":                  http.StatusBadRequest,
+		"?network=Commercial&as_of=someday":  http.StatusBadRequest,
+	} {
+		req, _ = http.NewRequest("GET", "/catchments"+query, nil)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, status, w.Code, query)
+	}
+	
+	mockService.AssertExpectations(t)
+}
+
+func TestGetNetworkTimeSeries(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	
+	mockService := new(MockAnalyticsService)
+	controller := NewAnalyticsController(mockService)
+	
+	router := gin.New()
+	router.GET("/networks/:network/timeseries", controller.GetNetworkTimeSeries)
+	
+	report := &models.TimeSeriesReport{Network: "Commercial", From: "2024-01-01", To: "2024-03-31", Interval: "month", GroupBy: "county", Series: []models.TimeSeries{{
+		Group: "Sedgwick",
+		Points: []models.TimeSeriesPoint{
+			{PeriodStart: "2024-01-01", PeriodEnd: "2024-01-31", Active: 12, Joins: 1},
+			{PeriodStart: "2024-02-01", PeriodEnd: "2024-02-29", Active: 10, Terminations: 2},
+			{PeriodStart: "2024-03-01", PeriodEnd: "2024-03-31", Active: 10},
+		},
+	}}}
+	mockService.On("GetNetworkTimeSeries", models.TimeSeriesQuery{
+		Network: "Commercial", From: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), To: time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC), Interval: "month", GroupBy: "county",
+	}).Return(report, nil)
+	// Without from, the two years up to to are charted
+	mockService.On("GetNetworkTimeSeries", models.TimeSeriesQuery{
+		Network: "Medicare", From: time.Date(2022, 7, 30, 0, 0, 0, 0, time.UTC), To: time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC), Interval: "quarter",
+	}).Return(report, nil)
+	mockService.On("GetNetworkTimeSeries", mock.MatchedBy(func(query models.TimeSeriesQuery) bool { return query.Network == "Medicaid" })).
+		Return((*models.TimeSeriesReport)(nil), fmt.Errorf("network Medicaid %w", services.ErrNotFound))
+	mockService.On("GetNetworkTimeSeries", mock.MatchedBy(func(query models.TimeSeriesQuery) bool { return query.Interval == "week" })).
+		Return((*models.TimeSeriesReport)(nil), &services.ValidationError{Field: "interval", Message: "must be month, quarter or year"})
+	
+	req, _ := http.NewRequest("GET", "/networks/Commercial/timeseries?from=2024-01-01&to=2024-03-31&group_by=county", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response models.TimeSeriesReport
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, *report, response)
+	
+	for query, status := range map[string]int{
+		"/networks/Medicare/timeseries?to=2024-06-30&interval=quarter": http.StatusOK,
+		"/networks/Medicaid/timeseries":                                http.StatusNotFound,
+		"/networks/Commercial/timeseries?interval=week":                http.StatusBadRequest,
+		"/networks/Commercial/timeseries?from=January":                 http.StatusBadRequest,
+		"/networks/Commercial/timeseries?to=2024-13-01":                http.StatusBadRequest,
+	} {
+		req, _ = http.NewRequest("GET", query, nil)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, status, w.Code, query)
+	}
+	
+	mockService.AssertExpectations(t)
+}
+
+func TestGetRetention(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	
+	mockService := new(MockAnalyticsService)
+	controller := NewAnalyticsController(mockService)
+	
+	router := gin.New()
+	router.GET("/retention", controller.GetRetention)
+	
+	asOfDate := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	median := 4.08
+	report := &models.RetentionReport{AsOf: "2024-06-30", Network: "Commercial", StratifyBy: "county", ConfidenceLevel: 0.95, Strata: []models.RetentionStratum{{
+		Group: "Sedgwick", Memberships: 2, Terminations: 1, Censored: 1, MedianTenureYears: &median,
+		Milestones: []models.RetentionMilestone{{Years: 1, Retention: 1, Lower: 1, Upper: 1}},
+		Curve:      []models.RetentionPoint{{TenureYears: 1.04, AtRisk: 2, Censored: 1, Retention: 1, Lower: 1, Upper: 1}, {TenureYears: 4.08, AtRisk: 1, Terminations: 1}},
+	}}}
+	mockService.On("GetRetention", models.RetentionQuery{Network: "Commercial", StratifyBy: "county", AsOf: asOfDate}).Return(report, nil)
+	mockService.On("GetRetention", models.RetentionQuery{StratifyBy: "network", AsOf: asOfDate}).Return(&models.RetentionReport{Strata: []models.RetentionStratum{}}, nil)
+	mockService.On("GetRetention", models.RetentionQuery{StratifyBy: "zip", AsOf: asOfDate}).
+		Return((*models.RetentionReport)(nil), &services.ValidationError{Field: "stratify_by", Message: "must be network, county or specialty"})
+	
+	req, _ := http.NewRequest("GET", "/retention?network=Commercial&stratify_by=county&as_of=2024-06-30", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response models.RetentionReport
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, *report, response)
+	
+	for query, status := range map[string]int{
+		"?as_of=2024-06-30":                 http.StatusOK,
+		"?stratify_by=zip&as_of=2024-06-30": http.StatusBadRequest,
+		"?as_of=June":                       http.StatusBadRequest,
+	} {
+		req, _ = http.NewRequest("GET", "/retention"+query, nil)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, status, w.Code, query)
+	}
+	
+	mockService.AssertExpectations(t)
+}
+
+func TestGetNetworkCohorts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	
+	mockService := new(MockAnalyticsService)
+	controller := NewAnalyticsController(mockService)
+	
+	router := gin.New()
+	router.GET("/networks/:network/cohorts", controller.GetNetworkCohorts)
+	
+	asOfDate := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	report := &models.CohortReport{Network: "Commercial", AsOf: "2024-06-30", Years: 2, Cohorts: []models.Cohort{
+		{JoinYear: 2022, Providers: 3, Active: []int{3, 2}, Percent: []float64{100, 66.7}},
+		{JoinYear: 2023, Providers: 2, Active: []int{1}, Percent: []float64{50}},
+	}}
+	mockService.On("GetNetworkCohorts", models.CohortQuery{Network: "Commercial", AsOf: asOfDate}).Return(report, nil)
+	mockService.On("GetNetworkCohorts", models.CohortQuery{Network: "Medicaid", AsOf: asOfDate}).
+		Return((*models.CohortReport)(nil), fmt.Errorf("network Medicaid %w", services.ErrNotFound))
+	
+	req, _ := http.NewRequest("GET", "/networks/Commercial/cohorts?as_of=2024-06-30", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response models.CohortReport
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, *report, response)
+	
+	req, _ = http.NewRequest("GET", "/networks/Commercial/cohorts?as_of=2024-06-30&format=csv", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, services.CSVContentType, w.Header().Get("Content-Type"))
+	assert.Equal(t, "join_year,providers,year_1,year_2\n2022,3,100.0,66.7\n2023,2,50.0,\n", w.Body.String())
+	
+	for query, status := range map[string]int{
+		"/networks/Medicaid/cohorts?as_of=2024-06-30":  http.StatusNotFound,
+		"/networks/Commercial/cohorts?format=xlsx":     http.StatusBadRequest,
+		"/networks/Commercial/cohorts?as_of=last-year": http.StatusBadRequest,
+	} {
+		req, _ = http.NewRequest("GET", query, nil)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, status, w.Code, query)
+	}
+	
+	mockService.AssertExpectations(t)
+}
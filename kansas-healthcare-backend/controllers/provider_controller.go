@@ -1,10 +1,13 @@
 package controllers
 
 import (
+	"fmt"
+	"kansas-healthcare-api/config"
 	"kansas-healthcare-api/models"
 	"kansas-healthcare-api/services"
 	"log"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -42,9 +45,13 @@ func (c *ProviderController) GetFilteredData(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	date, ok := asOf(ctx)
+	if !ok {
+		return
+	}
 
 	log.Printf("[INFO] Filtering providers: specialty=%s, network=%s", filter.Specialty, filter.Network)
-	providers, err := c.service.GetFilteredProviders(filter)
+	providers, err := c.service.GetFilteredProviders(filter, date)
 	if err != nil {
 		log.Printf("[ERROR] Failed to filter providers: %v", err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -53,3 +60,82 @@ func (c *ProviderController) GetFilteredData(ctx *gin.Context) {
 	log.Printf("[INFO] Successfully filtered %d providers", len(providers))
 	ctx.JSON(http.StatusOK, providers)
 }
+
+// GetNearestProviders returns the service locations closest to lat/lng of active providers in
+// the network, optionally of one specialty, nearest first
+func (c *ProviderController) GetNearestProviders(ctx *gin.Context) {
+	center, ok := pointQuery(ctx)
+	if !ok {
+		return
+	}
+	if center == nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "lat and lng query parameters are required"})
+		return
+	}
+	network := ctx.Query("network")
+	if network == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "network query parameter is required"})
+		return
+	}
+	limit := config.DefaultNearestProviderLimit
+	if value := ctx.Query("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > config.MaxNearestProviderLimit {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("limit must be a whole number from 1 to %d", config.MaxNearestProviderLimit)})
+			return
+		}
+		limit = n
+	}
+	date, ok := asOf(ctx)
+	if !ok {
+		return
+	}
+	includeOutOfState, ok := outOfStateQuery(ctx)
+	if !ok {
+		return
+	}
+
+	query := models.NearestProviderQuery{
+		Center:            *center,
+		Specialty:         ctx.DefaultQuery("specialty", "All"),
+		Network:           network,
+		Limit:             limit,
+		AsOf:              date,
+		IncludeOutOfState: includeOutOfState,
+	}
+	providers, err := c.service.GetNearestProviders(query)
+	if err != nil {
+		log.Printf("[ERROR] Failed to find nearest providers: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, providers)
+}
+
+// GetServiceLocationGeoJSON returns in-force service locations of active providers as a GeoJSON
+// FeatureCollection, optionally narrowed to one network and specialty
+func (c *ProviderController) GetServiceLocationGeoJSON(ctx *gin.Context) {
+	date, ok := asOf(ctx)
+	if !ok {
+		return
+	}
+	collection, err := c.service.GetServiceLocationFeatures(ctx.Query("network"), ctx.DefaultQuery("specialty", "All"), date)
+	if err != nil {
+		log.Printf("[ERROR] Failed to build service location GeoJSON: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.Header("Content-Type", services.GeoJSONContentType)
+	ctx.JSON(http.StatusOK, collection)
+}
+
+// GetDataQuality reports referential integrity and plausibility problems found in the loaded data
+func (c *ProviderController) GetDataQuality(ctx *gin.Context) {
+	report, err := c.service.GetDataQualityReport()
+	if err != nil {
+		log.Printf("[ERROR] Failed to build data quality report: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, report)
+}
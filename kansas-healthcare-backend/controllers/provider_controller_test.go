@@ -3,10 +3,13 @@ package controllers
 import (
 	"bytes"
 	"encoding/json"
+	"kansas-healthcare-api/config"
 	"kansas-healthcare-api/models"
+	"kansas-healthcare-api/services"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -27,11 +30,92 @@ func (m *MockProviderService) GetProviderNetworks() ([]models.ProviderNetwork, e
 	return args.Get(0).([]models.ProviderNetwork), args.Error(1)
 }
 
-func (m *MockProviderService) GetFilteredProviders(filter models.FilterRequest) ([]models.Provider, error) {
-	args := m.Called(filter)
+func (m *MockProviderService) GetFilteredProviders(filter models.FilterRequest, asOf time.Time) ([]models.Provider, error) {
+	args := m.Called(filter, asOf)
 	return args.Get(0).([]models.Provider), args.Error(1)
 }
 
+func (m *MockProviderService) GetNearestProviders(query models.NearestProviderQuery) ([]models.NearestProvider, error) {
+	args := m.Called(query)
+	return args.Get(0).([]models.NearestProvider), args.Error(1)
+}
+
+func (m *MockProviderService) GetServiceLocationFeatures(networkId, specialty string, asOf time.Time) (*models.FeatureCollection, error) {
+	args := m.Called(networkId, specialty, asOf)
+	return args.Get(0).(*models.FeatureCollection), args.Error(1)
+}
+
+func (m *MockProviderService) GetDataQualityReport() (*models.DataQualityReport, error) {
+	args := m.Called()
+	return args.Get(0).(*models.DataQualityReport), args.Error(1)
+}
+
+func (m *MockProviderService) GetProvider(providerId string) (*models.Provider, string, error) {
+	args := m.Called(providerId)
+	record, _ := args.Get(0).(*models.Provider)
+	return record, args.String(1), args.Error(2)
+}
+
+func (m *MockProviderService) CreateProvider(provider models.Provider) (*models.Provider, string, error) {
+	args := m.Called(provider)
+	record, _ := args.Get(0).(*models.Provider)
+	return record, args.String(1), args.Error(2)
+}
+
+func (m *MockProviderService) UpdateProvider(providerId string, provider models.Provider, version string) (*models.Provider, string, error) {
+	args := m.Called(providerId, provider, version)
+	record, _ := args.Get(0).(*models.Provider)
+	return record, args.String(1), args.Error(2)
+}
+
+func (m *MockProviderService) DeleteProvider(providerId, version string) error {
+	return m.Called(providerId, version).Error(0)
+}
+
+func (m *MockProviderService) GetProviderNetwork(providerId, networkId string, effectiveDate time.Time) (*models.ProviderNetwork, string, error) {
+	args := m.Called(providerId, networkId, effectiveDate)
+	record, _ := args.Get(0).(*models.ProviderNetwork)
+	return record, args.String(1), args.Error(2)
+}
+
+func (m *MockProviderService) CreateProviderNetwork(providerId string, network models.ProviderNetwork) (*models.ProviderNetwork, string, error) {
+	args := m.Called(providerId, network)
+	record, _ := args.Get(0).(*models.ProviderNetwork)
+	return record, args.String(1), args.Error(2)
+}
+
+func (m *MockProviderService) UpdateProviderNetwork(providerId, networkId string, effectiveDate time.Time, network models.ProviderNetwork, version string) (*models.ProviderNetwork, string, error) {
+	args := m.Called(providerId, networkId, effectiveDate, network, version)
+	record, _ := args.Get(0).(*models.ProviderNetwork)
+	return record, args.String(1), args.Error(2)
+}
+
+func (m *MockProviderService) DeleteProviderNetwork(providerId, networkId string, effectiveDate time.Time, version string) error {
+	return m.Called(providerId, networkId, effectiveDate, version).Error(0)
+}
+
+func (m *MockProviderService) GetServiceLocation(locationId string) (*models.ProviderServiceLocation, string, error) {
+	args := m.Called(locationId)
+	record, _ := args.Get(0).(*models.ProviderServiceLocation)
+	return record, args.String(1), args.Error(2)
+}
+
+func (m *MockProviderService) CreateServiceLocation(providerId string, location models.ProviderServiceLocation) (*models.ProviderServiceLocation, string, error) {
+	args := m.Called(providerId, location)
+	record, _ := args.Get(0).(*models.ProviderServiceLocation)
+	return record, args.String(1), args.Error(2)
+}
+
+func (m *MockProviderService) UpdateServiceLocation(locationId string, location models.ProviderServiceLocation, version string) (*models.ProviderServiceLocation, string, error) {
+	args := m.Called(locationId, location, version)
+	record, _ := args.Get(0).(*models.ProviderServiceLocation)
+	return record, args.String(1), args.Error(2)
+}
+
+func (m *MockProviderService) DeleteServiceLocation(locationId, version string) error {
+	return m.Called(locationId, version).Error(0)
+}
+
 func TestGetProviders(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	
@@ -78,7 +162,7 @@ func TestGetFilteredData(t *testing.T) {
 		{ProviderID: "1", NPI: "123456789", ProviderType: "Primary Care", Status: "Active", County: "Sedgwick"},
 	}
 	
-	mockService.On("GetFilteredProviders", filter).Return(expectedProviders, nil)
+	mockService.On("GetFilteredProviders", filter, config.Today()).Return(expectedProviders, nil)
 	
 	router := gin.New()
 	router.POST("/filters", controller.GetFilteredData)
@@ -114,4 +198,114 @@ func TestGetFilteredDataBadRequest(t *testing.T) {
 	router.ServeHTTP(w, req)
 	
 	assert.Equal(t, http.StatusBadRequest, w.Code)
-}
\ No newline at end of file
+}
+func TestGetDataQuality(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	
+	mockService := new(MockProviderService)
+	controller := NewProviderController(mockService)
+	
+	report := &models.DataQualityReport{
+		TotalIssues: 1,
+		Checks: []models.DataQualityCheck{
+			{Name: "orphan_service_locations", File: "provider_service_locations.json", Count: 1, Samples: []interface{}{}},
+		},
+	}
+	mockService.On("GetDataQualityReport").Return(report, nil)
+	
+	router := gin.New()
+	router.GET("/data-quality", controller.GetDataQuality)
+	
+	req, _ := http.NewRequest("GET", "/data-quality", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	
+	assert.Equal(t, http.StatusOK, w.Code)
+	
+	var response models.DataQualityReport
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, response.TotalIssues)
+	assert.Equal(t, "orphan_service_locations", response.Checks[0].Name)
+	
+	mockService.AssertExpectations(t)
+}
+
+func TestGetNearestProviders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	
+	mockService := new(MockProviderService)
+	controller := NewProviderController(mockService)
+	
+	nearest := []models.NearestProvider{{
+		ProviderLocation: models.ProviderLocation{ProviderID: "P0001", NPI: "1234567890", Specialty: "Cardiology", City: "Wichita"},
+		DistanceMiles:    1.25,
+	}}
+	mockService.On("GetNearestProviders", mock.MatchedBy(func(query models.NearestProviderQuery) bool {
+		return query.Center == models.Coordinate{Latitude: 37.69, Longitude: -97.33} &&
+			query.Specialty == "Cardiology" && query.Network == "Commercial" && query.Limit == 5 && !query.IncludeOutOfState
+	})).Return(nearest, nil)
+	mockService.On("GetNearestProviders", mock.MatchedBy(func(query models.NearestProviderQuery) bool {
+		return query.Specialty == "Cardiology" && query.IncludeOutOfState
+	})).Return([]models.NearestProvider{}, nil)
+	mockService.On("GetNearestProviders", mock.MatchedBy(func(query models.NearestProviderQuery) bool {
+		return query.Specialty == "All" && query.Limit == config.DefaultNearestProviderLimit
+	})).Return([]models.NearestProvider{}, nil)
+	
+	router := gin.New()
+	router.GET("/nearest-providers", controller.GetNearestProviders)
+	
+	for _, test := range []struct {
+		query    string
+		expected int
+	}{
+		{"/nearest-providers?lat=37.69&lng=-97.33&specialty=Cardiology&network=Commercial&limit=5", http.StatusOK},
+		{"/nearest-providers?lat=37.69&lng=-97.33&network=Commercial", http.StatusOK},
+		{"/nearest-providers?network=Commercial", http.StatusBadRequest},
+		{"/nearest-providers?lat=37.69&lng=-97.33", http.StatusBadRequest},
+		{"/nearest-providers?lat=37.69&lng=-97.33&network=Commercial&limit=0", http.StatusBadRequest},
+		{"/nearest-providers?lat=37.69&lng=-97.33&network=Commercial&limit=101", http.StatusBadRequest},
+		{"/nearest-providers?lat=37.02&lng=-94.73&specialty=Cardiology&network=Commercial&include_out_of_state=true", http.StatusOK},
+		{"/nearest-providers?lat=37.02&lng=-94.73&network=Commercial&include_out_of_state=1.5", http.StatusBadRequest},
+	} {
+		req, _ := http.NewRequest("GET", test.query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		
+		assert.Equal(t, test.expected, w.Code, test.query)
+	}
+	
+	req, _ := http.NewRequest("GET", "/nearest-providers?lat=37.69&lng=-97.33&specialty=Cardiology&network=Commercial&limit=5", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	
+	var response []models.NearestProvider
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, nearest, response)
+}
+
+func TestGetServiceLocationGeoJSON(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	
+	mockService := new(MockProviderService)
+	controller := NewProviderController(mockService)
+	
+	collection := &models.FeatureCollection{Type: "FeatureCollection", Features: []models.Feature{}}
+	mockService.On("GetServiceLocationFeatures", "Commercial", "Cardiology", mock.Anything).Return(collection, nil)
+	mockService.On("GetServiceLocationFeatures", "", "All", mock.Anything).Return(collection, nil)
+	
+	router := gin.New()
+	router.GET("/geojson/service-locations", controller.GetServiceLocationGeoJSON)
+	
+	for _, query := range []string{"/geojson/service-locations?network=Commercial&specialty=Cardiology", "/geojson/service-locations"} {
+		req, _ := http.NewRequest("GET", query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		
+		assert.Equal(t, http.StatusOK, w.Code, query)
+		assert.Equal(t, services.GeoJSONContentType, w.Header().Get("Content-Type"))
+		assert.JSONEq(t, `{"type": "FeatureCollection", "features": []}`, w.Body.String())
+	}
+	
+	mockService.AssertExpectations(t)
+}
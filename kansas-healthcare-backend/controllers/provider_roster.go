@@ -0,0 +1,254 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"kansas-healthcare-api/config"
+	"kansas-healthcare-api/models"
+	"kansas-healthcare-api/services"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setETag publishes a record version as a strong entity tag
+func setETag(ctx *gin.Context, version string) {
+	ctx.Header("ETag", `"`+version+`"`)
+}
+
+// ifMatch reads the version a client expects to modify. Writes to existing records must send
+// one, so a client can never overwrite a change it has not seen.
+func ifMatch(ctx *gin.Context) (string, bool) {
+	header := strings.TrimSpace(ctx.GetHeader("If-Match"))
+	if header == "" {
+		ctx.JSON(http.StatusPreconditionRequired, gin.H{"error": "If-Match header with the record's ETag is required"})
+		return "", false
+	}
+	return strings.Trim(strings.TrimPrefix(header, "W/"), `"`), true
+}
+
+// writeError maps roster errors to status codes
+func writeError(ctx *gin.Context, action string, err error) {
+	var validationErr *services.ValidationError
+	status := http.StatusInternalServerError
+	switch {
+	case errors.As(err, &validationErr):
+		status = http.StatusBadRequest
+	case errors.Is(err, services.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, services.ErrAlreadyExists), errors.Is(err, services.ErrInUse):
+		status = http.StatusConflict
+	case errors.Is(err, services.ErrVersionMismatch):
+		status = http.StatusPreconditionFailed
+	default:
+		log.Printf("[ERROR] Failed to %s: %v", action, err)
+	}
+	ctx.JSON(status, gin.H{"error": err.Error()})
+}
+
+// effectiveDate reads the effective date (YYYY-MM-DD) that, with the provider and network, identifies
+// a network affiliation. An invalid date is answered with 400 and reported as false.
+func effectiveDate(ctx *gin.Context) (time.Time, bool) {
+	date, err := time.Parse(config.AsOfDateFormat, ctx.Param("effectiveDate"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "effective date must be a date in YYYY-MM-DD format"})
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+func (c *ProviderController) GetProvider(ctx *gin.Context) {
+	provider, version, err := c.service.GetProvider(ctx.Param("id"))
+	if err != nil {
+		writeError(ctx, "read provider", err)
+		return
+	}
+	setETag(ctx, version)
+	ctx.JSON(http.StatusOK, provider)
+}
+
+func (c *ProviderController) CreateProvider(ctx *gin.Context) {
+	var provider models.Provider
+	if err := ctx.ShouldBindJSON(&provider); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	created, version, err := c.service.CreateProvider(provider)
+	if err != nil {
+		writeError(ctx, "create provider", err)
+		return
+	}
+	log.Printf("[INFO] Created provider %s", created.ProviderID)
+	setETag(ctx, version)
+	ctx.Header("Location", "/api/v1/providers/"+created.ProviderID)
+	ctx.JSON(http.StatusCreated, created)
+}
+
+func (c *ProviderController) UpdateProvider(ctx *gin.Context) {
+	var provider models.Provider
+	if err := ctx.ShouldBindJSON(&provider); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	version, ok := ifMatch(ctx)
+	if !ok {
+		return
+	}
+	updated, newVersion, err := c.service.UpdateProvider(ctx.Param("id"), provider, version)
+	if err != nil {
+		writeError(ctx, "update provider", err)
+		return
+	}
+	log.Printf("[INFO] Updated provider %s", updated.ProviderID)
+	setETag(ctx, newVersion)
+	ctx.JSON(http.StatusOK, updated)
+}
+
+func (c *ProviderController) DeleteProvider(ctx *gin.Context) {
+	version, ok := ifMatch(ctx)
+	if !ok {
+		return
+	}
+	if err := c.service.DeleteProvider(ctx.Param("id"), version); err != nil {
+		writeError(ctx, "delete provider", err)
+		return
+	}
+	log.Printf("[INFO] Deleted provider %s", ctx.Param("id"))
+	ctx.Status(http.StatusNoContent)
+}
+
+func (c *ProviderController) GetProviderNetworkAffiliation(ctx *gin.Context) {
+	effective, ok := effectiveDate(ctx)
+	if !ok {
+		return
+	}
+	network, version, err := c.service.GetProviderNetwork(ctx.Param("id"), ctx.Param("networkId"), effective)
+	if err != nil {
+		writeError(ctx, "read network affiliation", err)
+		return
+	}
+	setETag(ctx, version)
+	ctx.JSON(http.StatusOK, network)
+}
+
+func (c *ProviderController) CreateProviderNetworkAffiliation(ctx *gin.Context) {
+	var network models.ProviderNetwork
+	if err := ctx.ShouldBindJSON(&network); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	created, version, err := c.service.CreateProviderNetwork(ctx.Param("id"), network)
+	if err != nil {
+		writeError(ctx, "create network affiliation", err)
+		return
+	}
+	log.Printf("[INFO] Added provider %s to network %s", created.ProviderID, created.NetworkID)
+	setETag(ctx, version)
+	ctx.Header("Location", fmt.Sprintf("/api/v1/providers/%s/networks/%s/%s", created.ProviderID, created.NetworkID,
+		created.EffectiveDate.Format(config.AsOfDateFormat)))
+	ctx.JSON(http.StatusCreated, created)
+}
+
+func (c *ProviderController) UpdateProviderNetworkAffiliation(ctx *gin.Context) {
+	var network models.ProviderNetwork
+	if err := ctx.ShouldBindJSON(&network); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	effective, ok := effectiveDate(ctx)
+	if !ok {
+		return
+	}
+	version, ok := ifMatch(ctx)
+	if !ok {
+		return
+	}
+	updated, newVersion, err := c.service.UpdateProviderNetwork(ctx.Param("id"), ctx.Param("networkId"), effective, network, version)
+	if err != nil {
+		writeError(ctx, "update network affiliation", err)
+		return
+	}
+	log.Printf("[INFO] Updated provider %s affiliation with network %s", updated.ProviderID, updated.NetworkID)
+	setETag(ctx, newVersion)
+	ctx.JSON(http.StatusOK, updated)
+}
+
+func (c *ProviderController) DeleteProviderNetworkAffiliation(ctx *gin.Context) {
+	effective, ok := effectiveDate(ctx)
+	if !ok {
+		return
+	}
+	version, ok := ifMatch(ctx)
+	if !ok {
+		return
+	}
+	if err := c.service.DeleteProviderNetwork(ctx.Param("id"), ctx.Param("networkId"), effective, version); err != nil {
+		writeError(ctx, "delete network affiliation", err)
+		return
+	}
+	log.Printf("[INFO] Removed provider %s from network %s from %s", ctx.Param("id"), ctx.Param("networkId"), ctx.Param("effectiveDate"))
+	ctx.Status(http.StatusNoContent)
+}
+
+func (c *ProviderController) GetServiceLocation(ctx *gin.Context) {
+	location, version, err := c.service.GetServiceLocation(ctx.Param("locationId"))
+	if err != nil {
+		writeError(ctx, "read service location", err)
+		return
+	}
+	setETag(ctx, version)
+	ctx.JSON(http.StatusOK, location)
+}
+
+func (c *ProviderController) CreateServiceLocation(ctx *gin.Context) {
+	var location models.ProviderServiceLocation
+	if err := ctx.ShouldBindJSON(&location); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	created, version, err := c.service.CreateServiceLocation(ctx.Param("id"), location)
+	if err != nil {
+		writeError(ctx, "create service location", err)
+		return
+	}
+	log.Printf("[INFO] Created service location %s", created.LocationID)
+	setETag(ctx, version)
+	ctx.Header("Location", "/api/v1/service-locations/"+created.LocationID)
+	ctx.JSON(http.StatusCreated, created)
+}
+
+func (c *ProviderController) UpdateServiceLocation(ctx *gin.Context) {
+	var location models.ProviderServiceLocation
+	if err := ctx.ShouldBindJSON(&location); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	version, ok := ifMatch(ctx)
+	if !ok {
+		return
+	}
+	updated, newVersion, err := c.service.UpdateServiceLocation(ctx.Param("locationId"), location, version)
+	if err != nil {
+		writeError(ctx, "update service location", err)
+		return
+	}
+	log.Printf("[INFO] Updated service location %s", updated.LocationID)
+	setETag(ctx, newVersion)
+	ctx.JSON(http.StatusOK, updated)
+}
+
+func (c *ProviderController) DeleteServiceLocation(ctx *gin.Context) {
+	version, ok := ifMatch(ctx)
+	if !ok {
+		return
+	}
+	if err := c.service.DeleteServiceLocation(ctx.Param("locationId"), version); err != nil {
+		writeError(ctx, "delete service location", err)
+		return
+	}
+	log.Printf("[INFO] Deleted service location %s", ctx.Param("locationId"))
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,149 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"kansas-healthcare-api/models"
+	"kansas-healthcare-api/services"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newRosterRouter(mockService *MockProviderService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	controller := NewProviderController(mockService)
+	router := gin.New()
+	router.POST("/providers", controller.CreateProvider)
+	router.GET("/providers/:id", controller.GetProvider)
+	router.PUT("/providers/:id", controller.UpdateProvider)
+	router.DELETE("/providers/:id", controller.DeleteProvider)
+	router.DELETE("/providers/:id/networks/:networkId/:effectiveDate", controller.DeleteProviderNetworkAffiliation)
+	router.POST("/providers/:id/service-locations", controller.CreateServiceLocation)
+	return router
+}
+
+func TestCreateProvider(t *testing.T) {
+	mockService := new(MockProviderService)
+	router := newRosterRouter(mockService)
+
+	provider := models.Provider{ProviderID: "P9", NPI: "1000000009", ProviderType: "Cardiology", Status: "Active", County: "Butler"}
+	mockService.On("CreateProvider", provider).Return(&provider, "abc123", nil)
+
+	body, _ := json.Marshal(provider)
+	req, _ := http.NewRequest("POST", "/providers", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, `"abc123"`, w.Header().Get("ETag"))
+	assert.Equal(t, "/api/v1/providers/P9", w.Header().Get("Location"))
+	mockService.AssertExpectations(t)
+}
+
+func TestGetProviderSetsETag(t *testing.T) {
+	mockService := new(MockProviderService)
+	router := newRosterRouter(mockService)
+
+	provider := models.Provider{ProviderID: "P9"}
+	mockService.On("GetProvider", "P9").Return(&provider, "abc123", nil)
+	mockService.On("GetProvider", "P404").Return(nil, "", fmt.Errorf("provider P404 %w", services.ErrNotFound))
+
+	req, _ := http.NewRequest("GET", "/providers/P9", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"abc123"`, w.Header().Get("ETag"))
+
+	req, _ = http.NewRequest("GET", "/providers/P404", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestUpdateProviderPreconditions(t *testing.T) {
+	mockService := new(MockProviderService)
+	router := newRosterRouter(mockService)
+
+	provider := models.Provider{ProviderID: "P9", NPI: "1000000009", ProviderType: "Cardiology", Status: "Terminated", County: "Butler"}
+	mockService.On("UpdateProvider", "P9", provider, "stale").Return(nil, "", fmt.Errorf("provider P9 %w", services.ErrVersionMismatch))
+	mockService.On("UpdateProvider", "P9", provider, "abc123").Return(&provider, "def456", nil)
+	body, _ := json.Marshal(provider)
+
+	tests := []struct {
+		ifMatch  string
+		expected int
+	}{
+		{"", http.StatusPreconditionRequired},
+		{`"stale"`, http.StatusPreconditionFailed},
+		{`"abc123"`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest("PUT", "/providers/P9", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		if tt.ifMatch != "" {
+			req.Header.Set("If-Match", tt.ifMatch)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, tt.expected, w.Code, "If-Match %q", tt.ifMatch)
+	}
+}
+
+func TestDeleteProviderInUse(t *testing.T) {
+	mockService := new(MockProviderService)
+	router := newRosterRouter(mockService)
+
+	mockService.On("DeleteProvider", "P9", "abc123").Return(fmt.Errorf("provider P9 %w", services.ErrInUse))
+
+	req, _ := http.NewRequest("DELETE", "/providers/P9", nil)
+	req.Header.Set("If-Match", `W/"abc123"`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestDeleteProviderNetworkAffiliationByEffectiveDate(t *testing.T) {
+	mockService := new(MockProviderService)
+	router := newRosterRouter(mockService)
+
+	mockService.On("DeleteProviderNetwork", "P9", "Medicare", time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), "abc123").Return(nil)
+
+	req, _ := http.NewRequest("DELETE", "/providers/P9/networks/Medicare/2021-01-01", nil)
+	req.Header.Set("If-Match", `"abc123"`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	req, _ = http.NewRequest("DELETE", "/providers/P9/networks/Medicare/2021-13-01", nil)
+	req.Header.Set("If-Match", `"abc123"`)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestCreateServiceLocationValidationError(t *testing.T) {
+	mockService := new(MockProviderService)
+	router := newRosterRouter(mockService)
+
+	location := models.ProviderServiceLocation{County: "Sedgwick"}
+	mockService.On("CreateServiceLocation", "P9", location).Return(nil, "", &services.ValidationError{Field: "latitude", Message: "and longitude must fall within Kansas"})
+
+	body, _ := json.Marshal(location)
+	req, _ := http.NewRequest("POST", "/providers/P9/service-locations", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "latitude")
+}
@@ -0,0 +1,59 @@
+package data
+
+import (
+	"kansas-healthcare-api/models"
+	"math"
+	"sort"
+)
+
+// countySamplePoints lays a gridSize x gridSize grid of cell centres over a square with the
+// county's area, centred on its centroid. The square stands in for the boundary of a county
+// missing from the boundary dataset; countyLocator.samplePoints samples the others.
+func countySamplePoints(centroid models.Coordinate, areaSqMiles float64, gridSize int) []models.Coordinate {
+	if gridSize <= 0 {
+		return []models.Coordinate{}
+	}
+	side := math.Sqrt(areaSqMiles)
+	latSpan := side / milesPerDegreeLatitude
+	lngSpan := latSpan / math.Cos(centroid.Latitude*math.Pi/180)
+
+	points := make([]models.Coordinate, 0, gridSize*gridSize)
+	for row := 0; row < gridSize; row++ {
+		for col := 0; col < gridSize; col++ {
+			points = append(points, models.Coordinate{
+				Latitude:  centroid.Latitude + latSpan*((float64(row)+0.5)/float64(gridSize)-0.5),
+				Longitude: centroid.Longitude + lngSpan*((float64(col)+0.5)/float64(gridSize)-0.5),
+			})
+		}
+	}
+	return points
+}
+
+// nearestDistances returns the distance in miles from each point to the closest location in
+// index, or +Inf for every point when the index is empty
+func nearestDistances(index *spatialIndex, points []models.Coordinate, accept func(id int) bool) []float64 {
+	distances := make([]float64, len(points))
+	for i, point := range points {
+		distances[i] = math.Inf(1)
+		if neighbours := index.nearestN(point, 1, accept, nil); len(neighbours) > 0 {
+			distances[i] = neighbours[0].distance
+		}
+	}
+	return distances
+}
+
+// locationsWithin returns, for each point, the accepted locations in index no more than radius
+// miles away, ordered by location ID so both backends agree
+func locationsWithin(index *spatialIndex, points []models.Coordinate, radius float64, accept func(id int) bool, locationID func(id int) string) [][]models.LocationDistance {
+	reachable := make([][]models.LocationDistance, len(points))
+	for i, point := range points {
+		reachable[i] = []models.LocationDistance{}
+		index.within(point, radius, func(id int, distance float64) {
+			if accept(id) {
+				reachable[i] = append(reachable[i], models.LocationDistance{LocationID: locationID(id), DistanceMiles: distance})
+			}
+		})
+		sort.Slice(reachable[i], func(a, b int) bool { return reachable[i][a].LocationID < reachable[i][b].LocationID })
+	}
+	return reachable
+}
@@ -0,0 +1,197 @@
+package data
+
+import (
+	"errors"
+	"kansas-healthcare-api/config"
+	"kansas-healthcare-api/models"
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCountySamplePointsCoverCountyArea(t *testing.T) {
+	centroid := models.Coordinate{Latitude: 37.684, Longitude: -97.461}
+	points := countySamplePoints(centroid, 1009.8, 5)
+	require.Len(t, points, 25)
+
+	// The middle cell centre is the centroid, and neighbouring cells lie one fifth of the side apart
+	assert.InDelta(t, centroid.Latitude, points[12].Latitude, 1e-9)
+	assert.InDelta(t, centroid.Longitude, points[12].Longitude, 1e-9)
+	spacing := math.Sqrt(1009.8) / 5
+	assert.InDelta(t, spacing, haversineDistance(points[12].Latitude, points[12].Longitude, points[13].Latitude, points[13].Longitude), 0.05)
+	assert.InDelta(t, spacing, haversineDistance(points[12].Latitude, points[12].Longitude, points[17].Latitude, points[17].Longitude), 0.05)
+
+	assert.Empty(t, countySamplePoints(centroid, 1009.8, 0))
+}
+
+func TestNearestProviderDistances(t *testing.T) {
+	repo := newRadiusFixture()
+	asOf := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	wichita := models.Coordinate{Latitude: 37.6872, Longitude: -97.3301}
+	elDorado := models.Coordinate{Latitude: 37.8172, Longitude: -96.8622}
+	points := []models.Coordinate{wichita, elDorado}
+
+	distances, err := repo.GetNearestProviderDistances(points, "Primary Care", "Commercial", asOf, false)
+	require.NoError(t, err)
+	assert.Equal(t, 0.0, distances[0])
+	assert.InDelta(t, haversineDistance(elDorado.Latitude, elDorado.Longitude, wichita.Latitude, wichita.Longitude), distances[1], 1e-9)
+
+	// The only pediatrician is out of network, so no point has one in reach
+	distances, err = repo.GetNearestProviderDistances(points, "Pediatrics", "Commercial", asOf, false)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{math.Inf(1), math.Inf(1)}, distances)
+
+	samples, err := repo.GetCountySamplePoints("Sedgwick", config.AdequacySampleGridSize)
+	require.NoError(t, err)
+	assert.Len(t, samples, 25)
+	_, err = repo.GetCountySamplePoints("Butler", config.AdequacySampleGridSize)
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
+
+func TestProviderLocationsWithin(t *testing.T) {
+	repo := newRadiusFixture()
+	asOf := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	wichita := models.Coordinate{Latitude: 37.6872, Longitude: -97.3301}
+	overlandPark := models.Coordinate{Latitude: 38.9822, Longitude: -94.6708}
+
+	reachable, err := repo.GetProviderLocationsWithin([]models.Coordinate{wichita, overlandPark}, 30, "All", "Commercial", asOf)
+	require.NoError(t, err)
+	require.Len(t, reachable, 2)
+
+	// Hutchinson is beyond 30 miles and the pediatrician is out of network
+	ids := make([]string, len(reachable[0]))
+	for i, location := range reachable[0] {
+		ids[i] = location.LocationID
+	}
+	assert.Equal(t, []string{"P1-1", "P1-2", "P2-1"}, ids)
+	assert.Equal(t, 0.0, reachable[0][0].DistanceMiles)
+	assert.Equal(t, []models.LocationDistance{{LocationID: "P4-1", DistanceMiles: 0}}, reachable[1])
+
+	reachable, err = repo.GetProviderLocationsWithin([]models.Coordinate{overlandPark}, 30, "Cardiology", "Commercial", asOf)
+	require.NoError(t, err)
+	assert.Equal(t, [][]models.LocationDistance{{}}, reachable)
+}
+
+func TestSQLAdequacyMatchesJSON(t *testing.T) {
+	jsonRepo := newRadiusFixture()
+	snapshot := *jsonRepo.snapshot()
+	snapshot.adequacyStandards = []models.AdequacyStandard{
+		{Specialty: "Cardiology", Designation: config.DesignationMetro, MaxMinutes: 30, MaxMiles: 20},
+		{Specialty: "Cardiology", Designation: config.DesignationMicro, MaxMinutes: 50, MaxMiles: 35},
+	}
+	snapshot.countyDesignations = []models.CountyDesignation{
+		{County: "Butler", Designation: config.DesignationMicro},
+		{County: "Sedgwick", Designation: config.DesignationMetro},
+	}
+	snapshot.countyPopulation = []models.CountyPopulation{
+		{County: "Butler", Population: 67380},
+		{County: "Sedgwick", Population: 523824},
+	}
+	jsonRepo = newJSONRepositoryFrom(&snapshot)
+	sqlRepo := newSQLiteTestRepository(t, jsonRepo)
+	asOf := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+
+	for _, repo := range []Repository{jsonRepo, sqlRepo} {
+		standards, err := repo.GetAdequacyStandards()
+		require.NoError(t, err)
+		assert.Equal(t, snapshot.adequacyStandards, standards)
+		designations, err := repo.GetCountyDesignations()
+		require.NoError(t, err)
+		assert.Equal(t, snapshot.countyDesignations, designations)
+		population, err := repo.GetCountyPopulation()
+		require.NoError(t, err)
+		assert.Equal(t, snapshot.countyPopulation, population)
+	}
+
+	expectedPoints, err := jsonRepo.GetCountySamplePoints("Sedgwick", config.AdequacySampleGridSize)
+	require.NoError(t, err)
+	actualPoints, err := sqlRepo.GetCountySamplePoints("Sedgwick", config.AdequacySampleGridSize)
+	require.NoError(t, err)
+	assert.Equal(t, expectedPoints, actualPoints)
+	_, err = sqlRepo.GetCountySamplePoints("Butler", config.AdequacySampleGridSize)
+	assert.True(t, errors.Is(err, ErrNotFound))
+
+	for _, specialty := range []string{"All", "Primary Care", "Cardiology", "Pediatrics"} {
+		expected, err := jsonRepo.GetNearestProviderDistances(expectedPoints, specialty, "Commercial", asOf, false)
+		require.NoError(t, err)
+		actual, err := sqlRepo.GetNearestProviderDistances(expectedPoints, specialty, "Commercial", asOf, false)
+		require.NoError(t, err)
+		assert.Equal(t, expected, actual, specialty)
+
+		expectedWithin, err := jsonRepo.GetProviderLocationsWithin(expectedPoints, 30, specialty, "Commercial", asOf)
+		require.NoError(t, err)
+		actualWithin, err := sqlRepo.GetProviderLocationsWithin(expectedPoints, 30, specialty, "Commercial", asOf)
+		require.NoError(t, err)
+		assert.Equal(t, expectedWithin, actualWithin, specialty)
+	}
+}
+
+func TestLoadRejectsInvalidAdequacyData(t *testing.T) {
+	for _, test := range []struct {
+		file  string
+		body  string
+		field string
+	}{
+		{adequacyStandardsFile, `[{"specialty": "Cardiology", "designation": "Suburban", "max_minutes": 30, "max_miles": 20}]`, "designation"},
+		{adequacyStandardsFile, `[{"specialty": "Cardiology", "designation": "Metro", "max_minutes": 30, "max_miles": 0}]`, "max_miles"},
+		{adequacyStandardsFile, `[
+  {"specialty": "Cardiology", "designation": "Metro", "max_minutes": 30, "max_miles": 20},
+  {"specialty": "Cardiology", "designation": "Metro", "max_minutes": 45, "max_miles": 30}
+]`, "designation"},
+		{countyDesignationsFile, `[{"county": "Sedgwick", "designation": "Metro"}, {"county": "Sedgwick", "designation": "Rural"}]`, "county"},
+		{countyPopulationFile, `[{"county": "Sedgwick", "population": -1}]`, "population"},
+		{countyPopulationFile, `[{"county": "Sedgwick", "population": 523824}, {"county": "Sedgwick", "population": 1}]`, "county"},
+	} {
+		dir := t.TempDir()
+		writeTestDataset(t, dir, []models.Provider{{ProviderID: "P1", Status: "Active", County: "Sedgwick"}})
+		writeTestFile(t, filepath.Join(dir, test.file), []byte(test.body))
+
+		_, err := NewJSONRepository(LoadOptions{DataDir: dir})
+		var loadErr *LoadError
+		require.True(t, errors.As(err, &loadErr), test.body)
+		assert.Equal(t, test.field, loadErr.Field, test.body)
+	}
+}
+
+func TestEmbeddedAdequacyDataCoversEveryKansasCounty(t *testing.T) {
+	repo, err := NewJSONRepository(LoadOptions{})
+	require.NoError(t, err)
+
+	designations, err := repo.GetCountyDesignations()
+	require.NoError(t, err)
+	assert.Len(t, designations, 105)
+
+	// The 2020 census count of every Kansas county, adding up to the state total
+	population, err := repo.GetCountyPopulation()
+	require.NoError(t, err)
+	require.Len(t, population, 105)
+	total := 0
+	for i, county := range population {
+		assert.Equal(t, designations[i].County, county.County)
+		total += county.Population
+	}
+	assert.Equal(t, 2937880, total)
+
+	// Every county has a centroid to sample around and search from
+	centroids := repo.snapshot().idx.centroidByCounty
+	for _, county := range population {
+		_, ok := centroids[county.County]
+		assert.True(t, ok, "centroid of %s", county.County)
+	}
+	_, err = repo.GetRadiusAnalysis("Wyandotte", nil, 25, "Commercial", time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC), false)
+	assert.NoError(t, err)
+
+	standards, err := repo.GetAdequacyStandards()
+	require.NoError(t, err)
+	bySpecialty := make(map[string]int)
+	for _, standard := range standards {
+		bySpecialty[standard.Specialty]++
+	}
+	for specialty, count := range bySpecialty {
+		assert.Equal(t, len(validDesignations), count, "%s has a standard for every designation", specialty)
+	}
+}
@@ -0,0 +1,214 @@
+package data
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"kansas-healthcare-api/config"
+	"kansas-healthcare-api/models"
+	"math"
+)
+
+// geoJSONFeatureCollection is the subset of GeoJSON the county boundary file is read from
+type geoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []geoJSONFeature `json:"features"`
+}
+
+type geoJSONFeature struct {
+	Properties struct {
+		County    string  `json:"county"`
+		Latitude  float64 `json:"latitude"`
+		Longitude float64 `json:"longitude"`
+	} `json:"properties"`
+	Geometry struct {
+		Type        string          `json:"type"`
+		Coordinates json.RawMessage `json:"coordinates"`
+	} `json:"geometry"`
+}
+
+// loadCountyBoundaries decodes a GeoJSON FeatureCollection with one Polygon or MultiPolygon
+// feature per county, named by its county property and centred on its latitude and longitude
+func loadCountyBoundaries(path string, body []byte, target *[]models.CountyBoundary) error {
+	var collection geoJSONFeatureCollection
+	if err := loadObject(path, body, &collection); err != nil {
+		return err
+	}
+	if collection.Type != "FeatureCollection" {
+		return positionError(path, body, skipSeparators(body, 0), -1, "type", errors.New("expected a GeoJSON FeatureCollection"))
+	}
+
+	boundaries := make([]models.CountyBoundary, len(collection.Features))
+	for i, feature := range collection.Features {
+		boundary := models.CountyBoundary{
+			County:   feature.Properties.County,
+			Centroid: models.Coordinate{Latitude: feature.Properties.Latitude, Longitude: feature.Properties.Longitude},
+		}
+		coordinates := bytes.NewReader(feature.Geometry.Coordinates)
+		var err error
+		switch feature.Geometry.Type {
+		case "Polygon":
+			var polygon models.Polygon
+			if err = json.NewDecoder(coordinates).Decode(&polygon); err == nil {
+				boundary.Polygons = []models.Polygon{polygon}
+			}
+		case "MultiPolygon":
+			err = json.NewDecoder(coordinates).Decode(&boundary.Polygons)
+		default:
+			return recordError(path, i, "geometry", fmt.Errorf("type %q is not a Polygon or MultiPolygon", feature.Geometry.Type))
+		}
+		if err != nil {
+			return recordError(path, i, "geometry", unwrapTypeError(err))
+		}
+		boundaries[i] = boundary
+	}
+	*target = boundaries
+	return nil
+}
+
+// validatePolygon checks that every ring of a polygon is closed and encloses an area
+func validatePolygon(polygon models.Polygon) error {
+	if len(polygon) == 0 {
+		return errors.New("polygon has no rings")
+	}
+	for i, ring := range polygon {
+		if len(ring) < 4 {
+			return fmt.Errorf("ring %d has %d positions, fewer than the 4 of a closed triangle", i, len(ring))
+		}
+		if ring[0] != ring[len(ring)-1] {
+			return fmt.Errorf("ring %d does not end where it starts", i)
+		}
+	}
+	return nil
+}
+
+// polygonContains reports whether a point lies inside a polygon and outside its holes, by
+// counting how many ring edges a ray cast east from the point crosses (the even-odd rule). Over
+// the span of a county, treating degrees as planar coordinates moves a border by far less than
+// the boundary data is accurate to.
+func polygonContains(polygon models.Polygon, point models.Coordinate) bool {
+	x, y := point.Longitude, point.Latitude
+	inside := false
+	for _, ring := range polygon {
+		for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+			xi, yi := ring[i][0], ring[i][1]
+			xj, yj := ring[j][0], ring[j][1]
+			if (yi > y) != (yj > y) && x < xi+(y-yi)*(xj-xi)/(yj-yi) {
+				inside = !inside
+			}
+		}
+	}
+	return inside
+}
+
+// countyLocator finds which county boundary a point lies in, checking a bounding box before
+// each polygon
+type countyLocator struct {
+	boundaries []models.CountyBoundary
+	boxes      [][4]float64 // min longitude, min latitude, max longitude, max latitude
+	byCounty   map[string]int
+}
+
+func newCountyLocator(boundaries []models.CountyBoundary) *countyLocator {
+	l := &countyLocator{
+		boundaries: boundaries,
+		boxes:      make([][4]float64, len(boundaries)),
+		byCounty:   make(map[string]int, len(boundaries)),
+	}
+	for i, boundary := range boundaries {
+		box := [4]float64{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)}
+		for _, polygon := range boundary.Polygons {
+			for _, ring := range polygon {
+				for _, position := range ring {
+					box[0], box[1] = math.Min(box[0], position[0]), math.Min(box[1], position[1])
+					box[2], box[3] = math.Max(box[2], position[0]), math.Max(box[3], position[1])
+				}
+			}
+		}
+		l.boxes[i] = box
+		if _, exists := l.byCounty[boundary.County]; !exists {
+			l.byCounty[boundary.County] = i
+		}
+	}
+	return l
+}
+
+// stateBounds returns the box around every county boundary, or the state's configured bounding
+// box when there are no boundaries
+func stateBounds(l *countyLocator) (minLat, minLng, maxLat, maxLng float64) {
+	if len(l.boundaries) == 0 {
+		return config.KansasMinLatitude, config.KansasMinLongitude, config.KansasMaxLatitude, config.KansasMaxLongitude
+	}
+	minLat, minLng, maxLat, maxLng = math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+	for _, box := range l.boxes {
+		minLng, minLat = math.Min(minLng, box[0]), math.Min(minLat, box[1])
+		maxLng, maxLat = math.Max(maxLng, box[2]), math.Max(maxLat, box[3])
+	}
+	return minLat, minLng, maxLat, maxLng
+}
+
+// contains reports whether the point lies in the county. ok is false when the county has no boundary.
+func (l *countyLocator) contains(county string, point models.Coordinate) (inside, ok bool) {
+	i, ok := l.byCounty[county]
+	if !ok {
+		return false, false
+	}
+	return l.boundaryContains(i, point), true
+}
+
+// locate returns the county the point lies in, or "" when it is outside every boundary
+func (l *countyLocator) locate(point models.Coordinate) string {
+	for i, boundary := range l.boundaries {
+		if l.boundaryContains(i, point) {
+			return boundary.County
+		}
+	}
+	return ""
+}
+
+// samplePoints lays a gridSize x gridSize grid of cell centres over the bounding box of the
+// county's boundary and keeps those inside it, so the points follow the county's shape. A boundary
+// too small or thin for any centre to fall inside is sampled at its centroid. ok is false when the
+// county has no boundary.
+func (l *countyLocator) samplePoints(county string, gridSize int) (points []models.Coordinate, ok bool) {
+	i, ok := l.byCounty[county]
+	if !ok {
+		return nil, false
+	}
+	points = []models.Coordinate{}
+	if gridSize <= 0 {
+		return points, true
+	}
+	box := l.boxes[i]
+	lngStep := (box[2] - box[0]) / float64(gridSize)
+	latStep := (box[3] - box[1]) / float64(gridSize)
+	for row := 0; row < gridSize; row++ {
+		for col := 0; col < gridSize; col++ {
+			point := models.Coordinate{
+				Latitude:  box[1] + latStep*(float64(row)+0.5),
+				Longitude: box[0] + lngStep*(float64(col)+0.5),
+			}
+			if l.boundaryContains(i, point) {
+				points = append(points, point)
+			}
+		}
+	}
+	if len(points) == 0 {
+		points = append(points, l.boundaries[i].Centroid)
+	}
+	return points, true
+}
+
+func (l *countyLocator) boundaryContains(i int, point models.Coordinate) bool {
+	box := l.boxes[i]
+	if point.Longitude < box[0] || point.Longitude > box[2] || point.Latitude < box[1] || point.Latitude > box[3] {
+		return false
+	}
+	for _, polygon := range l.boundaries[i].Polygons {
+		if polygonContains(polygon, point) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,188 @@
+package data
+
+import (
+	"errors"
+	"kansas-healthcare-api/models"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rectangle returns a closed ring around a box of longitudes and latitudes
+func rectangle(minLng, minLat, maxLng, maxLat float64) [][2]float64 {
+	return [][2]float64{{minLng, minLat}, {maxLng, minLat}, {maxLng, maxLat}, {minLng, maxLat}, {minLng, minLat}}
+}
+
+// testBoundaries splits a box into Sedgwick to the west and Butler to the east
+func testBoundaries() []models.CountyBoundary {
+	return []models.CountyBoundary{
+		{County: "Sedgwick", Centroid: models.Coordinate{Latitude: 37.684, Longitude: -97.461}, Polygons: []models.Polygon{{rectangle(-97.81, 37.47, -97.15, 37.91)}}},
+		{County: "Butler", Centroid: models.Coordinate{Latitude: 37.78, Longitude: -96.84}, Polygons: []models.Polygon{{rectangle(-97.15, 37.47, -96.52, 38.17)}}},
+	}
+}
+
+func TestPolygonContains(t *testing.T) {
+	// A square with a square hole in the middle
+	polygon := models.Polygon{rectangle(0, 0, 10, 10), rectangle(4, 4, 6, 6)}
+
+	for _, test := range []struct {
+		point  models.Coordinate
+		inside bool
+	}{
+		{models.Coordinate{Latitude: 1, Longitude: 1}, true},
+		{models.Coordinate{Latitude: 9.99, Longitude: 5}, true},
+		{models.Coordinate{Latitude: 5, Longitude: 5}, false},
+		{models.Coordinate{Latitude: 11, Longitude: 5}, false},
+		{models.Coordinate{Latitude: 5, Longitude: -0.01}, false},
+	} {
+		assert.Equal(t, test.inside, polygonContains(polygon, test.point), "%v", test.point)
+	}
+}
+
+func TestCountyLocator(t *testing.T) {
+	locator := newCountyLocator(testBoundaries())
+	wichita := models.Coordinate{Latitude: 37.6872, Longitude: -97.3301}
+	elDorado := models.Coordinate{Latitude: 37.8172, Longitude: -96.8622}
+
+	assert.Equal(t, "Sedgwick", locator.locate(wichita))
+	assert.Equal(t, "Butler", locator.locate(elDorado))
+	assert.Equal(t, "", locator.locate(models.Coordinate{Latitude: 38.9822, Longitude: -94.6708}))
+
+	inside, ok := locator.contains("Sedgwick", elDorado)
+	assert.True(t, ok)
+	assert.False(t, inside)
+	_, ok = locator.contains("Atlantis", wichita)
+	assert.False(t, ok)
+}
+
+func TestCountyLocatorSamplePoints(t *testing.T) {
+	locator := newCountyLocator([]models.CountyBoundary{
+		// An L with its north-east quarter missing
+		{County: "Ell", Polygons: []models.Polygon{{{{0, 0}, {10, 0}, {10, 5}, {5, 5}, {5, 10}, {0, 10}, {0, 0}}}}},
+		// A square with a hole where its middle would be
+		{County: "Frame", Centroid: models.Coordinate{Latitude: 1, Longitude: 1}, Polygons: []models.Polygon{{rectangle(0, 0, 10, 10), rectangle(4, 4, 6, 6)}}},
+	})
+
+	// The grid covers the bounding box, and the centres in the missing quarter are dropped
+	points, ok := locator.samplePoints("Ell", 4)
+	require.True(t, ok)
+	assert.Len(t, points, 12)
+	for _, point := range points {
+		assert.False(t, point.Latitude > 5 && point.Longitude > 5, "%v", point)
+	}
+	assert.Equal(t, models.Coordinate{Latitude: 1.25, Longitude: 1.25}, points[0])
+
+	// When no centre falls inside, the centroid stands in
+	points, _ = locator.samplePoints("Frame", 1)
+	assert.Equal(t, []models.Coordinate{{Latitude: 1, Longitude: 1}}, points)
+
+	_, ok = locator.samplePoints("Atlantis", 4)
+	assert.False(t, ok)
+}
+
+func TestBoundaryMismatchesAreReported(t *testing.T) {
+	locations := []models.ProviderServiceLocation{
+		{LocationID: "P1-1", ProviderID: "P1", County: "Sedgwick", Latitude: 37.6872, Longitude: -97.3301},
+		// Declared Sedgwick but geocoded to El Dorado
+		{LocationID: "P1-2", ProviderID: "P1", County: "Sedgwick", Latitude: 37.8172, Longitude: -96.8622},
+		// No boundary for Reno, so nothing to check against
+		{LocationID: "P2-1", ProviderID: "P2", County: "Reno", Latitude: 37.6872, Longitude: -97.3301},
+		// Not geocoded
+		{LocationID: "P3-1", ProviderID: "P3", County: "Butler"},
+	}
+
+	report := checkDataQuality(nil, nil, locations, nil, nil, testBoundaries(), NewGeocoder(nil, nil))
+	check := findCheck(t, report, "county_boundary_mismatches")
+	require.Equal(t, 1, check.Count)
+	assert.Equal(t, boundaryMismatchSample{
+		LocationID: "P1-2", ProviderID: "P1", County: "Sedgwick", LocatedIn: "Butler", Latitude: 37.8172, Longitude: -96.8622,
+	}, check.Samples[0])
+
+	assert.Zero(t, findCheck(t, checkDataQuality(nil, nil, locations, nil, nil, nil, NewGeocoder(nil, nil)), "county_boundary_mismatches").Count)
+}
+
+func TestLoadRejectsInvalidCountyBoundaries(t *testing.T) {
+	for _, test := range []struct {
+		body  string
+		field string
+	}{
+		{`{"type": "Feature", "features": []}`, "type"},
+		{`{"type": "FeatureCollection", "features": [{"properties": {"county": "Sedgwick"}, "geometry": {"type": "Point", "coordinates": [-97.4, 37.7]}}]}`, "geometry"},
+		{`{"type": "FeatureCollection", "features": [{"properties": {"county": "Sedgwick"}, "geometry": {"type": "Polygon", "coordinates": [[[-97.8, 37.4], [-97.1, 37.4], [-97.1, 37.9], [-97.8, 37.9]]]}}]}`, "geometry"},
+		{`{"type": "FeatureCollection", "features": [{"properties": {"county": "Sedgwick"}, "geometry": {"type": "Polygon", "coordinates": "Sedgwick"}}]}`, "geometry"},
+		{`{"type": "FeatureCollection", "features": [
+  {"properties": {"county": "Sedgwick"}, "geometry": {"type": "Polygon", "coordinates": [[[-97.8, 37.4], [-97.1, 37.4], [-97.1, 37.9], [-97.8, 37.4]]]}},
+  {"properties": {"county": "Sedgwick"}, "geometry": {"type": "MultiPolygon", "coordinates": [[[[-97.8, 37.4], [-97.1, 37.4], [-97.1, 37.9], [-97.8, 37.4]]]]}}
+]}`, "county"},
+	} {
+		dir := t.TempDir()
+		writeTestDataset(t, dir, []models.Provider{{ProviderID: "P1", Status: "Active", County: "Sedgwick"}})
+		writeTestFile(t, filepath.Join(dir, countyBoundariesFile), []byte(test.body))
+
+		_, err := NewJSONRepository(LoadOptions{DataDir: dir})
+		var loadErr *LoadError
+		require.True(t, errors.As(err, &loadErr), test.body)
+		assert.Equal(t, test.field, loadErr.Field, test.body)
+	}
+}
+
+func TestEmbeddedBoundariesCoverEveryKansasCounty(t *testing.T) {
+	repo, err := NewJSONRepository(LoadOptions{})
+	require.NoError(t, err)
+
+	boundaries, err := repo.GetCountyBoundaries()
+	require.NoError(t, err)
+	assert.Len(t, boundaries, 105)
+
+	locator := newCountyLocator(boundaries)
+	for _, boundary := range boundaries {
+		assert.Equal(t, boundary.County, locator.locate(boundary.Centroid), "centroid of %s", boundary.County)
+	}
+
+	// The centroids used for radius searches fall inside the county they belong to
+	for _, area := range repo.snapshot().countyAreas {
+		inside, ok := locator.contains(area.County, models.Coordinate{Latitude: area.Latitude, Longitude: area.Longitude})
+		assert.True(t, ok && inside, area.County)
+	}
+
+	_, err = repo.GetCountyBoundary("Atlantis")
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
+
+func TestSQLCountyBoundariesMatchJSON(t *testing.T) {
+	// The location sits in El Dorado, across the line from the county it claims
+	jsonRepo := newProviderFixture(&jsonSnapshot{
+		countyAreas:      []models.CountyArea{{County: "Sedgwick", AreaSqMiles: 1009.8}, {County: "Butler", AreaSqMiles: 1428.8}},
+		countyBoundaries: testBoundaries(),
+	},
+		fixtureProvider{id: "P1", specialty: "Primary Care", county: "Sedgwick", network: "Commercial",
+			locations: []fixtureLocation{{latitude: 37.8172, longitude: -96.8622}}},
+	)
+	sqlRepo := newSQLiteTestRepository(t, jsonRepo)
+
+	expected, err := jsonRepo.GetCountyBoundary("Butler")
+	require.NoError(t, err)
+	actual, err := sqlRepo.GetCountyBoundary("Butler")
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+	_, err = sqlRepo.GetCountyBoundary("Atlantis")
+	assert.True(t, errors.Is(err, ErrNotFound))
+
+	// Counties with a boundary are sampled within it, even without a centroid in county_areas.json
+	expectedPoints, err := jsonRepo.GetCountySamplePoints("Butler", 5)
+	require.NoError(t, err)
+	assert.Len(t, expectedPoints, 25)
+	actualPoints, err := sqlRepo.GetCountySamplePoints("Butler", 5)
+	require.NoError(t, err)
+	assert.Equal(t, expectedPoints, actualPoints)
+
+	all, err := sqlRepo.GetCountyBoundaries()
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+
+	report, err := sqlRepo.GetDataQualityReport()
+	require.NoError(t, err)
+	assert.Equal(t, 1, findCheck(t, report, "county_boundary_mismatches").Count)
+}
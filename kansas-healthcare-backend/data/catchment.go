@@ -0,0 +1,209 @@
+package data
+
+import (
+	"kansas-healthcare-api/models"
+	"math"
+	"sort"
+)
+
+// planar is a position in miles on an equirectangular projection about the state's middle
+// latitude. Across Kansas, straight lines there stay within about two percent of great-circle
+// distances, close enough to draw catchment borders.
+type planar struct{ x, y float64 }
+
+type projection struct {
+	milesPerDegreeLongitude float64
+}
+
+func newProjection(minLat, maxLat float64) projection {
+	return projection{milesPerDegreeLongitude: milesPerDegreeLatitude * math.Cos((minLat+maxLat)/2*math.Pi/180)}
+}
+
+func (p projection) forward(longitude, latitude float64) planar {
+	return planar{longitude * p.milesPerDegreeLongitude, latitude * milesPerDegreeLatitude}
+}
+
+// inverse returns a [longitude, latitude] position rounded to a millionth of a degree, a few inches
+func (p projection) inverse(q planar) [2]float64 {
+	round := func(degrees float64) float64 { return math.Round(degrees*1e6) / 1e6 }
+	return [2]float64{round(q.x / p.milesPerDegreeLongitude), round(q.y / milesPerDegreeLatitude)}
+}
+
+// clipRing keeps the part of an open ring where side is not positive: one Sutherland-Hodgman step.
+// side must be linear in the position, so an edge crossing the line is cut where side reaches
+// zero. Clipping a concave ring can leave zero-width slivers along the line, which add no area.
+func clipRing(ring []planar, side func(planar) float64) []planar {
+	var clipped []planar
+	for i, current := range ring {
+		previous := ring[(i+len(ring)-1)%len(ring)]
+		sc, sp := side(current), side(previous)
+		if (sc < 0 && sp > 0) || (sc > 0 && sp < 0) {
+			t := sp / (sp - sc)
+			clipped = append(clipped, planar{previous.x + t*(current.x-previous.x), previous.y + t*(current.y-previous.y)})
+		}
+		if sc <= 0 {
+			clipped = append(clipped, current)
+		}
+	}
+	return clipped
+}
+
+// clipToConvex clips a ring to the inside of a convex counterclockwise polygon, one edge at a time
+func clipToConvex(ring, convex []planar) []planar {
+	for i := 0; i < len(convex) && len(ring) >= 3; i++ {
+		a, b := convex[i], convex[(i+1)%len(convex)]
+		ring = clipRing(ring, func(p planar) float64 { return -((b.x-a.x)*(p.y-a.y) - (b.y-a.y)*(p.x-a.x)) })
+	}
+	if len(ring) < 3 {
+		return nil
+	}
+	return ring
+}
+
+// ringArea returns the area enclosed by an open ring, in square miles
+func ringArea(ring []planar) float64 {
+	sum := 0.0
+	for i, current := range ring {
+		next := ring[(i+1)%len(ring)]
+		sum += current.x*next.y - next.x*current.y
+	}
+	return math.Abs(sum) / 2
+}
+
+// voronoiCell clips the bounding box to the positions closer to sites[i] than to any other site.
+// Other sites are visited nearest first, and once one is more than twice as far away as the
+// furthest corner of the cell so far, no site beyond it can cut the cell any further.
+func voronoiCell(box []planar, sites []planar, i int) []planar {
+	site := sites[i]
+	others := make([]int, 0, len(sites)-1)
+	for j := range sites {
+		if j != i {
+			others = append(others, j)
+		}
+	}
+	distance := func(p planar) float64 { return math.Hypot(p.x-site.x, p.y-site.y) }
+	sort.Slice(others, func(a, b int) bool { return distance(sites[others[a]]) < distance(sites[others[b]]) })
+
+	cell := box
+	for _, j := range others {
+		reach := 0.0
+		for _, corner := range cell {
+			reach = math.Max(reach, distance(corner))
+		}
+		if distance(sites[j]) > 2*reach {
+			break
+		}
+		other := sites[j]
+		mid := planar{(site.x + other.x) / 2, (site.y + other.y) / 2}
+		cell = clipRing(cell, func(p planar) float64 { return (p.x-mid.x)*(other.x-site.x) + (p.y-mid.y)*(other.y-site.y) })
+		if len(cell) < 3 {
+			return nil
+		}
+	}
+	return cell
+}
+
+// catchments groups the geocoded locations by position and returns the Voronoi cell around each
+// position, split into the parts lying in each county boundary. Without boundaries each cell is
+// clipped to the state's bounding box and has a single part with no county. Catchments follow
+// the order of the locations and their parts are sorted by county.
+func catchments(locator *countyLocator, locations []models.ProviderLocation) []models.Catchment {
+	minLat, minLng, maxLat, maxLng := stateBounds(locator)
+	proj := newProjection(minLat, maxLat)
+	box := []planar{proj.forward(minLng, minLat), proj.forward(maxLng, minLat), proj.forward(maxLng, maxLat), proj.forward(minLng, maxLat)}
+
+	result := []models.Catchment{}
+	var sites []planar
+	byPosition := make(map[models.Coordinate]int)
+	var providers []map[string]bool
+	for _, location := range locations {
+		position := models.Coordinate{Latitude: location.Latitude, Longitude: location.Longitude}
+		if position.Latitude == 0 && position.Longitude == 0 {
+			continue
+		}
+		i, ok := byPosition[position]
+		if !ok {
+			i = len(result)
+			byPosition[position] = i
+			result = append(result, models.Catchment{Site: position, City: location.City, County: location.County})
+			sites = append(sites, proj.forward(position.Longitude, position.Latitude))
+			providers = append(providers, make(map[string]bool))
+		}
+		result[i].LocationIDs = append(result[i].LocationIDs, location.LocationID)
+		if !providers[i][location.ProviderID] {
+			providers[i][location.ProviderID] = true
+			result[i].ProviderIDs = append(result[i].ProviderIDs, location.ProviderID)
+		}
+	}
+
+	for i := range result {
+		cell := voronoiCell(box, sites, i)
+		if cell == nil {
+			continue
+		}
+		if len(locator.boundaries) == 0 {
+			result[i].Parts = []models.CatchmentPart{{AreaSqMiles: ringArea(cell), Polygons: []models.Polygon{closedRing(proj, cell)}}}
+			continue
+		}
+
+		cellBox := [4]float64{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)}
+		for _, corner := range cell {
+			position := proj.inverse(corner)
+			cellBox[0], cellBox[1] = math.Min(cellBox[0], position[0]), math.Min(cellBox[1], position[1])
+			cellBox[2], cellBox[3] = math.Max(cellBox[2], position[0]), math.Max(cellBox[3], position[1])
+		}
+		for j, boundary := range locator.boundaries {
+			countyBox := locator.boxes[j]
+			if countyBox[0] > cellBox[2] || countyBox[2] < cellBox[0] || countyBox[1] > cellBox[3] || countyBox[3] < cellBox[1] {
+				continue
+			}
+			part := models.CatchmentPart{County: boundary.County}
+			for _, polygon := range boundary.Polygons {
+				var clipped models.Polygon
+				area := 0.0
+				for k, ring := range polygon {
+					piece := clipToConvex(openRing(proj, ring), cell)
+					if piece == nil {
+						if k == 0 {
+							break
+						}
+						continue
+					}
+					if k == 0 {
+						area += ringArea(piece)
+					} else {
+						area -= ringArea(piece)
+					}
+					clipped = append(clipped, closedRing(proj, piece)...)
+				}
+				if area > 0 {
+					part.AreaSqMiles += area
+					part.Polygons = append(part.Polygons, clipped)
+				}
+			}
+			if part.AreaSqMiles > 0 {
+				result[i].Parts = append(result[i].Parts, part)
+			}
+		}
+		sort.Slice(result[i].Parts, func(a, b int) bool { return result[i].Parts[a].County < result[i].Parts[b].County })
+	}
+	return result
+}
+
+// openRing projects a closed GeoJSON ring, dropping the position that repeats the first
+func openRing(proj projection, ring [][2]float64) []planar {
+	open := make([]planar, 0, len(ring))
+	for _, position := range ring[:len(ring)-1] {
+		open = append(open, proj.forward(position[0], position[1]))
+	}
+	return open
+}
+
+// closedRing returns an open planar ring as a one-ring GeoJSON polygon in longitude and latitude
+func closedRing(proj projection, ring []planar) models.Polygon {
+	closed := make([][2]float64, 0, len(ring)+1)
+	for _, position := range ring {
+		closed = append(closed, proj.inverse(position))
+	}
+	return models.Polygon{append(closed, closed[0])}
+}
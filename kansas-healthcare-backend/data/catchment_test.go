@@ -0,0 +1,105 @@
+package data
+
+import (
+	"kansas-healthcare-api/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClipRing(t *testing.T) {
+	square := []planar{{0, 0}, {10, 0}, {10, 10}, {0, 10}}
+	west := func(p planar) float64 { return p.x - 4 }
+
+	assert.Equal(t, []planar{{0, 0}, {4, 0}, {4, 10}, {0, 10}}, clipRing(square, west))
+	assert.Equal(t, 40.0, ringArea(clipRing(square, west)))
+	assert.Empty(t, clipRing(square, func(p planar) float64 { return 1 - p.x/100 }))
+
+	// An L-shaped ring clipped to a triangle keeps only the area they share
+	ell := []planar{{0, 0}, {10, 0}, {10, 2}, {2, 2}, {2, 10}, {0, 10}}
+	triangle := []planar{{0, 0}, {10, 0}, {0, 10}}
+	assert.InDelta(t, 32.0, ringArea(clipToConvex(ell, triangle)), 1e-9)
+	assert.Nil(t, clipToConvex(ell, []planar{{20, 20}, {30, 20}, {30, 30}}))
+}
+
+// newCatchmentFixture has a clinic in Wichita shared by two providers and one in El Dorado, in
+// the Sedgwick and Butler rectangles of testBoundaries
+func newCatchmentFixture(boundaries []models.CountyBoundary) *JSONRepository {
+	wichita := fixtureLocation{city: "Wichita", latitude: 37.6872, longitude: -97.3301}
+	return newProviderFixture(&jsonSnapshot{countyBoundaries: boundaries},
+		fixtureProvider{id: "P1", specialty: "Primary Care", county: "Sedgwick", network: "Commercial", locations: []fixtureLocation{wichita}},
+		fixtureProvider{id: "P2", specialty: "Primary Care", county: "Sedgwick", network: "Commercial", locations: []fixtureLocation{wichita}},
+		fixtureProvider{id: "P3", specialty: "Primary Care", county: "Butler", network: "Commercial",
+			locations: []fixtureLocation{{city: "El Dorado", latitude: 37.8172, longitude: -96.8622}}},
+		// Never geocoded, so it has no catchment
+		fixtureProvider{id: "P4", specialty: "Primary Care", county: "Butler", network: "Commercial", locations: []fixtureLocation{{}}},
+	)
+}
+
+func TestCatchmentsTileTheCounties(t *testing.T) {
+	repo := newCatchmentFixture(testBoundaries())
+	asOf := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+
+	catchments, err := repo.GetCatchments("Commercial", "All", asOf)
+	require.NoError(t, err)
+	require.Len(t, catchments, 2)
+	wichita, elDorado := catchments[0], catchments[1]
+	assert.Equal(t, []string{"P1-1", "P2-1"}, wichita.LocationIDs)
+	assert.Equal(t, []string{"P1", "P2"}, wichita.ProviderIDs)
+	assert.Equal(t, "Wichita", wichita.City)
+	assert.Equal(t, []string{"P3-1"}, elDorado.LocationIDs)
+
+	// The line halfway between them slants across the county line, so each catchment takes a
+	// corner of the other county, and between them they cover both counties exactly
+	require.Len(t, wichita.Parts, 2)
+	assert.Equal(t, "Butler", wichita.Parts[0].County)
+	assert.Equal(t, "Sedgwick", wichita.Parts[1].County)
+	require.Len(t, elDorado.Parts, 2)
+	assert.Equal(t, "Sedgwick", elDorado.Parts[1].County)
+	assert.Less(t, elDorado.Parts[1].AreaSqMiles, 5.0)
+	minLat, _, maxLat, _ := stateBounds(newCountyLocator(testBoundaries()))
+	proj := newProjection(minLat, maxLat)
+	for _, boundary := range testBoundaries() {
+		covered := 0.0
+		for _, catchment := range catchments {
+			for _, part := range catchment.Parts {
+				if part.County == boundary.County {
+					covered += part.AreaSqMiles
+				}
+			}
+		}
+		assert.InDelta(t, ringArea(openRing(proj, boundary.Polygons[0][0])), covered, 1e-6, boundary.County)
+	}
+	ring := wichita.Parts[0].Polygons[0][0]
+	assert.Equal(t, ring[0], ring[len(ring)-1])
+
+	sqlRepo := newSQLiteTestRepository(t, repo)
+	fromSQL, err := sqlRepo.GetCatchments("Commercial", "All", asOf)
+	require.NoError(t, err)
+	assert.Equal(t, catchments, fromSQL)
+
+	none, err := repo.GetCatchments("Medicare", "All", asOf)
+	require.NoError(t, err)
+	assert.Empty(t, none)
+}
+
+func TestCatchmentsWithoutBoundariesCoverTheStateBox(t *testing.T) {
+	repo := newCatchmentFixture(nil)
+
+	catchments, err := repo.GetCatchments("Commercial", "All", time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Len(t, catchments, 2)
+	minLat, minLng, maxLat, maxLng := stateBounds(newCountyLocator(nil))
+	proj := newProjection(minLat, maxLat)
+	box := []planar{proj.forward(minLng, minLat), proj.forward(maxLng, minLat), proj.forward(maxLng, maxLat), proj.forward(minLng, maxLat)}
+
+	total := 0.0
+	for _, catchment := range catchments {
+		require.Len(t, catchment.Parts, 1)
+		assert.Empty(t, catchment.Parts[0].County)
+		total += catchment.Parts[0].AreaSqMiles
+	}
+	assert.InDelta(t, ringArea(box), total, 1e-6)
+}
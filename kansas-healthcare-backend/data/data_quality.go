@@ -0,0 +1,228 @@
+package data
+
+import (
+	"kansas-healthcare-api/config"
+	"kansas-healthcare-api/models"
+	"log"
+	"sort"
+	"time"
+)
+
+// dataQualitySampleLimit caps how many offending records each check reports
+const dataQualitySampleLimit = 10
+
+// unknownCountySample is reported for a county name that has no entry in county_areas.json
+type unknownCountySample struct {
+	County  string `json:"county"`
+	File    string `json:"file"`
+	Records int    `json:"records"`
+}
+
+// duplicateNPISample is reported for an NPI shared by more than one provider
+type duplicateNPISample struct {
+	NPI         string   `json:"npi"`
+	ProviderIDs []string `json:"provider_ids"`
+}
+
+// boundaryMismatchSample is reported for a service location whose coordinates lie outside the
+// boundary of the county it declares. LocatedIn is the county they do lie in, if any.
+type boundaryMismatchSample struct {
+	LocationID string  `json:"location_id"`
+	ProviderID string  `json:"provider_id"`
+	County     string  `json:"county"`
+	LocatedIn  string  `json:"located_in"`
+	Latitude   float64 `json:"latitude"`
+	Longitude  float64 `json:"longitude"`
+}
+
+// addressMismatchSample is reported for a service location whose city or county disagrees with
+// its ZIP code in zip_centroids.json
+type addressMismatchSample struct {
+	LocationID string `json:"location_id"`
+	ProviderID string `json:"provider_id"`
+	*AddressMismatch
+}
+
+// qualityCheck accumulates the offending records of one check
+type qualityCheck struct {
+	check models.DataQualityCheck
+}
+
+func newQualityCheck(name, file, description string) *qualityCheck {
+	return &qualityCheck{check: models.DataQualityCheck{
+		Name:        name,
+		Description: description,
+		File:        file,
+		Samples:     []interface{}{},
+	}}
+}
+
+func (c *qualityCheck) add(sample interface{}) {
+	c.check.Count++
+	if len(c.check.Samples) < dataQualitySampleLimit {
+		c.check.Samples = append(c.check.Samples, sample)
+	}
+}
+
+// outsideKansas reports whether a coordinate falls outside the state's bounding box
+func outsideKansas(lat, lng float64) bool {
+	return lat < config.KansasMinLatitude || lat > config.KansasMaxLatitude ||
+		lng < config.KansasMinLongitude || lng > config.KansasMaxLongitude
+}
+
+// checkDataQuality cross-checks the datasets against each other. None of these problems stop the
+// data from loading, but each one silently skews analytics: an orphaned service location, for
+// example, is never matched to its provider and distance metrics fall back to area estimates.
+func checkDataQuality(providers []models.Provider, networks []models.ProviderNetwork,
+	locations []models.ProviderServiceLocation, claims []models.CountyClaims, areas []models.CountyArea,
+	boundaries []models.CountyBoundary, geocoder *Geocoder) *models.DataQualityReport {
+
+	providerIds := make(map[string]bool, len(providers))
+	providerCounties := make(map[string]bool)
+	for _, provider := range providers {
+		providerIds[provider.ProviderID] = true
+		providerCounties[provider.County] = true
+	}
+
+	orphanNetworks := newQualityCheck("orphan_network_rows", providerNetworksFile,
+		"Network rows whose provider_id is not in providers.json")
+	for _, network := range networks {
+		if !providerIds[network.ProviderID] {
+			orphanNetworks.add(network)
+		}
+	}
+
+	orphanLocations := newQualityCheck("orphan_service_locations", providerServiceLocationsFile,
+		"Service locations whose provider_id is not in providers.json")
+	outOfState := newQualityCheck("locations_outside_kansas", providerServiceLocationsFile,
+		"Service locations whose latitude/longitude fall outside the Kansas bounding box")
+	for _, location := range locations {
+		if !providerIds[location.ProviderID] {
+			orphanLocations.add(location)
+		}
+		if outsideKansas(location.Latitude, location.Longitude) {
+			outOfState.add(location)
+		}
+	}
+
+	unknownCounties := newQualityCheck("unknown_counties", countyAreasFile,
+		"County names used by other datasets that have no entry in county_areas.json")
+	if len(areas) > 0 {
+		known := make(map[string]bool, len(areas))
+		for _, area := range areas {
+			known[area.County] = true
+		}
+		tally := func(file string, counties []string) {
+			counts := make(map[string]int)
+			for _, county := range counties {
+				if !known[county] {
+					counts[county]++
+				}
+			}
+			names := make([]string, 0, len(counts))
+			for county := range counts {
+				names = append(names, county)
+			}
+			sort.Strings(names)
+			for _, county := range names {
+				unknownCounties.add(unknownCountySample{County: county, File: file, Records: counts[county]})
+			}
+		}
+
+		counties := make([]string, len(providers))
+		for i, provider := range providers {
+			counties[i] = provider.County
+		}
+		tally(providersFile, counties)
+		counties = make([]string, len(locations))
+		for i, location := range locations {
+			counties[i] = location.County
+		}
+		tally(providerServiceLocationsFile, counties)
+		counties = make([]string, len(claims))
+		for i, claim := range claims {
+			counties[i] = claim.County
+		}
+		tally(claimsFile, counties)
+	}
+
+	boundaryMismatches := newQualityCheck("county_boundary_mismatches", providerServiceLocationsFile,
+		"Service locations whose latitude/longitude lie outside the boundary of their county in county_boundaries.json")
+	if len(boundaries) > 0 {
+		locator := newCountyLocator(boundaries)
+		for _, location := range locations {
+			// Locations without coordinates or outside the state fail other checks already
+			if !hasCoordinates(location) || outsideKansas(location.Latitude, location.Longitude) {
+				continue
+			}
+			point := models.Coordinate{Latitude: location.Latitude, Longitude: location.Longitude}
+			if inside, ok := locator.contains(location.County, point); ok && !inside {
+				boundaryMismatches.add(boundaryMismatchSample{
+					LocationID: location.LocationID,
+					ProviderID: location.ProviderID,
+					County:     location.County,
+					LocatedIn:  locator.locate(point),
+					Latitude:   location.Latitude,
+					Longitude:  location.Longitude,
+				})
+			}
+		}
+	}
+
+	addressMismatches := newQualityCheck("zip_code_mismatches", providerServiceLocationsFile,
+		"Service locations whose city or county disagrees with their ZIP code in zip_centroids.json")
+	approximate := newQualityCheck("approximate_coordinates", providerServiceLocationsFile,
+		"Service locations placed at the centroid of their ZIP code or city because they had no latitude/longitude")
+	for _, location := range locations {
+		if mismatch := geocoder.CheckAddress(location); mismatch != nil {
+			addressMismatches.add(addressMismatchSample{LocationID: location.LocationID, ProviderID: location.ProviderID, AddressMismatch: mismatch})
+		}
+		if location.CoordinateSource != "" {
+			approximate.add(location)
+		}
+	}
+
+	claimsWithoutProviders := newQualityCheck("claims_without_providers", claimsFile,
+		"Counties with claims but no providers in providers.json")
+	for _, claim := range claims {
+		if !providerCounties[claim.County] {
+			claimsWithoutProviders.add(claim)
+		}
+	}
+
+	duplicateNPIs := newQualityCheck("duplicate_npis", providersFile,
+		"NPIs shared by more than one provider")
+	byNPI := make(map[string][]string)
+	var npis []string
+	for _, provider := range providers {
+		if provider.NPI == "" {
+			continue
+		}
+		if _, seen := byNPI[provider.NPI]; !seen {
+			npis = append(npis, provider.NPI)
+		}
+		byNPI[provider.NPI] = append(byNPI[provider.NPI], provider.ProviderID)
+	}
+	for _, npi := range npis {
+		if ids := byNPI[npi]; len(ids) > 1 {
+			duplicateNPIs.add(duplicateNPISample{NPI: npi, ProviderIDs: ids})
+		}
+	}
+
+	report := &models.DataQualityReport{GeneratedAt: time.Now().UTC()}
+	for _, c := range []*qualityCheck{orphanNetworks, orphanLocations, unknownCounties, claimsWithoutProviders, duplicateNPIs, outOfState, boundaryMismatches,
+		addressMismatches, approximate} {
+		report.Checks = append(report.Checks, c.check)
+		report.TotalIssues += c.check.Count
+	}
+	return report
+}
+
+// logDataQuality summarizes failed checks when a dataset is loaded
+func logDataQuality(report *models.DataQualityReport) {
+	for _, check := range report.Checks {
+		if check.Count > 0 {
+			log.Printf("[WARN] Data quality: %d records fail %s in %s", check.Count, check.Name, check.File)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package data
+
+import (
+	"kansas-healthcare-api/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func findCheck(t *testing.T, report *models.DataQualityReport, name string) models.DataQualityCheck {
+	for _, check := range report.Checks {
+		if check.Name == name {
+			return check
+		}
+	}
+	t.Fatalf("check %s missing from report", name)
+	return models.DataQualityCheck{}
+}
+
+func TestCheckDataQuality(t *testing.T) {
+	providers := []models.Provider{
+		{ProviderID: "P0001", NPI: "1001", County: "Sedgwick"},
+		{ProviderID: "P0002", NPI: "1001", County: "Sedgwick"},
+		{ProviderID: "P0003", NPI: "1003", County: "Atlantis"},
+	}
+	networks := []models.ProviderNetwork{
+		{ProviderID: "P0001", NetworkID: "Commercial"},
+		{ProviderID: "P9999", NetworkID: "Commercial"},
+	}
+	locations := []models.ProviderServiceLocation{
+		{ProviderID: "P0001", County: "Sedgwick", Latitude: 37.69, Longitude: -97.34},
+		{ProviderID: "P001", County: "Sedgwick", Latitude: 37.69, Longitude: -97.34},
+		{ProviderID: "P0002", County: "Sedgwick", Latitude: 0, Longitude: 0},
+	}
+	claims := []models.CountyClaims{{County: "Sedgwick"}, {County: "Butler"}}
+	areas := []models.CountyArea{{County: "Sedgwick"}, {County: "Butler"}}
+
+	report := checkDataQuality(providers, networks, locations, claims, areas, nil, NewGeocoder(nil, nil))
+
+	orphanNetworks := findCheck(t, report, "orphan_network_rows")
+	assert.Equal(t, 1, orphanNetworks.Count)
+	assert.Equal(t, networks[1], orphanNetworks.Samples[0])
+
+	orphanLocations := findCheck(t, report, "orphan_service_locations")
+	assert.Equal(t, 1, orphanLocations.Count)
+	assert.Equal(t, locations[1], orphanLocations.Samples[0])
+
+	unknown := findCheck(t, report, "unknown_counties")
+	require.Equal(t, 1, unknown.Count)
+	assert.Equal(t, unknownCountySample{County: "Atlantis", File: providersFile, Records: 1}, unknown.Samples[0])
+
+	assert.Equal(t, 1, findCheck(t, report, "claims_without_providers").Count)
+
+	duplicates := findCheck(t, report, "duplicate_npis")
+	require.Equal(t, 1, duplicates.Count)
+	assert.Equal(t, duplicateNPISample{NPI: "1001", ProviderIDs: []string{"P0001", "P0002"}}, duplicates.Samples[0])
+
+	assert.Equal(t, 1, findCheck(t, report, "locations_outside_kansas").Count)
+	assert.Equal(t, 6, report.TotalIssues)
+}
+
+func TestDataQualitySamplesAreCapped(t *testing.T) {
+	var networks []models.ProviderNetwork
+	for i := 0; i < dataQualitySampleLimit+5; i++ {
+		networks = append(networks, models.ProviderNetwork{ProviderID: "missing", NetworkID: "Commercial"})
+	}
+
+	check := findCheck(t, checkDataQuality(nil, networks, nil, nil, nil, nil, NewGeocoder(nil, nil)), "orphan_network_rows")
+	assert.Equal(t, dataQualitySampleLimit+5, check.Count)
+	assert.Len(t, check.Samples, dataQualitySampleLimit)
+}
+
+func TestSQLDataQualityMatchesJSON(t *testing.T) {
+	jsonRepo := newFixtureJSONRepository()
+	sqlRepo := newSQLiteTestRepository(t, jsonRepo)
+
+	expected, err := jsonRepo.GetDataQualityReport()
+	require.NoError(t, err)
+	actual, err := sqlRepo.GetDataQualityReport()
+	require.NoError(t, err)
+
+	for _, check := range expected.Checks {
+		assert.Equal(t, check.Count, findCheck(t, actual, check.Name).Count, check.Name)
+	}
+}
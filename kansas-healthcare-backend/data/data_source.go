@@ -0,0 +1,75 @@
+package data
+
+import (
+	"embed"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// defaultDataDir is the directory the JSON datasets are read from when none is configured,
+// relative to the working directory
+const defaultDataDir = "data"
+
+// embeddedPrefix marks paths served from the dataset compiled into the binary
+const embeddedPrefix = "embedded:"
+
+// embeddedData is the sample dataset shipped with the repository, compiled in so the binary can
+// serve the demo from any working directory
+//
+//go:embed *.json
+var embeddedData embed.FS
+
+// LoadOptions controls where the JSON repository reads its datasets from and how strictly
+type LoadOptions struct {
+	// DataDir holds the dataset files. When empty, ./data is used and any file missing there is
+	// read from the embedded sample dataset instead.
+	DataDir string
+
+	// Files overrides the location of individual datasets, keyed by file name (e.g.
+	// "providers.json"). Overridden files are always read from disk.
+	Files map[string]string
+
+	// AllowDegraded starts without optional datasets (county areas, specialty density and adequacy
+	// standards, county designations, boundaries and population, ZIP code and city centroids,
+	// out-of-state locations) when they are missing or malformed instead of failing the load
+	AllowDegraded bool
+}
+
+// dir is the directory datasets without an override are read from
+func (o LoadOptions) dir() string {
+	if o.DataDir == "" {
+		return defaultDataDir
+	}
+	return o.DataDir
+}
+
+// path is where the named dataset is expected on disk
+func (o LoadOptions) path(name string) string {
+	if override := o.Files[name]; override != "" {
+		return override
+	}
+	return filepath.Join(o.dir(), name)
+}
+
+// usesEmbeddedFallback reports whether a missing file may be served from the embedded dataset.
+// Explicitly configured locations never fall back, so a typo cannot silently serve sample data.
+func (o LoadOptions) usesEmbeddedFallback(name string) bool {
+	return o.DataDir == "" && o.Files[name] == ""
+}
+
+// readFile returns the contents of the named dataset and the path it was read from
+func (o LoadOptions) readFile(name string) (string, []byte, error) {
+	path := o.path(name)
+	body, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) && o.usesEmbeddedFallback(name) {
+		if embedded, embedErr := embeddedData.ReadFile(name); embedErr == nil {
+			return embeddedPrefix + name, embedded, nil
+		}
+	}
+	if err != nil {
+		return path, nil, fileError(path, err)
+	}
+	return path, body, nil
+}
@@ -0,0 +1,56 @@
+package data
+
+import (
+	"encoding/json"
+	"io/fs"
+	"kansas-healthcare-api/models"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbeddedDatasetServesWhenDataDirIsAbsent(t *testing.T) {
+	// Tests run from the package directory, which has no data/ subdirectory
+	_, err := os.Stat(defaultDataDir)
+	require.ErrorIs(t, err, fs.ErrNotExist)
+
+	repo, err := NewJSONRepository(LoadOptions{})
+	require.NoError(t, err)
+
+	providers, err := repo.GetProviders()
+	require.NoError(t, err)
+	assert.NotEmpty(t, providers)
+	assert.Empty(t, repo.statDataFiles(), "embedded files are never polled")
+}
+
+func TestConfiguredDataDirDoesNotFallBackToEmbeddedDataset(t *testing.T) {
+	_, err := NewJSONRepository(LoadOptions{DataDir: t.TempDir()})
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func TestFileOverrideReplacesSingleDataset(t *testing.T) {
+	dir := t.TempDir()
+	writeTestDataset(t, dir, []models.Provider{{ProviderID: "P1", Status: "Active", County: "Sedgwick"}})
+
+	override := filepath.Join(t.TempDir(), "roster.json")
+	body, err := json.Marshal([]models.Provider{
+		{ProviderID: "P1", Status: "Active", County: "Sedgwick"},
+		{ProviderID: "P2", Status: "Active", County: "Butler"},
+	})
+	require.NoError(t, err)
+	writeTestFile(t, override, body)
+
+	repo, err := NewJSONRepository(LoadOptions{DataDir: dir, Files: map[string]string{providersFile: override}})
+	require.NoError(t, err)
+	count, _ := repo.GetActiveProviderCount()
+	assert.Equal(t, 2, count)
+
+	writeTestFile(t, override, []byte(`[]`))
+	_, err = repo.reloadIfChanged()
+	assert.ErrorContains(t, err, override)
+	assert.True(t, strings.HasSuffix(repo.ReloadStatus().LastError, "contains no providers"))
+}
@@ -0,0 +1,94 @@
+package data
+
+import (
+	"fmt"
+	"kansas-healthcare-api/models"
+	"math"
+)
+
+const (
+	// defaultCountyAreaSqMiles is used when a county has no entry in the area dataset
+	defaultCountyAreaSqMiles = 700.0
+
+	earthRadiusMiles = 3959.0
+
+	// milesPerDegreeLatitude is the great-circle length of one degree along a meridian
+	milesPerDegreeLatitude = earthRadiusMiles * math.Pi / 180
+)
+
+// providerDensity buckets an active provider count into a density label
+func providerDensity(providerCount int) string {
+	if providerCount >= 200 {
+		return "high"
+	} else if providerCount >= 50 {
+		return "medium"
+	} else if providerCount >= 30 {
+		return "low"
+	}
+	return "critical"
+}
+
+// haversineDistance calculates the distance between two points on Earth using the Haversine formula
+func haversineDistance(lat1, lng1, lat2, lng2 float64) float64 {
+	// Convert degrees to radians
+	lat1Rad := lat1 * math.Pi / 180
+	lng1Rad := lng1 * math.Pi / 180
+	lat2Rad := lat2 * math.Pi / 180
+	lng2Rad := lng2 * math.Pi / 180
+
+	// Haversine formula
+	dlat := lat2Rad - lat1Rad
+	dlng := lng2Rad - lng1Rad
+	a := math.Sin(dlat/2)*math.Sin(dlat/2) + math.Cos(lat1Rad)*math.Cos(lat2Rad)*math.Sin(dlng/2)*math.Sin(dlng/2)
+	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+	return earthRadiusMiles * c
+}
+
+// DistanceMiles returns the great-circle distance in miles between two coordinates
+func DistanceMiles(a, b models.Coordinate) float64 {
+	return haversineDistance(a.Latitude, a.Longitude, b.Latitude, b.Longitude)
+}
+
+// averageNearestProviderDistance returns the mean distance from each location to its nearest neighbour,
+// found through a spatial index instead of comparing every pair
+func averageNearestProviderDistance(providerLocations []models.ProviderServiceLocation) float64 {
+	if len(providerLocations) < 2 {
+		return 0
+	}
+
+	coordinates := make([]models.Coordinate, len(providerLocations))
+	for i, location := range providerLocations {
+		coordinates[i] = models.Coordinate{Latitude: location.Latitude, Longitude: location.Longitude}
+	}
+	index := newSpatialIndex(coordinates)
+
+	// Calculate distance to nearest provider for each provider
+	var totalDistance float64
+	for i, coordinate := range coordinates {
+		_, distance, _ := index.nearest(coordinate, i)
+		totalDistance += distance
+	}
+	return totalDistance / float64(len(providerLocations))
+}
+
+// providerDensityMiles formats provider spacing, preferring measured distances over area math
+func providerDensityMiles(providerCount int, actualDistance, countyAreaSqMiles float64) string {
+	if providerCount == 0 {
+		return "No providers"
+	}
+
+	// Try to use actual distances first
+	if actualDistance > 0 {
+		return fmt.Sprintf("~%.1f mi apart", actualDistance)
+	}
+
+	// Fallback to area-based calculation
+	providersPerSqMile := float64(providerCount) / countyAreaSqMiles
+
+	if providersPerSqMile >= 1 {
+		return fmt.Sprintf("%.1f/sq mi", providersPerSqMile)
+	} else {
+		avgDistanceMiles := math.Sqrt(countyAreaSqMiles / float64(providerCount))
+		return fmt.Sprintf("~%.1f mi apart", avgDistanceMiles)
+	}
+}
@@ -0,0 +1,49 @@
+package data
+
+import (
+	"fmt"
+	"kansas-healthcare-api/models"
+	"time"
+)
+
+// fixtureJoined and fixtureOpen bound the affiliations and service locations of fixture providers,
+// which are in force from before any as-of date the tests use until the open-ended termination date
+var (
+	fixtureJoined = time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+	fixtureOpen   = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+)
+
+// fixtureProvider is an active provider affiliated with network, practising at locations in its
+// own county
+type fixtureProvider struct {
+	id, npi, specialty, county, network string
+	locations                           []fixtureLocation
+}
+
+// fixtureLocation is a service location; one without coordinates was never geocoded
+type fixtureLocation struct {
+	city                string
+	latitude, longitude float64
+}
+
+// newProviderFixture adds the providers to snapshot, which holds whatever other datasets a test
+// needs, with their affiliations and their locations numbered P1-1, P1-2 and so on, all in force
+// from fixtureJoined
+func newProviderFixture(snapshot *jsonSnapshot, providers ...fixtureProvider) *JSONRepository {
+	for _, p := range providers {
+		snapshot.providers = append(snapshot.providers, models.Provider{
+			ProviderID: p.id, NPI: p.npi, ProviderType: p.specialty, Status: "Active", County: p.county,
+		})
+		snapshot.providerNetwork = append(snapshot.providerNetwork, models.ProviderNetwork{
+			ProviderID: p.id, NetworkID: p.network, EffectiveDate: fixtureJoined, TerminationDate: fixtureOpen,
+		})
+		for i, l := range p.locations {
+			snapshot.providerServiceLocations = append(snapshot.providerServiceLocations, models.ProviderServiceLocation{
+				LocationID: fmt.Sprintf("%s-%d", p.id, i+1), ProviderID: p.id,
+				EffectiveDate: fixtureJoined, TerminationDate: fixtureOpen,
+				City: l.city, County: p.county, Latitude: l.latitude, Longitude: l.longitude,
+			})
+		}
+	}
+	return newJSONRepositoryFrom(snapshot)
+}
@@ -0,0 +1,115 @@
+package data
+
+import (
+	"kansas-healthcare-api/config"
+	"kansas-healthcare-api/models"
+	"regexp"
+	"strings"
+)
+
+var zipCodePattern = regexp.MustCompile(`^[0-9]{5}$`)
+
+// ValidZipCode reports whether zip is a five-digit ZIP code
+func ValidZipCode(zip string) bool {
+	return zipCodePattern.MatchString(zip)
+}
+
+// Geocoder places service locations without coordinates at the centroid of their ZIP code, or
+// failing that of their city, from offline tables. It also checks that the city and county of a
+// location agree with its ZIP code.
+type Geocoder struct {
+	zips   map[string]models.ZipCentroid
+	cities map[string][]models.CityCentroid // keyed by lower-case city name
+}
+
+// AddressMismatch is a city or county that disagrees with the ZIP code table. Expected is what
+// the table has for the ZIP code and Actual what the location declares.
+type AddressMismatch struct {
+	Field    string `json:"field"`
+	ZipCode  string `json:"zip_code"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+}
+
+func NewGeocoder(zips []models.ZipCentroid, cities []models.CityCentroid) *Geocoder {
+	g := &Geocoder{
+		zips:   make(map[string]models.ZipCentroid, len(zips)),
+		cities: make(map[string][]models.CityCentroid),
+	}
+	for _, zip := range zips {
+		g.zips[zip.ZipCode] = zip
+	}
+	for _, city := range cities {
+		key := cityKey(city.City)
+		g.cities[key] = append(g.cities[key], city)
+	}
+	return g
+}
+
+func cityKey(city string) string {
+	return strings.ToLower(strings.TrimSpace(city))
+}
+
+// Geocode fills in the coordinates of a location that has none and records which table they
+// came from in CoordinateSource. It reports whether it did; locations with coordinates, and
+// those whose ZIP code and city are both unknown, are left alone.
+func (g *Geocoder) Geocode(location *models.ProviderServiceLocation) bool {
+	if hasCoordinates(*location) {
+		return false
+	}
+	if zip, ok := g.zips[location.ZipCode]; ok {
+		location.Latitude, location.Longitude = zip.Latitude, zip.Longitude
+		location.CoordinateSource = config.CoordinateSourceZip
+		return true
+	}
+	if city, ok := g.city(location.City, location.County); ok {
+		location.Latitude, location.Longitude = city.Latitude, city.Longitude
+		location.CoordinateSource = config.CoordinateSourceCity
+		return true
+	}
+	return false
+}
+
+// city finds the named city in the county. Without a county the name must be unambiguous.
+func (g *Geocoder) city(name, county string) (models.CityCentroid, bool) {
+	matches := g.cities[cityKey(name)]
+	if county == "" {
+		if len(matches) == 1 {
+			return matches[0], true
+		}
+		return models.CityCentroid{}, false
+	}
+	for _, city := range matches {
+		if strings.EqualFold(city.County, strings.TrimSpace(county)) {
+			return city, true
+		}
+	}
+	return models.CityCentroid{}, false
+}
+
+// CheckAddress returns how a location's county or city, in that order, disagrees with its ZIP
+// code, ignoring case. It returns nil when they agree, when either is empty or when the ZIP code
+// is not in the table.
+func (g *Geocoder) CheckAddress(location models.ProviderServiceLocation) *AddressMismatch {
+	zip, ok := g.zips[location.ZipCode]
+	if !ok {
+		return nil
+	}
+	for _, field := range []struct{ name, expected, actual string }{
+		{"county", zip.County, location.County},
+		{"city", zip.City, location.City},
+	} {
+		actual := strings.TrimSpace(field.actual)
+		if actual != "" && !strings.EqualFold(actual, field.expected) {
+			return &AddressMismatch{Field: field.name, ZipCode: zip.ZipCode, Expected: field.expected, Actual: field.actual}
+		}
+	}
+	return nil
+}
+
+// geocodeLocations fills in the coordinates of every location that has none
+func geocodeLocations(geocoder *Geocoder, locations []models.ProviderServiceLocation) {
+	for i := range locations {
+		geocoder.Geocode(&locations[i])
+	}
+}
@@ -0,0 +1,177 @@
+package data
+
+import (
+	"encoding/json"
+	"errors"
+	"kansas-healthcare-api/config"
+	"kansas-healthcare-api/models"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testZipCentroids() []models.ZipCentroid {
+	return []models.ZipCentroid{
+		{ZipCode: "67042", City: "El Dorado", County: "Butler", Latitude: 37.8172, Longitude: -96.8622},
+		{ZipCode: "67202", City: "Wichita", County: "Sedgwick", Latitude: 37.6872, Longitude: -97.3301},
+	}
+}
+
+func testCityCentroids() []models.CityCentroid {
+	return []models.CityCentroid{
+		{City: "Augusta", County: "Butler", Latitude: 37.6867, Longitude: -96.9767},
+		{City: "Wichita", County: "Sedgwick", Latitude: 37.69, Longitude: -97.33},
+	}
+}
+
+func TestGeocode(t *testing.T) {
+	geocoder := NewGeocoder(testZipCentroids(), testCityCentroids())
+
+	byZip := models.ProviderServiceLocation{ZipCode: "67042", City: "El Dorado", County: "Butler"}
+	assert.True(t, geocoder.Geocode(&byZip))
+	assert.Equal(t, 37.8172, byZip.Latitude)
+	assert.Equal(t, -96.8622, byZip.Longitude)
+	assert.Equal(t, config.CoordinateSourceZip, byZip.CoordinateSource)
+
+	// The ZIP code is not in the table, so the city stands in for it
+	byCity := models.ProviderServiceLocation{ZipCode: "67010", City: " augusta", County: "Butler"}
+	assert.True(t, geocoder.Geocode(&byCity))
+	assert.Equal(t, 37.6867, byCity.Latitude)
+	assert.Equal(t, config.CoordinateSourceCity, byCity.CoordinateSource)
+
+	for _, location := range []models.ProviderServiceLocation{
+		{ZipCode: "67010", City: "Augusta", County: "Sedgwick"},
+		{ZipCode: "66002", City: "Atchison", County: "Atchison"},
+		{ZipCode: "67042", County: "Butler", Latitude: 37.82, Longitude: -96.86},
+	} {
+		before := location
+		assert.False(t, geocoder.Geocode(&location), "%+v", before)
+		assert.Equal(t, before, location)
+	}
+}
+
+func TestCheckAddress(t *testing.T) {
+	geocoder := NewGeocoder(testZipCentroids(), nil)
+
+	assert.Nil(t, geocoder.CheckAddress(models.ProviderServiceLocation{ZipCode: "67202", City: "WICHITA", County: "sedgwick"}))
+	assert.Nil(t, geocoder.CheckAddress(models.ProviderServiceLocation{ZipCode: "67202", County: "Sedgwick"}))
+	assert.Nil(t, geocoder.CheckAddress(models.ProviderServiceLocation{ZipCode: "66002", City: "Wichita", County: "Sedgwick"}))
+
+	assert.Equal(t, &AddressMismatch{Field: "county", ZipCode: "67202", Expected: "Sedgwick", Actual: "Butler"},
+		geocoder.CheckAddress(models.ProviderServiceLocation{ZipCode: "67202", City: "El Dorado", County: "Butler"}))
+	assert.Equal(t, &AddressMismatch{Field: "city", ZipCode: "67042", Expected: "El Dorado", Actual: "Augusta"},
+		geocoder.CheckAddress(models.ProviderServiceLocation{ZipCode: "67042", City: "Augusta", County: "Butler"}))
+}
+
+func TestLoadGeocodesLocationsWithoutCoordinates(t *testing.T) {
+	joined := time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+	dir := t.TempDir()
+	writeTestDataset(t, dir, []models.Provider{{ProviderID: "P1", Status: "Active", County: "Butler"}})
+	for name, content := range map[string]interface{}{
+		zipCentroidsFile:  testZipCentroids(),
+		cityCentroidsFile: testCityCentroids(),
+		providerServiceLocationsFile: []models.ProviderServiceLocation{
+			{ProviderID: "P1", EffectiveDate: joined, ZipCode: "67042", City: "El Dorado", County: "Butler"},
+			{ProviderID: "P1", EffectiveDate: joined, ZipCode: "67010", City: "Augusta", County: "Butler", Latitude: 37.69, Longitude: -96.98},
+			{ProviderID: "P1", EffectiveDate: joined, ZipCode: "67202", City: "Wichita", County: "Butler", Latitude: 37.69, Longitude: -97.33},
+		},
+	} {
+		body, err := json.Marshal(content)
+		require.NoError(t, err)
+		writeTestFile(t, filepath.Join(dir, name), body)
+	}
+
+	repo, err := NewJSONRepository(LoadOptions{DataDir: dir})
+	require.NoError(t, err)
+	locations, err := repo.GetProviderServiceLocations()
+	require.NoError(t, err)
+	assert.Equal(t, 37.8172, locations[0].Latitude)
+	assert.Equal(t, config.CoordinateSourceZip, locations[0].CoordinateSource)
+	assert.Empty(t, locations[1].CoordinateSource)
+
+	report, err := repo.GetDataQualityReport()
+	require.NoError(t, err)
+	assert.Equal(t, 1, findCheck(t, report, "approximate_coordinates").Count)
+	check := findCheck(t, report, "zip_code_mismatches")
+	require.Equal(t, 1, check.Count)
+	assert.Equal(t, addressMismatchSample{
+		LocationID:      "P1-3",
+		ProviderID:      "P1",
+		AddressMismatch: &AddressMismatch{Field: "county", ZipCode: "67202", Expected: "Sedgwick", Actual: "Butler"},
+	}, check.Samples[0])
+
+	sqlRepo := newSQLiteTestRepository(t, repo)
+	stored, err := sqlRepo.GetServiceLocation(locations[0].LocationID)
+	require.NoError(t, err)
+	assert.Equal(t, locations[0].Latitude, stored.Latitude)
+	assert.Equal(t, config.CoordinateSourceZip, stored.CoordinateSource)
+	zips, err := sqlRepo.GetZipCentroids()
+	require.NoError(t, err)
+	assert.Equal(t, testZipCentroids(), zips)
+	geocoder, err := sqlRepo.GetGeocoder()
+	require.NoError(t, err)
+	assert.Equal(t, repo.snapshot().idx.geocoder, geocoder)
+	sqlReport, err := sqlRepo.GetDataQualityReport()
+	require.NoError(t, err)
+	assert.Equal(t, 1, findCheck(t, sqlReport, "zip_code_mismatches").Count)
+}
+
+func TestLoadRejectsInvalidCentroids(t *testing.T) {
+	for _, test := range []struct {
+		file  string
+		body  string
+		field string
+	}{
+		{zipCentroidsFile, `[{"zip_code": "6720", "city": "Wichita", "county": "Sedgwick", "latitude": 37.69, "longitude": -97.33}]`, "zip_code"},
+		{zipCentroidsFile, `[
+  {"zip_code": "67202", "city": "Wichita", "county": "Sedgwick", "latitude": 37.69, "longitude": -97.33},
+  {"zip_code": "67202", "city": "Wichita", "county": "Sedgwick", "latitude": 37.69, "longitude": -97.33}
+]`, "zip_code"},
+		{zipCentroidsFile, `[{"zip_code": "67202", "city": "Wichita", "latitude": 37.69, "longitude": -97.33}]`, "county"},
+		{zipCentroidsFile, `[{"zip_code": "67202", "city": "Wichita", "county": "Sedgwick", "latitude": 0, "longitude": 0}]`, "latitude"},
+		{cityCentroidsFile, `[{"county": "Sedgwick", "latitude": 37.69, "longitude": -97.33}]`, "city"},
+		{cityCentroidsFile, `[
+  {"city": "Wichita", "county": "Sedgwick", "latitude": 37.69, "longitude": -97.33},
+  {"city": "WICHITA", "county": "Sedgwick", "latitude": 37.69, "longitude": -97.33}
+]`, "city"},
+	} {
+		dir := t.TempDir()
+		writeTestDataset(t, dir, []models.Provider{{ProviderID: "P1", Status: "Active", County: "Sedgwick"}})
+		writeTestFile(t, filepath.Join(dir, test.file), []byte(test.body))
+
+		_, err := NewJSONRepository(LoadOptions{DataDir: dir})
+		var loadErr *LoadError
+		require.True(t, errors.As(err, &loadErr), test.body)
+		assert.Equal(t, test.field, loadErr.Field, test.body)
+	}
+}
+
+func TestEmbeddedZipCentroidsCoverEveryKansasCounty(t *testing.T) {
+	repo, err := NewJSONRepository(LoadOptions{})
+	require.NoError(t, err)
+
+	zips, err := repo.GetZipCentroids()
+	require.NoError(t, err)
+	counties := make(map[string]bool)
+	for _, zip := range zips {
+		counties[zip.County] = true
+	}
+	assert.Len(t, counties, 105)
+
+	// Every sample service location agrees with its ZIP code and lies in the county it names
+	report, err := repo.GetDataQualityReport()
+	require.NoError(t, err)
+	assert.Zero(t, findCheck(t, report, "zip_code_mismatches").Count)
+	locator := repo.snapshot().idx.countyLocator
+	for _, zip := range zips {
+		inside, ok := locator.contains(zip.County, models.Coordinate{Latitude: zip.Latitude, Longitude: zip.Longitude})
+		assert.True(t, ok && inside, "%s in %s", zip.ZipCode, zip.County)
+	}
+	for _, city := range repo.snapshot().cityCentroids {
+		inside, ok := locator.contains(city.County, models.Coordinate{Latitude: city.Latitude, Longitude: city.Longitude})
+		assert.True(t, ok && inside, "%s in %s", city.City, city.County)
+	}
+}
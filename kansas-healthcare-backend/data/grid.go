@@ -0,0 +1,39 @@
+package data
+
+import (
+	"kansas-healthcare-api/models"
+	"math"
+)
+
+// coverageGrid lays square cells of cellMiles a side over the state and keeps those whose centre
+// lies in a county boundary. Without boundaries it keeps every cell in the state's bounding box,
+// with no county. Cells share one longitude step, taken at the state's middle latitude, so they
+// are square to within a few percent across Kansas.
+func coverageGrid(locator *countyLocator, cellMiles float64) []models.GridCell {
+	minLat, minLng, maxLat, maxLng := stateBounds(locator)
+	latStep := cellMiles / milesPerDegreeLatitude
+	lngStep := latStep / math.Cos((minLat+maxLat)/2*math.Pi/180)
+	rows := int(math.Ceil((maxLat - minLat) / latStep))
+	cols := int(math.Ceil((maxLng - minLng) / lngStep))
+
+	cells := []models.GridCell{}
+	for row := 0; row < rows; row++ {
+		south, north := minLat+float64(row)*latStep, minLat+float64(row+1)*latStep
+		for col := 0; col < cols; col++ {
+			west, east := minLng+float64(col)*lngStep, minLng+float64(col+1)*lngStep
+			center := models.Coordinate{Latitude: (south + north) / 2, Longitude: (west + east) / 2}
+			county := ""
+			if len(locator.boundaries) > 0 {
+				if county = locator.locate(center); county == "" {
+					continue
+				}
+			}
+			cells = append(cells, models.GridCell{
+				County:  county,
+				Center:  center,
+				Polygon: models.Polygon{{{west, south}, {east, south}, {east, north}, {west, north}, {west, south}}},
+			})
+		}
+	}
+	return cells
+}
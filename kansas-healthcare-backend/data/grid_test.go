@@ -0,0 +1,63 @@
+package data
+
+import (
+	"kansas-healthcare-api/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoverageGridFollowsCountyBoundaries(t *testing.T) {
+	locator := newCountyLocator(testBoundaries())
+	cells := coverageGrid(locator, 10)
+	require.NotEmpty(t, cells)
+
+	counties := make(map[string]int)
+	for _, cell := range cells {
+		counties[cell.County]++
+		inside, ok := locator.contains(cell.County, cell.Center)
+		assert.True(t, ok && inside, "%v lies in %s", cell.Center, cell.County)
+
+		// Each cell is a closed square ten miles a side around its centre
+		ring := cell.Polygon[0]
+		require.Len(t, ring, 5)
+		assert.Equal(t, ring[0], ring[4])
+		assert.InDelta(t, 10, haversineDistance(ring[0][1], ring[0][0], ring[1][1], ring[1][0]), 0.1)
+		assert.InDelta(t, 10, haversineDistance(ring[1][1], ring[1][0], ring[2][1], ring[2][0]), 0.1)
+	}
+	assert.Len(t, counties, 2)
+	// Butler is the larger box, so it holds more cells
+	assert.Greater(t, counties["Butler"], counties["Sedgwick"])
+
+	// Halving the cell side roughly quadruples the cell count
+	assert.InDelta(t, 4*len(cells), len(coverageGrid(locator, 5)), float64(len(cells)))
+}
+
+func TestCoverageGridWithoutBoundariesCoversTheState(t *testing.T) {
+	cells := coverageGrid(newCountyLocator(nil), 25)
+	require.NotEmpty(t, cells)
+	for _, cell := range cells {
+		assert.Empty(t, cell.County)
+	}
+	// Kansas is roughly 410 by 210 miles
+	assert.InDelta(t, 17*9, len(cells), 20)
+}
+
+func TestSQLCoverageGridMatchesJSON(t *testing.T) {
+	joined := time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+	open := time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+	jsonRepo := newJSONRepositoryFrom(&jsonSnapshot{
+		providers:        []models.Provider{{ProviderID: "P1", ProviderType: "Primary Care", Status: "Active", County: "Sedgwick"}},
+		providerNetwork:  []models.ProviderNetwork{{ProviderID: "P1", NetworkID: "Commercial", EffectiveDate: joined, TerminationDate: open}},
+		countyBoundaries: testBoundaries(),
+	})
+	sqlRepo := newSQLiteTestRepository(t, jsonRepo)
+
+	expected, err := jsonRepo.GetCoverageGrid(10)
+	require.NoError(t, err)
+	actual, err := sqlRepo.GetCoverageGrid(10)
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
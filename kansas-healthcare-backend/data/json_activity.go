@@ -0,0 +1,115 @@
+package data
+
+import (
+	"kansas-healthcare-api/models"
+	"time"
+)
+
+// maxCachedActivityViews bounds how many as-of dates a snapshot keeps derived views for. Nearly all
+// requests ask for today, so the cache only needs room for a handful of historical reports.
+const maxCachedActivityViews = 16
+
+// activeAt reports whether a row with the given effective and termination dates is in force at asOf
+func activeAt(effective, termination, asOf time.Time) bool {
+	return !effective.After(asOf) && termination.After(asOf)
+}
+
+// jsonActivity holds the per-county aggregates that depend on which network affiliations and
+// service locations were in force at a particular date
+type jsonActivity struct {
+	// activeNetworkMembers holds, per network, providers with an affiliation in force
+	activeNetworkMembers map[string]map[string]bool
+	// activeLocationProviders holds, per county, active providers with a service location in force there
+	activeLocationProviders map[string][]string
+	// activeProviders holds active providers with a service location in force anywhere
+	activeProviders []string
+
+	nearestDistanceByCounty map[string]float64
+	countyStats             map[string]models.CountyStats
+}
+
+// activity returns the activity view of the snapshot at asOf, building and caching it on first use
+func (s *jsonSnapshot) activity(asOf time.Time) *jsonActivity {
+	idx := s.idx
+	key := asOf.UnixNano()
+
+	idx.activityMu.Lock()
+	defer idx.activityMu.Unlock()
+	if view, ok := idx.activityByDate[key]; ok {
+		return view
+	}
+	if len(idx.activityByDate) >= maxCachedActivityViews {
+		idx.activityByDate = make(map[int64]*jsonActivity)
+	}
+	view := buildJSONActivity(s, asOf)
+	idx.activityByDate[key] = view
+	return view
+}
+
+func buildJSONActivity(r *jsonSnapshot, asOf time.Time) *jsonActivity {
+	idx := r.idx
+	view := &jsonActivity{
+		activeNetworkMembers:    make(map[string]map[string]bool),
+		activeLocationProviders: make(map[string][]string),
+		nearestDistanceByCounty: make(map[string]float64),
+		countyStats:             make(map[string]models.CountyStats, len(r.countyClaims)),
+	}
+
+	for _, network := range r.providerNetwork {
+		if !activeAt(network.EffectiveDate, network.TerminationDate, asOf) {
+			continue
+		}
+		members := view.activeNetworkMembers[network.NetworkID]
+		if members == nil {
+			members = make(map[string]bool)
+			view.activeNetworkMembers[network.NetworkID] = members
+		}
+		members[network.ProviderID] = true
+	}
+
+	// Active providers with a service location in force, grouped by the location's county, plus the
+	// first such location in the provider's own county for nearest-neighbour spacing
+	nearestCandidates := make(map[string][]models.ProviderServiceLocation)
+	for _, provider := range r.providers {
+		if provider.Status != "Active" {
+			continue
+		}
+		seenCounties := make(map[string]bool)
+		homeLocationFound := false
+		for _, li := range idx.locationsByProvider[provider.ProviderID] {
+			location := r.providerServiceLocations[li]
+			if !activeAt(location.EffectiveDate, location.TerminationDate, asOf) {
+				continue
+			}
+			if !seenCounties[location.County] {
+				seenCounties[location.County] = true
+				view.activeLocationProviders[location.County] = append(view.activeLocationProviders[location.County], provider.ProviderID)
+			}
+			if !homeLocationFound && location.County == provider.County {
+				homeLocationFound = true
+				nearestCandidates[provider.County] = append(nearestCandidates[provider.County], location)
+			}
+		}
+		if len(seenCounties) > 0 {
+			view.activeProviders = append(view.activeProviders, provider.ProviderID)
+		}
+	}
+
+	for county, locations := range nearestCandidates {
+		view.nearestDistanceByCounty[county] = averageNearestProviderDistance(locations)
+	}
+
+	// Per-county aggregates served directly by GetCountyStats and GetCountyStatsByName
+	for _, claims := range r.countyClaims {
+		providerCount := len(view.activeLocationProviders[claims.County])
+		view.countyStats[claims.County] = models.CountyStats{
+			County:         claims.County,
+			ProviderCount:  providerCount,
+			ClaimsCount:    claims.ClaimsCount,
+			AvgClaimAmount: claims.AvgClaimAmount,
+			Density:        providerDensity(providerCount),
+			DensityMiles:   providerDensityMiles(providerCount, view.nearestDistanceByCounty[claims.County], idx.countyArea(claims.County)),
+		}
+	}
+	return view
+}
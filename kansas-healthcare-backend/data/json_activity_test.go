@@ -0,0 +1,87 @@
+package data
+
+import (
+	"kansas-healthcare-api/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestActiveAt(t *testing.T) {
+	effective := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	termination := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.False(t, activeAt(effective, termination, effective.AddDate(0, 0, -1)), "not yet effective")
+	assert.True(t, activeAt(effective, termination, effective), "effective on its first day")
+	assert.True(t, activeAt(effective, termination, termination.AddDate(0, 0, -1)))
+	assert.False(t, activeAt(effective, termination, termination), "no longer in force on the termination date")
+}
+
+func TestAnalyticsAreJudgedAsOfDate(t *testing.T) {
+	joined := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	left := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	open := time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	repo := newJSONRepositoryFrom(&jsonSnapshot{
+		providers: []models.Provider{
+			{ProviderID: "P1", ProviderType: "Primary Care", Status: "Active", County: "Sedgwick"},
+			{ProviderID: "P2", ProviderType: "Cardiology", Status: "Active", County: "Sedgwick"},
+		},
+		providerNetwork: []models.ProviderNetwork{
+			{ProviderID: "P1", NetworkID: "Commercial", EffectiveDate: joined, TerminationDate: open},
+			// Left after the dates below, so it still counts as a member then despite its reason
+			{ProviderID: "P2", NetworkID: "Commercial", EffectiveDate: joined, TerminationDate: left, TerminationReason: "Left Network"},
+		},
+		providerServiceLocations: []models.ProviderServiceLocation{
+			{ProviderID: "P1", EffectiveDate: joined, TerminationDate: open, County: "Sedgwick", Latitude: 37.69, Longitude: -97.33},
+			{ProviderID: "P2", EffectiveDate: joined, TerminationDate: left, County: "Sedgwick", Latitude: 37.54, Longitude: -97.27},
+		},
+		countyClaims: []models.CountyClaims{{County: "Sedgwick", ClaimsCount: 100}},
+	})
+	filter := models.FilterRequest{Specialty: "All", Network: "Commercial"}
+
+	for _, test := range []struct {
+		asOf             time.Time
+		networkMembers   int
+		locationsInForce int
+	}{
+		{time.Date(2022, 6, 30, 0, 0, 0, 0, time.UTC), 0, 0},
+		{time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC), 2, 2},
+		{time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC), 1, 1},
+	} {
+		providers, err := repo.GetFilteredProviders(filter, test.asOf)
+		assert.NoError(t, err)
+		assert.Len(t, providers, test.networkMembers, "network members as of %s", test.asOf.Format("2006-01-02"))
+
+		total, _, err := repo.GetCountyTerminatedNetworkBreakdown("Sedgwick", "Commercial", leftNetworkCriteria(test.asOf))
+		assert.NoError(t, err)
+		assert.Equal(t, test.locationsInForce, total, "providers with a location in force as of %s", test.asOf.Format("2006-01-02"))
+	}
+
+	// The same date is served from the cached view
+	asOf := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	s := repo.snapshot()
+	assert.Same(t, s.activity(asOf), s.activity(asOf))
+}
+
+func TestTerminatedWindowMovesWithAsOfDate(t *testing.T) {
+	repo := newJSONRepositoryFrom(&jsonSnapshot{
+		providerNetwork: []models.ProviderNetwork{
+			{ProviderID: "P1", NetworkID: "Commercial", TerminationReason: "Left Network", TerminationDate: time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)},
+		},
+	})
+
+	for _, test := range []struct {
+		asOf     time.Time
+		expected int
+	}{
+		{time.Date(2022, 6, 30, 0, 0, 0, 0, time.UTC), 0}, // Too recent
+		{time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC), 1},
+		{time.Date(2027, 6, 30, 0, 0, 0, 0, time.UTC), 0}, // Older than five years
+	} {
+		breakdown, err := repo.GetTerminatedNetworkBreakdown("Commercial", leftNetworkCriteria(test.asOf))
+		assert.NoError(t, err)
+		assert.Equal(t, test.expected, breakdown.Total)
+	}
+}
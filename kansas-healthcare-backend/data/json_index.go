@@ -0,0 +1,114 @@
+package data
+
+import (
+	"kansas-healthcare-api/models"
+	"sync"
+)
+
+// jsonIndex holds secondary indexes and per-county aggregates over a loaded dataset, so request
+// handlers look rows up by key instead of scanning every provider, network row and location.
+// Index slices hold positions into the repository slices and preserve file order.
+type jsonIndex struct {
+	providersByID        map[string]int
+	providersByCounty    map[string][]int
+	providersBySpecialty map[string][]int
+	networksByProvider   map[string][]int
+	networksByNetwork    map[string][]int
+	locationsByProvider  map[string][]int
+	locationsByID        map[string]int
+	claimsByCounty       map[string]int
+	areaByCounty         map[string]float64
+	centroidByCounty     map[string]models.Coordinate
+
+	// locationTree indexes the geocoded service locations; tree ids are positions in locationTreeRows,
+	// which holds each location's position in the repository slice
+	locationTree     *spatialIndex
+	locationTreeRows []int
+	// outOfStateTree indexes the out-of-state locations; tree ids are positions in the repository slice
+	outOfStateTree *spatialIndex
+
+	countyLocator *countyLocator
+	geocoder      *Geocoder
+
+	activeProviderCount int
+
+	quality *models.DataQualityReport
+
+	// Views of which affiliations and locations are in force, keyed by as-of date in Unix nanoseconds
+	activityMu     sync.Mutex
+	activityByDate map[int64]*jsonActivity
+}
+
+func buildJSONIndex(r *jsonSnapshot) *jsonIndex {
+	idx := &jsonIndex{
+		providersByID:        make(map[string]int, len(r.providers)),
+		providersByCounty:    make(map[string][]int),
+		providersBySpecialty: make(map[string][]int),
+		networksByProvider:   make(map[string][]int, len(r.providers)),
+		networksByNetwork:    make(map[string][]int),
+		locationsByProvider:  make(map[string][]int),
+		locationsByID:        make(map[string]int, len(r.providerServiceLocations)),
+		claimsByCounty:       make(map[string]int, len(r.countyClaims)),
+		areaByCounty:         make(map[string]float64, len(r.countyAreas)),
+		centroidByCounty:     make(map[string]models.Coordinate, len(r.countyAreas)),
+		activityByDate:       make(map[int64]*jsonActivity),
+	}
+
+	for i, provider := range r.providers {
+		if _, exists := idx.providersByID[provider.ProviderID]; !exists {
+			idx.providersByID[provider.ProviderID] = i
+		}
+		idx.providersByCounty[provider.County] = append(idx.providersByCounty[provider.County], i)
+		idx.providersBySpecialty[provider.ProviderType] = append(idx.providersBySpecialty[provider.ProviderType], i)
+		if provider.Status == "Active" {
+			idx.activeProviderCount++
+		}
+	}
+
+	for i, network := range r.providerNetwork {
+		idx.networksByProvider[network.ProviderID] = append(idx.networksByProvider[network.ProviderID], i)
+		idx.networksByNetwork[network.NetworkID] = append(idx.networksByNetwork[network.NetworkID], i)
+	}
+
+	var coordinates []models.Coordinate
+	for i, location := range r.providerServiceLocations {
+		idx.locationsByProvider[location.ProviderID] = append(idx.locationsByProvider[location.ProviderID], i)
+		idx.locationsByID[location.LocationID] = i
+		if hasCoordinates(location) {
+			coordinates = append(coordinates, models.Coordinate{Latitude: location.Latitude, Longitude: location.Longitude})
+			idx.locationTreeRows = append(idx.locationTreeRows, i)
+		}
+	}
+	idx.locationTree = newSpatialIndex(coordinates)
+	idx.outOfStateTree = newSpatialIndex(outOfStateCoordinates(r.outOfStateLocations))
+
+	for i, claims := range r.countyClaims {
+		if _, exists := idx.claimsByCounty[claims.County]; !exists {
+			idx.claimsByCounty[claims.County] = i
+		}
+	}
+
+	for _, area := range r.countyAreas {
+		if _, exists := idx.areaByCounty[area.County]; !exists {
+			idx.areaByCounty[area.County] = area.AreaSqMiles
+			if area.Latitude != 0 || area.Longitude != 0 {
+				idx.centroidByCounty[area.County] = models.Coordinate{Latitude: area.Latitude, Longitude: area.Longitude}
+			}
+		}
+	}
+
+	idx.countyLocator = newCountyLocator(r.countyBoundaries)
+	idx.geocoder = NewGeocoder(r.zipCentroids, r.cityCentroids)
+
+	idx.quality = checkDataQuality(r.providers, r.providerNetwork, r.providerServiceLocations, r.countyClaims, r.countyAreas,
+		r.countyBoundaries, idx.geocoder)
+	return idx
+}
+
+func (idx *jsonIndex) countyArea(county string) float64 {
+	if area, ok := idx.areaByCounty[county]; ok {
+		return area
+	}
+	// Fallback to average if county not found
+	return defaultCountyAreaSqMiles
+}
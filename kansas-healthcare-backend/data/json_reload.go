@@ -0,0 +1,124 @@
+package data
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+)
+
+// ReloadStatus describes the snapshot being served and the outcome of the latest reload attempt
+type ReloadStatus struct {
+	LoadedAt    time.Time `json:"loaded_at"`
+	LastAttempt time.Time `json:"last_attempt"`
+	LastError   string    `json:"last_error,omitempty"`
+	// Degraded lists optional datasets the served snapshot was loaded without
+	Degraded []string `json:"degraded,omitempty"`
+}
+
+// fileStamp identifies one version of a data file for change detection
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// statDataFiles stamps every data file on disk; files that cannot be stat'ed, including those
+// served from the embedded dataset, are left out so their appearance registers as a change
+func (r *JSONRepository) statDataFiles() map[string]fileStamp {
+	stamps := make(map[string]fileStamp, len(dataFiles))
+	for _, name := range dataFiles {
+		if info, err := os.Stat(r.opts.path(name)); err == nil {
+			stamps[name] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+		}
+	}
+	return stamps
+}
+
+func sameStamps(a, b map[string]fileStamp) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, stamp := range a {
+		if other, ok := b[name]; !ok || !other.modTime.Equal(stamp.modTime) || other.size != stamp.size {
+			return false
+		}
+	}
+	return true
+}
+
+// Reload re-reads every data file and atomically swaps in the new snapshot. If any file fails to
+// load or validate the current snapshot keeps being served and the error is returned.
+func (r *JSONRepository) Reload() error {
+	r.reloadMu.Lock()
+	defer r.reloadMu.Unlock()
+	return r.reloadLocked(r.statDataFiles())
+}
+
+// reloadLocked loads a snapshot for files stamped before reading, so edits made mid-read are picked up next poll
+func (r *JSONRepository) reloadLocked(stamps map[string]fileStamp) error {
+	r.attemptedStamps = stamps
+	r.status.LastAttempt = time.Now().UTC()
+
+	s, err := loadSnapshot(r.opts)
+	if err != nil {
+		r.status.LastError = err.Error()
+		return err
+	}
+
+	r.current.Store(s)
+	r.loadedStamps = stamps
+	r.status.LoadedAt = r.status.LastAttempt
+	r.status.LastError = ""
+	r.status.Degraded = nil
+	for _, loadErr := range s.degraded {
+		r.status.Degraded = append(r.status.Degraded, loadErr.Error())
+	}
+	logDataQuality(s.idx.quality)
+	return nil
+}
+
+// reloadIfChanged reloads when any data file differs from the last attempt. A file that failed to
+// load is not retried until it changes again.
+func (r *JSONRepository) reloadIfChanged() (bool, error) {
+	r.reloadMu.Lock()
+	defer r.reloadMu.Unlock()
+
+	stamps := r.statDataFiles()
+	if sameStamps(stamps, r.attemptedStamps) {
+		return false, nil
+	}
+	return true, r.reloadLocked(stamps)
+}
+
+// Watch polls the data directory every interval until ctx is cancelled, swapping in a new snapshot
+// whenever the files change. Failed reloads are passed to onError while the previous snapshot
+// stays live.
+func (r *JSONRepository) Watch(ctx context.Context, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := r.reloadIfChanged()
+			if err != nil {
+				if onError != nil {
+					onError(err)
+				}
+				continue
+			}
+			if reloaded {
+				log.Printf("[INFO] Reloaded data files from %s", r.opts.dir())
+			}
+		}
+	}
+}
+
+// ReloadStatus reports when the served snapshot was loaded and the last reload error, if any
+func (r *JSONRepository) ReloadStatus() ReloadStatus {
+	r.reloadMu.Lock()
+	defer r.reloadMu.Unlock()
+	return r.status
+}
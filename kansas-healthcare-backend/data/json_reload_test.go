@@ -0,0 +1,127 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"kansas-healthcare-api/models"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestDataset writes a minimal but valid set of data files into dir
+func writeTestDataset(t *testing.T, dir string, providers []models.Provider) {
+	files := map[string]interface{}{
+		providersFile:                 providers,
+		providerNetworksFile:          []models.ProviderNetwork{},
+		providerServiceLocationsFile:  []models.ProviderServiceLocation{},
+		claimsFile:                    []models.CountyClaims{{County: "Sedgwick", ClaimsCount: 100, AvgClaimAmount: 250}},
+		countyAreasFile:               []models.CountyArea{{County: "Sedgwick", AreaSqMiles: 1009.8}},
+		specialtyDensityStandardsFile: map[string]float64{"Primary Care": 2.5},
+		adequacyStandardsFile:         []models.AdequacyStandard{},
+		countyDesignationsFile:        []models.CountyDesignation{},
+		countyBoundariesFile:          map[string]interface{}{"type": "FeatureCollection", "features": []interface{}{}},
+		countyPopulationFile:          []models.CountyPopulation{},
+		zipCentroidsFile:              []models.ZipCentroid{},
+		cityCentroidsFile:             []models.CityCentroid{},
+	}
+	for name, content := range files {
+		body, err := json.Marshal(content)
+		require.NoError(t, err)
+		writeTestFile(t, filepath.Join(dir, name), body)
+	}
+}
+
+// writeTestFile writes body and bumps the modification time so polling sees the change
+func writeTestFile(t *testing.T, path string, body []byte) {
+	require.NoError(t, os.WriteFile(path, body, 0o644))
+	stamp := time.Now().Add(time.Duration(len(body)) * time.Second)
+	require.NoError(t, os.Chtimes(path, stamp, stamp))
+}
+
+func TestReloadSwapsSnapshotWhenFilesChange(t *testing.T) {
+	dir := t.TempDir()
+	writeTestDataset(t, dir, []models.Provider{
+		{ProviderID: "P1", Status: "Active", County: "Sedgwick"},
+	})
+
+	repo, err := NewJSONRepository(LoadOptions{DataDir: dir})
+	require.NoError(t, err)
+	before := repo.snapshot()
+
+	reloaded, err := repo.reloadIfChanged()
+	assert.NoError(t, err)
+	assert.False(t, reloaded)
+
+	writeTestDataset(t, dir, []models.Provider{
+		{ProviderID: "P1", Status: "Active", County: "Sedgwick"},
+		{ProviderID: "P2", Status: "Active", County: "Sedgwick"},
+	})
+	reloaded, err = repo.reloadIfChanged()
+	assert.NoError(t, err)
+	assert.True(t, reloaded)
+
+	count, _ := repo.GetActiveProviderCount()
+	assert.Equal(t, 2, count)
+	assert.Len(t, before.providers, 1, "published snapshots must not be mutated")
+}
+
+func TestFailedReloadKeepsServingPreviousSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	writeTestDataset(t, dir, []models.Provider{
+		{ProviderID: "P1", Status: "Active", County: "Sedgwick"},
+	})
+
+	repo, err := NewJSONRepository(LoadOptions{DataDir: dir})
+	require.NoError(t, err)
+
+	writeTestFile(t, filepath.Join(dir, providersFile), []byte(`[{"provider_id": "P1",`))
+	reloaded, err := repo.reloadIfChanged()
+	assert.True(t, reloaded)
+	assert.Error(t, err)
+	assert.Contains(t, repo.ReloadStatus().LastError, providersFile)
+
+	count, _ := repo.GetActiveProviderCount()
+	assert.Equal(t, 1, count)
+
+	// The broken file is not retried until it changes again
+	reloaded, err = repo.reloadIfChanged()
+	assert.False(t, reloaded)
+	assert.NoError(t, err)
+
+	writeTestFile(t, filepath.Join(dir, providersFile), []byte(`[{"provider_id": "P1"}, {"provider_id": "P1"}]`))
+	_, err = repo.reloadIfChanged()
+	assert.ErrorContains(t, err, "duplicates provider_id")
+}
+
+func TestWatchReportsReloadErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeTestDataset(t, dir, []models.Provider{
+		{ProviderID: "P1", Status: "Active", County: "Sedgwick"},
+	})
+
+	repo, err := NewJSONRepository(LoadOptions{DataDir: dir})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errs := make(chan error, 1)
+	go repo.Watch(ctx, 10*time.Millisecond, func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	})
+
+	writeTestFile(t, filepath.Join(dir, claimsFile), []byte(`not json`))
+	select {
+	case err := <-errs:
+		assert.ErrorContains(t, err, claimsFile)
+	case <-time.After(5 * time.Second):
+		t.Fatal("watcher did not report the broken claims file")
+	}
+}
@@ -1,212 +1,83 @@
 package data
 
 import (
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"kansas-healthcare-api/config"
 	"kansas-healthcare-api/models"
-	"log"
-	"math"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// JSONRepository serves every query from an immutable in-memory snapshot of the JSON datasets.
+// Reloads build a complete new snapshot off to the side and swap the pointer, so a request that
+// has picked up a snapshot keeps a consistent view even while the files are being refreshed.
 type JSONRepository struct {
-	providers                []models.Provider
-	providerNetwork          []models.ProviderNetwork
-	providerServiceLocations []models.ProviderServiceLocation
-	countyClaims             []models.CountyClaims
-	countyAreas              []models.CountyArea
-	specialtyDensityStandards map[string]float64
-}
-
-func NewJSONRepository() *JSONRepository {
-	repo := &JSONRepository{}
-	repo.loadData()
-	return repo
-}
-
-func (r *JSONRepository) loadData() {
-	r.loadProviders()
-	r.loadProviderNetworks()
-	r.loadProviderServiceLocations()
-	r.loadCountyClaims()
-	r.loadCountyAreas()
-	r.loadSpecialtyDensityStandards()
-}
-
-func (r *JSONRepository) loadProviders() {
-	file, err := ioutil.ReadFile("data/providers.json")
-	if err != nil {
-		log.Fatal("Required file data/providers.json not found:", err)
-	}
-	if err := json.Unmarshal(file, &r.providers); err != nil {
-		log.Fatal("Error parsing providers.json:", err)
-	}
-}
+	opts    LoadOptions
+	current atomic.Pointer[jsonSnapshot]
 
-func (r *JSONRepository) loadProviderNetworks() {
-	file, err := ioutil.ReadFile("data/provider_networks.json")
-	if err != nil {
-		log.Fatal("Required file data/provider_networks.json not found:", err)
-	}
-	if err := json.Unmarshal(file, &r.providerNetwork); err != nil {
-		log.Fatal("Error parsing provider_networks.json:", err)
-	}
+	reloadMu        sync.Mutex
+	loadedStamps    map[string]fileStamp
+	attemptedStamps map[string]fileStamp
+	status          ReloadStatus
 }
 
-func (r *JSONRepository) loadProviderServiceLocations() {
-	file, err := ioutil.ReadFile("data/provider_service_locations.json")
-	if err != nil {
-		log.Fatal("Required file data/provider_service_locations.json not found:", err)
-	}
-	if err := json.Unmarshal(file, &r.providerServiceLocations); err != nil {
-		log.Fatal("Error parsing provider_service_locations.json:", err)
+// NewJSONRepository loads every dataset, returning a *LoadError that locates the problem when a
+// file is missing, malformed or fails validation
+func NewJSONRepository(opts LoadOptions) (*JSONRepository, error) {
+	repo := &JSONRepository{opts: opts}
+	if err := repo.Reload(); err != nil {
+		return nil, err
 	}
+	return repo, nil
 }
 
-func (r *JSONRepository) loadCountyClaims() {
-	file, err := ioutil.ReadFile("data/claims.json")
-	if err != nil {
-		log.Fatal("Required file data/claims.json not found:", err)
-	}
-	if err := json.Unmarshal(file, &r.countyClaims); err != nil {
-		log.Fatal("Error parsing claims.json:", err)
-	}
+// newJSONRepositoryFrom wraps an in-memory snapshot, building its indexes. Writes to it are not
+// persisted anywhere.
+func newJSONRepositoryFrom(s *jsonSnapshot) *JSONRepository {
+	assignLocationIDs(s.providerServiceLocations)
+	geocodeLocations(NewGeocoder(s.zipCentroids, s.cityCentroids), s.providerServiceLocations)
+	s.idx = buildJSONIndex(s)
+	repo := &JSONRepository{}
+	repo.current.Store(s)
+	return repo
 }
 
-func (r *JSONRepository) loadCountyAreas() {
-	file, err := ioutil.ReadFile("data/county_areas.json")
-	if err != nil {
-		log.Fatal("Required file data/county_areas.json not found:", err)
-	}
-	if err := json.Unmarshal(file, &r.countyAreas); err != nil {
-		log.Fatal("Error parsing county_areas.json:", err)
-	}
-}
-
-func (r *JSONRepository) loadSpecialtyDensityStandards() {
-	file, err := ioutil.ReadFile("data/specialty_density_standards.json")
-	if err != nil {
-		log.Fatal("Required file data/specialty_density_standards.json not found:", err)
-	}
-	if err := json.Unmarshal(file, &r.specialtyDensityStandards); err != nil {
-		log.Fatal("Error parsing specialty_density_standards.json:", err)
-	}
+// snapshot returns the dataset currently being served
+func (r *JSONRepository) snapshot() *jsonSnapshot {
+	return r.current.Load()
 }
 
 func (r *JSONRepository) GetProviders() ([]models.Provider, error) {
-	return r.providers, nil
+	return r.snapshot().providers, nil
 }
 
 func (r *JSONRepository) GetProviderNetworks() ([]models.ProviderNetwork, error) {
-	return r.providerNetwork, nil
+	return r.snapshot().providerNetwork, nil
 }
 
 func (r *JSONRepository) GetProviderServiceLocations() ([]models.ProviderServiceLocation, error) {
-	return r.providerServiceLocations, nil
+	return r.snapshot().providerServiceLocations, nil
 }
 
 func (r *JSONRepository) calculateProviderDensity(providerCount int) string {
-	if providerCount >= 200 {
-		return "high"
-	} else if providerCount >= 50 {
-		return "medium"
-	} else if providerCount >= 30 {
-		return "low"
-	}
-	return "critical"
-}
-
-// haversineDistance calculates the distance between two points on Earth using the Haversine formula
-func (r *JSONRepository) haversineDistance(lat1, lng1, lat2, lng2 float64) float64 {
-	const earthRadiusMiles = 3959.0
-	
-	// Convert degrees to radians
-	lat1Rad := lat1 * math.Pi / 180
-	lng1Rad := lng1 * math.Pi / 180
-	lat2Rad := lat2 * math.Pi / 180
-	lng2Rad := lng2 * math.Pi / 180
-	
-	// Haversine formula
-	dlat := lat2Rad - lat1Rad
-	dlng := lng2Rad - lng1Rad
-	a := math.Sin(dlat/2)*math.Sin(dlat/2) + math.Cos(lat1Rad)*math.Cos(lat2Rad)*math.Sin(dlng/2)*math.Sin(dlng/2)
-	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
-	return earthRadiusMiles * c
+	return providerDensity(providerCount)
 }
 
 // calculateActualProviderDistances calculates average distance between providers using their actual locations
-func (r *JSONRepository) calculateActualProviderDistances(county string) float64 {
-	// Get active providers in county with locations
-	var providerLocations []models.ProviderServiceLocation
-	for _, provider := range r.providers {
-		if provider.County == county && provider.Status == "Active" {
-			// Find active service location for this provider
-			for _, location := range r.providerServiceLocations {
-				if location.ProviderID == provider.ProviderID && 
-				   location.County == county && 
-				   location.TerminationDate.Year() == 9999 {
-					providerLocations = append(providerLocations, location)
-					break
-				}
-			}
-		}
-	}
-	
-	if len(providerLocations) < 2 {
-		return 0
-	}
-	
-	// Calculate average distance to nearest provider for each provider
-	var totalDistance float64
-	for i, p1 := range providerLocations {
-		minDistance := math.MaxFloat64
-		for j, p2 := range providerLocations {
-			if i != j {
-				distance := r.haversineDistance(p1.Latitude, p1.Longitude, p2.Latitude, p2.Longitude)
-				if distance < minDistance {
-					minDistance = distance
-				}
-			}
-		}
-		totalDistance += minDistance
-	}
-	
-	return totalDistance / float64(len(providerLocations))
+func (r *JSONRepository) calculateActualProviderDistances(county string, asOf time.Time) float64 {
+	return r.snapshot().activity(asOf).nearestDistanceByCounty[county]
 }
 
-func (r *JSONRepository) calculateProviderDensityMiles(providerCount int, county string) string {
+func (r *JSONRepository) calculateProviderDensityMiles(providerCount int, county string, asOf time.Time) string {
 	if providerCount == 0 {
 		return "No providers"
 	}
-	
-	// Try to calculate actual distances first
-	actualDistance := r.calculateActualProviderDistances(county)
-	if actualDistance > 0 {
-		return fmt.Sprintf("~%.1f mi apart", actualDistance)
-	}
-	
-	// Fallback to area-based calculation
-	countyAreaSqMiles := r.getCountyArea(county)
-	providersPerSqMile := float64(providerCount) / countyAreaSqMiles
-	
-	if providersPerSqMile >= 1 {
-		return fmt.Sprintf("%.1f/sq mi", providersPerSqMile)
-	} else {
-		avgDistanceMiles := math.Sqrt(countyAreaSqMiles / float64(providerCount))
-		return fmt.Sprintf("~%.1f mi apart", avgDistanceMiles)
-	}
+	return providerDensityMiles(providerCount, r.calculateActualProviderDistances(county, asOf), r.getCountyArea(county))
 }
 
 func (r *JSONRepository) getCountyArea(county string) float64 {
-	for _, area := range r.countyAreas {
-		if area.County == county {
-			return area.AreaSqMiles
-		}
-	}
-	// Fallback to average if county not found
-	return 700.0
+	return r.snapshot().idx.countyArea(county)
 }
 
 func (r *JSONRepository) GetCountyArea(county string) float64 {
@@ -214,251 +85,411 @@ func (r *JSONRepository) GetCountyArea(county string) float64 {
 }
 
 func (r *JSONRepository) GetSpecialtyDensityStandards() map[string]float64 {
-	return r.specialtyDensityStandards
+	return r.snapshot().specialtyDensityStandards
 }
 
-func (r *JSONRepository) GetCountyStats() ([]models.CountyStats, error) {
-	var countyStats []models.CountyStats
-	
-	// Create a map to count active providers by county
-	providerCounts := make(map[string]int)
-	for _, provider := range r.providers {
-		if provider.Status == "Active" {
-			providerCounts[provider.County]++
-		}
+// GetDataQualityReport returns the checks run when the current snapshot was loaded
+func (r *JSONRepository) GetDataQualityReport() (*models.DataQualityReport, error) {
+	return r.snapshot().idx.quality, nil
+}
+
+func (r *JSONRepository) GetCountyBoundaries() ([]models.CountyBoundary, error) {
+	return r.snapshot().countyBoundaries, nil
+}
+
+func (r *JSONRepository) GetCountyBoundary(county string) (*models.CountyBoundary, error) {
+	s := r.snapshot()
+	i, ok := s.idx.countyLocator.byCounty[county]
+	if !ok {
+		return nil, fmt.Errorf("boundary for county %s %w", county, ErrNotFound)
 	}
-	
-	// Combine with claims data
-	for _, claims := range r.countyClaims {
-		providerCount := providerCounts[claims.County]
-		density := r.calculateProviderDensity(providerCount)
-		
-		countyStats = append(countyStats, models.CountyStats{
-			County:         claims.County,
-			ProviderCount:  providerCount,
-			ClaimsCount:    claims.ClaimsCount,
-			AvgClaimAmount: claims.AvgClaimAmount,
-			Density:        density,
-			DensityMiles:   r.calculateProviderDensityMiles(providerCount, claims.County),
-		})
+	boundary := s.countyBoundaries[i]
+	return &boundary, nil
+}
+
+func (r *JSONRepository) GetCountyStats(asOf time.Time) ([]models.CountyStats, error) {
+	s := r.snapshot()
+	view := s.activity(asOf)
+
+	var countyStats []models.CountyStats
+	for _, claims := range s.countyClaims {
+		countyStats = append(countyStats, view.countyStats[claims.County])
 	}
-	
 	return countyStats, nil
 }
 
-func (r *JSONRepository) GetCountyStatsByName(county string) (*models.CountyStats, error) {
-	// Count active providers in the county
-	providerCount := 0
-	for _, provider := range r.providers {
-		if provider.County == county && provider.Status == "Active" {
-			providerCount++
-		}
-	}
-	
-	// Find claims data for the county
-	for _, claims := range r.countyClaims {
-		if claims.County == county {
-			density := r.calculateProviderDensity(providerCount)
-			return &models.CountyStats{
-				County:         county,
-				ProviderCount:  providerCount,
-				ClaimsCount:    claims.ClaimsCount,
-				AvgClaimAmount: claims.AvgClaimAmount,
-				Density:        density,
-				DensityMiles:   r.calculateProviderDensityMiles(providerCount, county),
-			}, nil
-		}
+func (r *JSONRepository) GetCountyStatsByName(county string, asOf time.Time) (*models.CountyStats, error) {
+	stats, ok := r.snapshot().activity(asOf).countyStats[county]
+	if !ok {
+		return nil, nil
 	}
-	
-	return nil, nil
+	return &stats, nil
 }
 
 func (r *JSONRepository) GetProvidersInCounty(county string) ([]models.Provider, error) {
+	s := r.snapshot()
+
 	var countyProviders []models.Provider
-	for _, provider := range r.providers {
-		if provider.County == county {
-			countyProviders = append(countyProviders, provider)
-		}
+	for _, i := range s.idx.providersByCounty[county] {
+		countyProviders = append(countyProviders, s.providers[i])
 	}
 	return countyProviders, nil
 }
 
-func (r *JSONRepository) GetFilteredProviders(filter models.FilterRequest) ([]models.Provider, error) {
+func (r *JSONRepository) GetFilteredProviders(filter models.FilterRequest, asOf time.Time) ([]models.Provider, error) {
+	s := r.snapshot()
+	idx := s.idx
+
 	// Get active providers in the specified network
-	activeNetworkProviders := make(map[string]bool)
-	for _, network := range r.providerNetwork {
-		if network.NetworkID == filter.Network && network.TerminationReason == "" {
-			activeNetworkProviders[network.ProviderID] = true
+	activeNetworkProviders := s.activity(asOf).activeNetworkMembers[filter.Network]
+
+	// Narrow candidates by specialty (all providers if "All")
+	candidates := idx.providersBySpecialty[filter.Specialty]
+	if filter.Specialty == "All" {
+		candidates = nil
+		for i := range s.providers {
+			candidates = append(candidates, i)
 		}
 	}
 
 	var filtered []models.Provider
-	for _, provider := range r.providers {
-		// Only include active providers
-		if provider.Status != "Active" {
-			continue
-		}
-		
-		// Filter by network
-		if !activeNetworkProviders[provider.ProviderID] {
+	for _, i := range candidates {
+		provider := s.providers[i]
+		// Only include active providers in the network
+		if provider.Status != "Active" || !activeNetworkProviders[provider.ProviderID] {
 			continue
 		}
-		
-		// Filter by specialty (skip if "All")
-		if filter.Specialty != "All" && provider.ProviderType != filter.Specialty {
-			continue
-		}
-		
 		filtered = append(filtered, provider)
 	}
 	return filtered, nil
 }
 
 func (r *JSONRepository) GetActiveProviderCount() (int, error) {
-	count := 0
-	for _, provider := range r.providers {
-		if provider.Status == "Active" {
-			count++
-		}
+	return r.snapshot().idx.activeProviderCount, nil
+}
+
+func (r *JSONRepository) GetActiveLocationProviders(county string, asOf time.Time) ([]models.Provider, error) {
+	s := r.snapshot()
+	view := s.activity(asOf)
+	providerIds := view.activeProviders
+	if county != "" {
+		providerIds = view.activeLocationProviders[county]
 	}
-	return count, nil
+
+	var providers []models.Provider
+	for _, providerId := range providerIds {
+		providers = append(providers, s.providers[s.idx.providersByID[providerId]])
+	}
+	return providers, nil
 }
 
-func (r *JSONRepository) GetTerminatedNetworkCount(networkId string) (int, error) {
-	now := time.Now()
-	twoYearsAgo := now.AddDate(-2, 0, 0)
-	fiveYearsAgo := now.AddDate(-5, 0, 0)
+// providerSpecialty returns the provider type of a provider, or "" for a provider not on the roster
+func (s *jsonSnapshot) providerSpecialty(providerId string) string {
+	if i, ok := s.idx.providersByID[providerId]; ok {
+		return s.providers[i].ProviderType
+	}
+	return ""
+}
 
-	count := 0
-	for _, network := range r.providerNetwork {
-		if network.NetworkID == networkId &&
-			network.TerminationReason == "Left Network" &&
-			network.TerminationDate.After(fiveYearsAgo) &&
-			network.TerminationDate.Before(twoYearsAgo) {
-			count++
+// terminationPopulation returns the affiliations with a network that a termination percentage is
+// taken over: all of them, or only those of the active providers with a service location in force
+// in county when it is given
+func (s *jsonSnapshot) terminationPopulation(county, networkId string, asOf time.Time) []models.ProviderNetwork {
+	var population map[string]bool
+	if county != "" {
+		population = make(map[string]bool)
+		for _, providerId := range s.activity(asOf).activeLocationProviders[county] {
+			population[providerId] = true
 		}
 	}
-	return count, nil
+
+	var networks []models.ProviderNetwork
+	for _, i := range s.idx.networksByNetwork[networkId] {
+		network := s.providerNetwork[i]
+		if population == nil || population[network.ProviderID] {
+			networks = append(networks, network)
+		}
+	}
+	return networks
 }
 
-// GetCountyTerminatedNetworkCount implements the specific algorithm for county-based terminated network analysis
-func (r *JSONRepository) GetCountyTerminatedNetworkCount(county, networkId string) (int, int, error) {
-	fiveYearsAgo, twoYearsAgo := config.GetTerminatedAnalysisTimeRange()
-	
-	// Step 1: Get active providers in county with active service locations
-	activeProviderIds := make(map[string]bool)
-	for _, provider := range r.providers {
-		if provider.Status == "Active" {
-			// Check if provider has active service location in county
-			for _, location := range r.providerServiceLocations {
-				if location.ProviderID == provider.ProviderID &&
-					location.County == county &&
-					location.TerminationDate.Year() == 9999 { // Active service location
-					activeProviderIds[provider.ProviderID] = true
-					break
-				}
-			}
+// GetTerminatedNetworkBreakdown counts the providers who left the network in the window, the same
+// providers GetTerminationRate counts. Under the legacy percentage it counts every matching
+// affiliation instead, as the original analysis did.
+func (r *JSONRepository) GetTerminatedNetworkBreakdown(networkId string, criteria models.TerminationCriteria) (*models.TerminationBreakdown, error) {
+	s := r.snapshot()
+	if !criteria.LegacyPercentage {
+		return terminationBreakdown(s.terminationPopulation("", networkId, criteria.AsOf), s.providerSpecialty, criteria), nil
+	}
+
+	breakdown := newTerminationBreakdown()
+	for _, i := range s.idx.networksByNetwork[networkId] {
+		network := s.providerNetwork[i]
+		if matchesTermination(network, criteria) {
+			addTermination(breakdown, network.TerminationReason, s.providerSpecialty(network.ProviderID), network.TerminationDate)
 		}
 	}
-	
+	return breakdown, nil
+}
+
+// GetCountyTerminatedNetworkBreakdown counts the active providers with a service location in force
+// in the county, and among them those who left the network in the window, the same providers
+// GetTerminationRate counts. Under the legacy percentage it implements the original algorithm,
+// which also counts providers without an affiliation row for the network as terminated.
+func (r *JSONRepository) GetCountyTerminatedNetworkBreakdown(county, networkId string, criteria models.TerminationCriteria) (int, *models.TerminationBreakdown, error) {
+	s := r.snapshot()
+	idx := s.idx
+
+	// Step 1: Get active providers in county with active service locations
+	activeProviderIds := s.activity(criteria.AsOf).activeLocationProviders[county]
 	totProvbyCounty := len(activeProviderIds)
-	
+	if !criteria.LegacyPercentage {
+		return totProvbyCounty, terminationBreakdown(s.terminationPopulation(county, networkId, criteria.AsOf), s.providerSpecialty, criteria), nil
+	}
+
 	// Step 2: Check terminated network providers
-	termNetworkCount := 0
-	for providerId := range activeProviderIds {
+	breakdown := newTerminationBreakdown()
+	for _, providerId := range activeProviderIds {
 		found := false
-		for _, network := range r.providerNetwork {
-			if network.ProviderID == providerId && network.NetworkID == networkId {
+		for _, i := range idx.networksByProvider[providerId] {
+			network := s.providerNetwork[i]
+			if network.NetworkID == networkId {
 				found = true
 				// Check if terminated in the specified timeframe
-				if network.TerminationReason == config.LeftNetworkReason &&
-					network.TerminationDate.After(fiveYearsAgo) &&
-					network.TerminationDate.Before(twoYearsAgo) {
-					termNetworkCount++
+				if matchesTermination(network, criteria) {
+					addTermination(breakdown, network.TerminationReason, s.providerSpecialty(providerId), network.TerminationDate)
 				}
 				break
 			}
 		}
 		// If no row found in Provider Network Table for the provider ID for that specific Network
 		if !found {
-			termNetworkCount++
+			addTermination(breakdown, config.NoAffiliationReason, s.providerSpecialty(providerId), time.Time{})
 		}
 	}
-	
-	return totProvbyCounty, termNetworkCount, nil
+
+	return totProvbyCounty, breakdown, nil
+}
+
+func (r *JSONRepository) GetTerminationRate(county, networkId string, criteria models.TerminationCriteria) (int, int, error) {
+	numerator, denominator := terminationRate(r.snapshot().terminationPopulation(county, networkId, criteria.AsOf), criteria)
+	return numerator, denominator, nil
 }
 
-func (r *JSONRepository) GetTerminatedServiceLocationCount(networkId string) (int, error) {
-	now := time.Now()
-	twoYearsAgo := now.AddDate(-2, 0, 0)
-	fiveYearsAgo := now.AddDate(-5, 0, 0)
+func (r *JSONRepository) GetTerminatedServiceLocationCount(networkId string, criteria models.TerminationCriteria) (int, error) {
+	s := r.snapshot()
+	idx := s.idx
 
 	// Get provider IDs that terminated from the network
 	terminatedProviderIds := make(map[string]bool)
-	for _, network := range r.providerNetwork {
-		if network.NetworkID == networkId &&
-			network.TerminationReason == "Left Network" &&
-			network.TerminationDate.After(fiveYearsAgo) &&
-			network.TerminationDate.Before(twoYearsAgo) {
+	for _, i := range idx.networksByNetwork[networkId] {
+		network := s.providerNetwork[i]
+		if matchesTermination(network, criteria) {
 			terminatedProviderIds[network.ProviderID] = true
 		}
 	}
 
 	// Count service locations for those providers
 	count := 0
-	for _, location := range r.providerServiceLocations {
-		if terminatedProviderIds[location.ProviderID] &&
-			location.TerminationDate.After(fiveYearsAgo) &&
-			location.TerminationDate.Before(twoYearsAgo) {
-			count++
+	for providerId := range terminatedProviderIds {
+		for _, i := range idx.locationsByProvider[providerId] {
+			location := s.providerServiceLocations[i]
+			if location.TerminationDate.After(criteria.From) && location.TerminationDate.Before(criteria.To) {
+				count++
+			}
 		}
 	}
 	return count, nil
 }
 
-func (r *JSONRepository) GetRadiusAnalysis(county string, radius int, networkId string) (map[string]interface{}, error) {
-	// Get active providers in the network for the county
-	activeNetworkProviders := make(map[string]bool)
-	for _, network := range r.providerNetwork {
-		if network.NetworkID == networkId && network.TerminationReason == "" {
-			activeNetworkProviders[network.ProviderID] = true
+func (r *JSONRepository) GetRadiusAnalysis(county string, center *models.Coordinate, radius int, networkId string, asOf time.Time, includeOutOfState bool) (map[string]interface{}, error) {
+	s := r.snapshot()
+	idx := s.idx
+
+	if center == nil {
+		centroid, ok := idx.centroidByCounty[county]
+		if !ok {
+			return nil, errNoCentroid(county)
 		}
+		center = &centroid
 	}
 
-	// Count providers in the county
-	countyProviders := 0
-	specialtyCount := make(map[string]int)
-	for _, provider := range r.providers {
-		if provider.County == county && provider.Status == "Active" && activeNetworkProviders[provider.ProviderID] {
-			countyProviders++
-			specialtyCount[provider.ProviderType]++
+	// Every in-force service location of an active network member within the radius, wherever
+	// the location's county
+	activeNetworkProviders := s.activity(asOf).activeNetworkMembers[networkId]
+	var matches []radiusMatch
+	idx.locationTree.within(*center, float64(radius), func(id int, distance float64) {
+		location := s.providerServiceLocations[idx.locationTreeRows[id]]
+		if !activeNetworkProviders[location.ProviderID] || !activeAt(location.EffectiveDate, location.TerminationDate, asOf) {
+			return
 		}
+		i, ok := idx.providersByID[location.ProviderID]
+		if !ok || s.providers[i].Status != "Active" {
+			return
+		}
+		matches = append(matches, radiusMatch{
+			providerID: location.ProviderID,
+			specialty:  s.providers[i].ProviderType,
+			county:     location.County,
+			distance:   distance,
+		})
+	})
+	if includeOutOfState {
+		accept := s.acceptOutOfStateLocation(networkId, "All", asOf)
+		idx.outOfStateTree.within(*center, float64(radius), func(id int, distance float64) {
+			if accept(id) {
+				matches = append(matches, outOfStateRadiusMatch(s.outOfStateLocations[id], distance))
+			}
+		})
 	}
 
+	result := radiusAnalysisResult(county, *center, radius, networkId, includeOutOfState, matches)
+
 	// Get claims data for the county
-	var claimsData *models.CountyClaims
-	for _, claims := range r.countyClaims {
-		if claims.County == county {
-			claimsData = &claims
-			break
+	if i, ok := idx.claimsByCounty[county]; ok {
+		result["claims_count"] = s.countyClaims[i].ClaimsCount
+		result["avg_claim_amount"] = s.countyClaims[i].AvgClaimAmount
+	}
+
+	return result, nil
+}
+
+func (r *JSONRepository) GetNearestProviders(query models.NearestProviderQuery) ([]models.NearestProvider, error) {
+	s := r.snapshot()
+	idx := s.idx
+	accept := s.acceptNetworkLocation(query.Network, query.Specialty, query.AsOf)
+	before := func(a, b int) bool {
+		return s.providerServiceLocations[idx.locationTreeRows[a]].LocationID < s.providerServiceLocations[idx.locationTreeRows[b]].LocationID
+	}
+
+	neighbours := idx.locationTree.nearestN(query.Center, query.Limit, accept, before)
+	matches := make([]nearestMatch, len(neighbours))
+	for i, neighbour := range neighbours {
+		location := s.providerServiceLocations[idx.locationTreeRows[neighbour.id]]
+		matches[i] = nearestMatch{
+			provider: s.providers[idx.providersByID[location.ProviderID]],
+			location: location,
+			distance: neighbour.distance,
+		}
+	}
+	if query.IncludeOutOfState {
+		accept := s.acceptOutOfStateLocation(query.Network, query.Specialty, query.AsOf)
+		before := func(a, b int) bool { return s.outOfStateLocations[a].LocationID < s.outOfStateLocations[b].LocationID }
+		for _, neighbour := range idx.outOfStateTree.nearestN(query.Center, query.Limit, accept, before) {
+			matches = append(matches, outOfStateNearestMatch(s.outOfStateLocations[neighbour.id], neighbour.distance))
 		}
 	}
+	return nearestResults(matches, query.Limit), nil
+}
 
-	result := map[string]interface{}{
-		"county":           county,
-		"radius":          radius,
-		"network":         networkId,
-		"provider_count":  countyProviders,
-		"specialty_count": len(specialtyCount),
-		"specialties":     specialtyCount,
+// acceptNetworkLocation returns a filter over locationTree ids accepting the locations
+// networkLocation accepts
+func (s *jsonSnapshot) acceptNetworkLocation(networkId, specialty string, asOf time.Time) func(id int) bool {
+	accept := s.networkLocation(networkId, specialty, asOf)
+	return func(id int) bool {
+		return accept(s.providerServiceLocations[s.idx.locationTreeRows[id]])
 	}
+}
 
-	if claimsData != nil {
-		result["claims_count"] = claimsData.ClaimsCount
-		result["avg_claim_amount"] = claimsData.AvgClaimAmount
+// networkLocation returns a filter accepting the locations in force at asOf of active providers
+// of the specialty who are in the network then. An empty networkId accepts any network or none.
+func (s *jsonSnapshot) networkLocation(networkId, specialty string, asOf time.Time) func(location models.ProviderServiceLocation) bool {
+	idx := s.idx
+	activeNetworkProviders := s.activity(asOf).activeNetworkMembers[networkId]
+	return func(location models.ProviderServiceLocation) bool {
+		if networkId != "" && !activeNetworkProviders[location.ProviderID] {
+			return false
+		}
+		if !activeAt(location.EffectiveDate, location.TerminationDate, asOf) {
+			return false
+		}
+		i, ok := idx.providersByID[location.ProviderID]
+		return ok && s.providers[i].Status == "Active" && matchesSpecialty(s.providers[i].ProviderType, specialty)
 	}
+}
 
-	return result, nil
+// acceptOutOfStateLocation returns a filter over outOfStateTree ids accepting the out-of-state
+// locations in force at asOf of the specialty that are in the network then
+func (s *jsonSnapshot) acceptOutOfStateLocation(networkId, specialty string, asOf time.Time) func(id int) bool {
+	return func(id int) bool {
+		location := s.outOfStateLocations[id]
+		return activeAt(location.EffectiveDate, location.TerminationDate, asOf) &&
+			matchesSpecialty(location.ProviderType, specialty) && inOutOfStateNetwork(location, networkId, asOf)
+	}
+}
+
+func (r *JSONRepository) GetNetworkProviderLocations(networkId, specialty string, asOf time.Time) ([]models.ProviderLocation, error) {
+	s := r.snapshot()
+	accept := s.networkLocation(networkId, specialty, asOf)
+
+	locations := []models.ProviderLocation{}
+	for _, location := range s.providerServiceLocations {
+		if accept(location) {
+			locations = append(locations, providerLocation(s.providers[s.idx.providersByID[location.ProviderID]], location))
+		}
+	}
+	sortProviderLocations(locations)
+	return locations, nil
 }
 
+// GetAdequacyStandards returns the time and distance standards adequacy is judged against
+func (r *JSONRepository) GetAdequacyStandards() ([]models.AdequacyStandard, error) {
+	return r.snapshot().adequacyStandards, nil
+}
 
+// GetCountyDesignations returns the designation each county's adequacy standards are set by
+func (r *JSONRepository) GetCountyDesignations() ([]models.CountyDesignation, error) {
+	return r.snapshot().countyDesignations, nil
+}
+
+func (r *JSONRepository) GetCountySamplePoints(county string, gridSize int) ([]models.Coordinate, error) {
+	idx := r.snapshot().idx
+	if points, ok := idx.countyLocator.samplePoints(county, gridSize); ok {
+		return points, nil
+	}
+	centroid, ok := idx.centroidByCounty[county]
+	if !ok {
+		return nil, errNoCentroid(county)
+	}
+	return countySamplePoints(centroid, idx.areaByCounty[county], gridSize), nil
+}
+
+func (r *JSONRepository) GetCoverageGrid(cellMiles float64) ([]models.GridCell, error) {
+	return coverageGrid(r.snapshot().idx.countyLocator, cellMiles), nil
+}
+
+func (r *JSONRepository) GetCatchments(networkId, specialty string, asOf time.Time) ([]models.Catchment, error) {
+	locations, err := r.GetNetworkProviderLocations(networkId, specialty, asOf)
+	if err != nil {
+		return nil, err
+	}
+	return catchments(r.snapshot().idx.countyLocator, locations), nil
+}
+
+func (r *JSONRepository) GetNearestProviderDistances(points []models.Coordinate, specialty, networkId string, asOf time.Time, includeOutOfState bool) ([]float64, error) {
+	s := r.snapshot()
+	distances := nearestDistances(s.idx.locationTree, points, s.acceptNetworkLocation(networkId, specialty, asOf))
+	if includeOutOfState {
+		keepNearer(distances, nearestDistances(s.idx.outOfStateTree, points, s.acceptOutOfStateLocation(networkId, specialty, asOf)))
+	}
+	return distances, nil
+}
+
+func (r *JSONRepository) GetProviderLocationsWithin(points []models.Coordinate, radiusMiles float64, specialty, networkId string, asOf time.Time) ([][]models.LocationDistance, error) {
+	s := r.snapshot()
+	locationID := func(id int) string { return s.providerServiceLocations[s.idx.locationTreeRows[id]].LocationID }
+	return locationsWithin(s.idx.locationTree, points, radiusMiles, s.acceptNetworkLocation(networkId, specialty, asOf), locationID), nil
+}
+
+// GetCountyPopulation returns the resident population of each county
+func (r *JSONRepository) GetCountyPopulation() ([]models.CountyPopulation, error) {
+	return r.snapshot().countyPopulation, nil
+}
+
+func (r *JSONRepository) GetZipCentroids() ([]models.ZipCentroid, error) {
+	return r.snapshot().zipCentroids, nil
+}
+
+func (r *JSONRepository) GetGeocoder() (*Geocoder, error) {
+	return r.snapshot().idx.geocoder, nil
+}
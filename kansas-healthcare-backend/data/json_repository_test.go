@@ -9,7 +9,7 @@ import (
 )
 
 func TestCalculateProviderDensity(t *testing.T) {
-	repo := &JSONRepository{}
+	repo := newJSONRepositoryFrom(&jsonSnapshot{})
 	
 	tests := []struct {
 		providerCount int
@@ -28,11 +28,11 @@ func TestCalculateProviderDensity(t *testing.T) {
 }
 
 func TestCalculateProviderDensityMiles(t *testing.T) {
-	repo := &JSONRepository{
+	repo := newJSONRepositoryFrom(&jsonSnapshot{
 		countyAreas: []models.CountyArea{
 			{County: "TestCounty", AreaSqMiles: 700.0},
 		},
-	}
+	})
 	
 	tests := []struct {
 		providerCount int
@@ -45,19 +45,19 @@ func TestCalculateProviderDensityMiles(t *testing.T) {
 	}
 	
 	for _, test := range tests {
-		result := repo.calculateProviderDensityMiles(test.providerCount, test.county)
+		result := repo.calculateProviderDensityMiles(test.providerCount, test.county, fixtureAsOf)
 		assert.Equal(t, test.expected, result)
 	}
 }
 
 func TestGetProvidersInCounty(t *testing.T) {
-	repo := &JSONRepository{
+	repo := newJSONRepositoryFrom(&jsonSnapshot{
 		providers: []models.Provider{
 			{ProviderID: "1", County: "Sedgwick", Status: "Active"},
 			{ProviderID: "2", County: "Sedgwick", Status: "Terminated"},
 			{ProviderID: "3", County: "Johnson", Status: "Active"},
 		},
-	}
+	})
 	
 	result, err := repo.GetProvidersInCounty("Sedgwick")
 	
@@ -68,13 +68,13 @@ func TestGetProvidersInCounty(t *testing.T) {
 }
 
 func TestGetActiveProviderCount(t *testing.T) {
-	repo := &JSONRepository{
+	repo := newJSONRepositoryFrom(&jsonSnapshot{
 		providers: []models.Provider{
 			{ProviderID: "1", Status: "Active"},
 			{ProviderID: "2", Status: "Active"},
 			{ProviderID: "3", Status: "Terminated"},
 		},
-	}
+	})
 	
 	result, err := repo.GetActiveProviderCount()
 	
@@ -83,18 +83,20 @@ func TestGetActiveProviderCount(t *testing.T) {
 }
 
 func TestGetFilteredProviders(t *testing.T) {
-	repo := &JSONRepository{
+	joined := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	open := time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+	repo := newJSONRepositoryFrom(&jsonSnapshot{
 		providers: []models.Provider{
 			{ProviderID: "1", ProviderType: "Primary Care", Status: "Active"},
 			{ProviderID: "2", ProviderType: "Cardiology", Status: "Active"},
 			{ProviderID: "3", ProviderType: "Primary Care", Status: "Terminated"},
 		},
 		providerNetwork: []models.ProviderNetwork{
-			{ProviderID: "1", NetworkID: "Commercial", TerminationReason: ""},
-			{ProviderID: "2", NetworkID: "Commercial", TerminationReason: ""},
-			{ProviderID: "3", NetworkID: "Commercial", TerminationReason: "Left Network"},
+			{ProviderID: "1", NetworkID: "Commercial", EffectiveDate: joined, TerminationDate: open, TerminationReason: ""},
+			{ProviderID: "2", NetworkID: "Commercial", EffectiveDate: joined, TerminationDate: open, TerminationReason: ""},
+			{ProviderID: "3", NetworkID: "Commercial", EffectiveDate: joined, TerminationDate: joined.AddDate(2, 0, 0), TerminationReason: "Left Network"},
 		},
-	}
+	})
 	
 	filter := models.FilterRequest{
 		Specialty: "Primary Care",
@@ -102,7 +104,7 @@ func TestGetFilteredProviders(t *testing.T) {
 		Metric:    "Provider Density",
 	}
 	
-	result, err := repo.GetFilteredProviders(filter)
+	result, err := repo.GetFilteredProviders(filter, fixtureAsOf)
 	
 	assert.NoError(t, err)
 	assert.Len(t, result, 1)
@@ -112,19 +114,70 @@ func TestGetFilteredProviders(t *testing.T) {
 }
 
 func TestGetTerminatedNetworkCount(t *testing.T) {
-	now := time.Now()
+	now := fixtureAsOf
 	threeYearsAgo := now.AddDate(-3, 0, 0)
 	
-	repo := &JSONRepository{
+	repo := newJSONRepositoryFrom(&jsonSnapshot{
 		providerNetwork: []models.ProviderNetwork{
 			{ProviderID: "1", NetworkID: "Commercial", TerminationReason: "Left Network", TerminationDate: threeYearsAgo},
 			{ProviderID: "2", NetworkID: "Commercial", TerminationReason: "Left Network", TerminationDate: now.AddDate(-1, 0, 0)}, // Too recent
 			{ProviderID: "3", NetworkID: "Medicare", TerminationReason: "Left Network", TerminationDate: threeYearsAgo}, // Wrong network
 		},
-	}
+	})
 	
-	result, err := repo.GetTerminatedNetworkCount("Commercial")
+	result, err := repo.GetTerminatedNetworkBreakdown("Commercial", leftNetworkCriteria(now))
 	
 	assert.NoError(t, err)
-	assert.Equal(t, 1, result)
-}
\ No newline at end of file
+	assert.Equal(t, 1, result.Total)
+}
+
+func TestGetCountyStatsFromIndex(t *testing.T) {
+	repo := newFixtureJSONRepository()
+
+	stats, err := repo.GetCountyStats(fixtureAsOf)
+	assert.NoError(t, err)
+	assert.Len(t, stats, 2)
+	assert.Equal(t, "Johnson", stats[0].County)
+	assert.Equal(t, 0, stats[0].ProviderCount, "P4 has no service location in Johnson")
+	assert.Equal(t, "Sedgwick", stats[1].County)
+	assert.Equal(t, 2, stats[1].ProviderCount)
+	assert.Equal(t, "~10.4 mi apart", stats[1].DensityMiles)
+
+	// Before any service location opened the county had no providers
+	before, err := repo.GetCountyStatsByName("Sedgwick", time.Date(2014, 1, 1, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, before.ProviderCount)
+
+	total, term, err := repo.GetCountyTerminatedNetworkBreakdown("Sedgwick", "Commercial", leftNetworkCriteria(fixtureAsOf))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, total)
+	assert.Equal(t, 1, term.Total)
+}
+
+func TestAverageNearestProviderDistanceMatchesBruteForce(t *testing.T) {
+	var locations []models.ProviderServiceLocation
+	for i := 0; i < 200; i++ {
+		locations = append(locations, models.ProviderServiceLocation{
+			Latitude:  37.0 + float64((i*37)%300)/100,
+			Longitude: -102.0 + float64((i*53)%700)/100,
+		})
+	}
+
+	var expected float64
+	for i, p1 := range locations {
+		minDistance := -1.0
+		for j, p2 := range locations {
+			if i == j {
+				continue
+			}
+			distance := haversineDistance(p1.Latitude, p1.Longitude, p2.Latitude, p2.Longitude)
+			if minDistance < 0 || distance < minDistance {
+				minDistance = distance
+			}
+		}
+		expected += minDistance
+	}
+	expected /= float64(len(locations))
+
+	assert.InDelta(t, expected, averageNearestProviderDistance(locations), 1e-9)
+}
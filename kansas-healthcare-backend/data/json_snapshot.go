@@ -0,0 +1,351 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"kansas-healthcare-api/config"
+	"kansas-healthcare-api/models"
+	"time"
+)
+
+// Dataset file names inside the data directory
+const (
+	providersFile                 = "providers.json"
+	providerNetworksFile          = "provider_networks.json"
+	providerServiceLocationsFile  = "provider_service_locations.json"
+	claimsFile                    = "claims.json"
+	countyAreasFile               = "county_areas.json"
+	specialtyDensityStandardsFile = "specialty_density_standards.json"
+	adequacyStandardsFile         = "adequacy_standards.json"
+	countyDesignationsFile        = "county_designations.json"
+	countyBoundariesFile          = "county_boundaries.json"
+	countyPopulationFile          = "county_population.json"
+	zipCentroidsFile              = "zip_centroids.json"
+	cityCentroidsFile             = "city_centroids.json"
+	outOfStateLocationsFile       = "out_of_state_locations.json"
+)
+
+// dataFiles lists every file a snapshot is built from, in load order
+var dataFiles = []string{
+	providersFile,
+	providerNetworksFile,
+	providerServiceLocationsFile,
+	claimsFile,
+	countyAreasFile,
+	specialtyDensityStandardsFile,
+	adequacyStandardsFile,
+	countyDesignationsFile,
+	countyBoundariesFile,
+	countyPopulationFile,
+	zipCentroidsFile,
+	cityCentroidsFile,
+	outOfStateLocationsFile,
+}
+
+// optionalDataFiles can be skipped in degraded mode; analytics fall back to defaults without them
+var optionalDataFiles = map[string]bool{
+	countyAreasFile:               true,
+	specialtyDensityStandardsFile: true,
+	adequacyStandardsFile:         true,
+	countyDesignationsFile:        true,
+	countyBoundariesFile:          true,
+	countyPopulationFile:          true,
+	zipCentroidsFile:              true,
+	cityCentroidsFile:             true,
+	outOfStateLocationsFile:       true,
+}
+
+// supplementalDataFiles load as empty when they are missing from the data directory, since most
+// deployments have nothing to put in them. A location configured explicitly must still exist.
+var supplementalDataFiles = map[string]bool{
+	outOfStateLocationsFile: true,
+}
+
+// neighboringStates holds the states out-of-state service locations may lie in
+var neighboringStates = map[string]bool{
+	config.StateColorado: true,
+	config.StateMissouri: true,
+	config.StateNebraska: true,
+	config.StateOklahoma: true,
+}
+
+// validDesignations holds the county designations adequacy standards are set for
+var validDesignations = map[string]bool{
+	config.DesignationLargeMetro: true,
+	config.DesignationMetro:      true,
+	config.DesignationMicro:      true,
+	config.DesignationRural:      true,
+	config.DesignationCEAC:       true,
+}
+
+// jsonSnapshot is one fully loaded, validated and indexed copy of the datasets. It is never
+// mutated once published, so readers can use it without locking.
+type jsonSnapshot struct {
+	providers                 []models.Provider
+	providerNetwork           []models.ProviderNetwork
+	providerServiceLocations  []models.ProviderServiceLocation
+	countyClaims              []models.CountyClaims
+	countyAreas               []models.CountyArea
+	specialtyDensityStandards map[string]float64
+	adequacyStandards         []models.AdequacyStandard
+	countyDesignations        []models.CountyDesignation
+	countyBoundaries          []models.CountyBoundary
+	countyPopulation          []models.CountyPopulation
+	zipCentroids              []models.ZipCentroid
+	cityCentroids             []models.CityCentroid
+	outOfStateLocations       []models.OutOfStateLocation
+
+	// degraded holds the load errors of optional datasets that were skipped
+	degraded []*LoadError
+	// paths records where each dataset was read from; nil for in-memory snapshots
+	paths map[string]string
+
+	idx *jsonIndex
+}
+
+// loadSnapshot reads every dataset, validates the result and builds its indexes
+func loadSnapshot(opts LoadOptions) (*jsonSnapshot, error) {
+	s := &jsonSnapshot{}
+	loaders := map[string]func(path string, body []byte) error{
+		providersFile:                func(path string, body []byte) error { return loadRecords(path, body, &s.providers) },
+		providerNetworksFile:         func(path string, body []byte) error { return loadRecords(path, body, &s.providerNetwork) },
+		providerServiceLocationsFile: func(path string, body []byte) error { return loadRecords(path, body, &s.providerServiceLocations) },
+		claimsFile:                   func(path string, body []byte) error { return loadRecords(path, body, &s.countyClaims) },
+		countyAreasFile:              func(path string, body []byte) error { return loadRecords(path, body, &s.countyAreas) },
+		specialtyDensityStandardsFile: func(path string, body []byte) error {
+			return loadObject(path, body, &s.specialtyDensityStandards)
+		},
+		adequacyStandardsFile:  func(path string, body []byte) error { return loadRecords(path, body, &s.adequacyStandards) },
+		countyDesignationsFile: func(path string, body []byte) error { return loadRecords(path, body, &s.countyDesignations) },
+		countyBoundariesFile:   func(path string, body []byte) error { return loadCountyBoundaries(path, body, &s.countyBoundaries) },
+		countyPopulationFile:   func(path string, body []byte) error { return loadRecords(path, body, &s.countyPopulation) },
+		zipCentroidsFile:       func(path string, body []byte) error { return loadRecords(path, body, &s.zipCentroids) },
+		cityCentroidsFile:      func(path string, body []byte) error { return loadRecords(path, body, &s.cityCentroids) },
+		outOfStateLocationsFile: func(path string, body []byte) error {
+			return loadRecords(path, body, &s.outOfStateLocations)
+		},
+	}
+
+	paths := make(map[string]string, len(dataFiles))
+	for _, name := range dataFiles {
+		path, body, err := opts.readFile(name)
+		paths[name] = path
+		if supplementalDataFiles[name] && opts.Files[name] == "" && errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err == nil {
+			err = loaders[name](path, body)
+		}
+		if err == nil {
+			continue
+		}
+		var loadErr *LoadError
+		if opts.AllowDegraded && optionalDataFiles[name] && errors.As(err, &loadErr) {
+			s.degraded = append(s.degraded, loadErr)
+			continue
+		}
+		return nil, err
+	}
+	if s.specialtyDensityStandards == nil {
+		s.specialtyDensityStandards = map[string]float64{}
+	}
+	assignLocationIDs(s.providerServiceLocations)
+	s.paths = paths
+
+	if err := s.validate(paths); err != nil {
+		return nil, err
+	}
+	geocodeLocations(NewGeocoder(s.zipCentroids, s.cityCentroids), s.providerServiceLocations)
+	s.idx = buildJSONIndex(s)
+	return s, nil
+}
+
+// validate rejects datasets that parse but would serve obviously broken results, such as a
+// truncated roster or rows missing their keys. paths maps each dataset to where it was read from.
+func (s *jsonSnapshot) validate(paths map[string]string) error {
+	path := func(name string) string { return paths[name] }
+
+	if len(s.providers) == 0 {
+		return fileError(path(providersFile), errors.New("contains no providers"))
+	}
+	if len(s.countyClaims) == 0 {
+		return fileError(path(claimsFile), errors.New("contains no county claims"))
+	}
+
+	providerIds := make(map[string]bool, len(s.providers))
+	for i, provider := range s.providers {
+		if provider.ProviderID == "" {
+			return recordError(path(providersFile), i, "provider_id", errors.New("is required"))
+		}
+		if providerIds[provider.ProviderID] {
+			return recordError(path(providersFile), i, "provider_id", fmt.Errorf("duplicates provider_id %s", provider.ProviderID))
+		}
+		providerIds[provider.ProviderID] = true
+	}
+	// A provider may hold several affiliations with a network, one per spell, but not two
+	// starting on the same day
+	type affiliation struct {
+		providerID, networkID string
+		effectiveDate         time.Time
+	}
+	affiliations := make(map[affiliation]bool, len(s.providerNetwork))
+	for i, network := range s.providerNetwork {
+		if network.ProviderID == "" {
+			return recordError(path(providerNetworksFile), i, "provider_id", errors.New("is required"))
+		}
+		if network.NetworkID == "" {
+			return recordError(path(providerNetworksFile), i, "network_id", errors.New("is required"))
+		}
+		key := affiliation{network.ProviderID, network.NetworkID, network.EffectiveDate.UTC()}
+		if affiliations[key] {
+			return recordError(path(providerNetworksFile), i, "effective_date",
+				fmt.Errorf("duplicates network_id %s for provider_id %s from %s", network.NetworkID, network.ProviderID,
+					network.EffectiveDate.UTC().Format(config.AsOfDateFormat)))
+		}
+		affiliations[key] = true
+	}
+	locationIds := make(map[string]bool, len(s.providerServiceLocations))
+	for i, location := range s.providerServiceLocations {
+		if location.ProviderID == "" {
+			return recordError(path(providerServiceLocationsFile), i, "provider_id", errors.New("is required"))
+		}
+		if locationIds[location.LocationID] {
+			return recordError(path(providerServiceLocationsFile), i, "location_id", fmt.Errorf("duplicates location_id %s", location.LocationID))
+		}
+		locationIds[location.LocationID] = true
+	}
+	for i, claims := range s.countyClaims {
+		if claims.County == "" {
+			return recordError(path(claimsFile), i, "county", errors.New("is required"))
+		}
+	}
+	standards := make(map[[2]string]bool, len(s.adequacyStandards))
+	for i, standard := range s.adequacyStandards {
+		if standard.Specialty == "" {
+			return recordError(path(adequacyStandardsFile), i, "specialty", errors.New("is required"))
+		}
+		if !validDesignations[standard.Designation] {
+			return recordError(path(adequacyStandardsFile), i, "designation", fmt.Errorf("%q is not a county designation", standard.Designation))
+		}
+		if standard.MaxMiles <= 0 {
+			return recordError(path(adequacyStandardsFile), i, "max_miles", errors.New("must be positive"))
+		}
+		key := [2]string{standard.Specialty, standard.Designation}
+		if standards[key] {
+			return recordError(path(adequacyStandardsFile), i, "designation",
+				fmt.Errorf("duplicates the %s standard for %s", standard.Designation, standard.Specialty))
+		}
+		standards[key] = true
+	}
+	designated := make(map[string]bool, len(s.countyDesignations))
+	for i, designation := range s.countyDesignations {
+		if designation.County == "" {
+			return recordError(path(countyDesignationsFile), i, "county", errors.New("is required"))
+		}
+		if !validDesignations[designation.Designation] {
+			return recordError(path(countyDesignationsFile), i, "designation", fmt.Errorf("%q is not a county designation", designation.Designation))
+		}
+		if designated[designation.County] {
+			return recordError(path(countyDesignationsFile), i, "county", fmt.Errorf("duplicates county %s", designation.County))
+		}
+		designated[designation.County] = true
+	}
+	bounded := make(map[string]bool, len(s.countyBoundaries))
+	for i, boundary := range s.countyBoundaries {
+		if boundary.County == "" {
+			return recordError(path(countyBoundariesFile), i, "county", errors.New("is required"))
+		}
+		if bounded[boundary.County] {
+			return recordError(path(countyBoundariesFile), i, "county", fmt.Errorf("duplicates county %s", boundary.County))
+		}
+		bounded[boundary.County] = true
+		if len(boundary.Polygons) == 0 {
+			return recordError(path(countyBoundariesFile), i, "geometry", errors.New("has no polygons"))
+		}
+		for _, polygon := range boundary.Polygons {
+			if err := validatePolygon(polygon); err != nil {
+				return recordError(path(countyBoundariesFile), i, "geometry", err)
+			}
+		}
+	}
+	populated := make(map[string]bool, len(s.countyPopulation))
+	for i, population := range s.countyPopulation {
+		if population.County == "" {
+			return recordError(path(countyPopulationFile), i, "county", errors.New("is required"))
+		}
+		if population.Population < 0 {
+			return recordError(path(countyPopulationFile), i, "population", errors.New("must not be negative"))
+		}
+		if populated[population.County] {
+			return recordError(path(countyPopulationFile), i, "county", fmt.Errorf("duplicates county %s", population.County))
+		}
+		populated[population.County] = true
+	}
+	zips := make(map[string]bool, len(s.zipCentroids))
+	for i, zip := range s.zipCentroids {
+		if !ValidZipCode(zip.ZipCode) {
+			return recordError(path(zipCentroidsFile), i, "zip_code", errors.New("must be 5 digits"))
+		}
+		if zips[zip.ZipCode] {
+			return recordError(path(zipCentroidsFile), i, "zip_code", fmt.Errorf("duplicates zip_code %s", zip.ZipCode))
+		}
+		zips[zip.ZipCode] = true
+		if zip.County == "" {
+			return recordError(path(zipCentroidsFile), i, "county", errors.New("is required"))
+		}
+		if outsideKansas(zip.Latitude, zip.Longitude) {
+			return recordError(path(zipCentroidsFile), i, "latitude", errors.New("and longitude must fall within Kansas"))
+		}
+	}
+	cities := make(map[[2]string]bool, len(s.cityCentroids))
+	for i, city := range s.cityCentroids {
+		if city.City == "" {
+			return recordError(path(cityCentroidsFile), i, "city", errors.New("is required"))
+		}
+		if city.County == "" {
+			return recordError(path(cityCentroidsFile), i, "county", errors.New("is required"))
+		}
+		key := [2]string{cityKey(city.City), cityKey(city.County)}
+		if cities[key] {
+			return recordError(path(cityCentroidsFile), i, "city", fmt.Errorf("duplicates %s in %s county", city.City, city.County))
+		}
+		cities[key] = true
+		if outsideKansas(city.Latitude, city.Longitude) {
+			return recordError(path(cityCentroidsFile), i, "latitude", errors.New("and longitude must fall within Kansas"))
+		}
+	}
+	outOfStateIds := make(map[string]bool, len(s.outOfStateLocations))
+	for i, location := range s.outOfStateLocations {
+		if location.LocationID == "" {
+			return recordError(path(outOfStateLocationsFile), i, "location_id", errors.New("is required"))
+		}
+		if outOfStateIds[location.LocationID] || locationIds[location.LocationID] {
+			return recordError(path(outOfStateLocationsFile), i, "location_id", fmt.Errorf("duplicates location_id %s", location.LocationID))
+		}
+		outOfStateIds[location.LocationID] = true
+		if location.ProviderID == "" {
+			return recordError(path(outOfStateLocationsFile), i, "provider_id", errors.New("is required"))
+		}
+		if location.ProviderType == "" {
+			return recordError(path(outOfStateLocationsFile), i, "provider_type", errors.New("is required"))
+		}
+		if !neighboringStates[location.State] {
+			return recordError(path(outOfStateLocationsFile), i, "state", fmt.Errorf("%q is not a state bordering Kansas", location.State))
+		}
+		if location.Latitude == 0 && location.Longitude == 0 {
+			return recordError(path(outOfStateLocationsFile), i, "latitude", errors.New("and longitude are required"))
+		}
+		networks := make(map[string]bool, len(location.Networks))
+		for _, network := range location.Networks {
+			if network.NetworkID == "" {
+				return recordError(path(outOfStateLocationsFile), i, "networks", errors.New("network_id is required"))
+			}
+			if networks[network.NetworkID] {
+				return recordError(path(outOfStateLocationsFile), i, "networks", fmt.Errorf("duplicates network_id %s", network.NetworkID))
+			}
+			networks[network.NetworkID] = true
+		}
+	}
+	return nil
+}
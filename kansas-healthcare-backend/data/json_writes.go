@@ -0,0 +1,310 @@
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"kansas-healthcare-api/models"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// write applies change to a copy of the current snapshot, validates and indexes the result,
+// persists the changed dataset and only then publishes it. Writers hold reloadMu, so they are
+// serialized with each other and with reloads, and a failed write leaves both the served snapshot
+// and the file untouched. change must copy any slice it modifies: the current snapshot is shared
+// with in-flight readers.
+func (r *JSONRepository) write(file string, change func(cur, next *jsonSnapshot) error) error {
+	r.reloadMu.Lock()
+	defer r.reloadMu.Unlock()
+
+	cur := r.snapshot()
+	next := *cur
+	next.idx = nil
+	if err := change(cur, &next); err != nil {
+		return err
+	}
+	if err := next.validate(next.paths); err != nil {
+		return err
+	}
+	next.idx = buildJSONIndex(&next)
+
+	if err := r.persist(&next, file); err != nil {
+		return err
+	}
+	r.current.Store(&next)
+	return nil
+}
+
+// persist writes one dataset of s back to disk atomically. Datasets served from the embedded copy
+// are written to where the file would be read from on disk, which takes precedence from then on.
+func (r *JSONRepository) persist(s *jsonSnapshot, file string) error {
+	if s.paths == nil {
+		return nil
+	}
+
+	var records interface{}
+	switch file {
+	case providersFile:
+		records = s.providers
+	case providerNetworksFile:
+		records = s.providerNetwork
+	case providerServiceLocationsFile:
+		records = s.providerServiceLocations
+	default:
+		return fmt.Errorf("dataset %s is read-only", file)
+	}
+	body, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	path := s.paths[file]
+	if path == "" || strings.HasPrefix(path, embeddedPrefix) {
+		path = r.opts.path(file)
+	}
+	if err := writeFileAtomic(path, append(body, '\n')); err != nil {
+		return fileError(path, err)
+	}
+
+	paths := make(map[string]string, len(s.paths))
+	for name, p := range s.paths {
+		paths[name] = p
+	}
+	paths[file] = path
+	s.paths = paths
+
+	// Record the new stamp so the watcher does not reload what was just written
+	if info, err := os.Stat(path); err == nil {
+		stamp := fileStamp{modTime: info.ModTime(), size: info.Size()}
+		r.loadedStamps = withStamp(r.loadedStamps, file, stamp)
+		r.attemptedStamps = withStamp(r.attemptedStamps, file, stamp)
+	}
+	return nil
+}
+
+func withStamp(stamps map[string]fileStamp, file string, stamp fileStamp) map[string]fileStamp {
+	updated := make(map[string]fileStamp, len(stamps)+1)
+	for name, s := range stamps {
+		updated[name] = s
+	}
+	updated[file] = stamp
+	return updated
+}
+
+// writeFileAtomic replaces path with body via a temporary file and rename, so readers and the
+// watcher never observe a partially written file
+func writeFileAtomic(path string, body []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(body); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// clone copies a slice so it can be modified without affecting published snapshots
+func clone[T any](records []T) []T {
+	return append([]T(nil), records...)
+}
+
+func (r *JSONRepository) GetProvider(providerId string) (*models.Provider, error) {
+	s := r.snapshot()
+	i, ok := s.idx.providersByID[providerId]
+	if !ok {
+		return nil, fmt.Errorf("provider %s %w", providerId, ErrNotFound)
+	}
+	provider := s.providers[i]
+	return &provider, nil
+}
+
+func (r *JSONRepository) CreateProvider(provider models.Provider) error {
+	return r.write(providersFile, func(cur, next *jsonSnapshot) error {
+		if _, exists := cur.idx.providersByID[provider.ProviderID]; exists {
+			return fmt.Errorf("provider %s %w", provider.ProviderID, ErrAlreadyExists)
+		}
+		next.providers = append(clone(cur.providers), provider)
+		return nil
+	})
+}
+
+func (r *JSONRepository) UpdateProvider(provider models.Provider, version string) error {
+	return r.write(providersFile, func(cur, next *jsonSnapshot) error {
+		i, ok := cur.idx.providersByID[provider.ProviderID]
+		if !ok {
+			return fmt.Errorf("provider %s %w", provider.ProviderID, ErrNotFound)
+		}
+		if err := checkVersion("provider "+provider.ProviderID, cur.providers[i], version); err != nil {
+			return err
+		}
+		next.providers = clone(cur.providers)
+		next.providers[i] = provider
+		return nil
+	})
+}
+
+// DeleteProvider removes a provider that no longer has network affiliations or service locations
+func (r *JSONRepository) DeleteProvider(providerId, version string) error {
+	return r.write(providersFile, func(cur, next *jsonSnapshot) error {
+		i, ok := cur.idx.providersByID[providerId]
+		if !ok {
+			return fmt.Errorf("provider %s %w", providerId, ErrNotFound)
+		}
+		if err := checkVersion("provider "+providerId, cur.providers[i], version); err != nil {
+			return err
+		}
+		if len(cur.idx.networksByProvider[providerId]) > 0 || len(cur.idx.locationsByProvider[providerId]) > 0 {
+			return fmt.Errorf("provider %s %w by network affiliations or service locations", providerId, ErrInUse)
+		}
+		next.providers = append(clone(cur.providers[:i]), cur.providers[i+1:]...)
+		return nil
+	})
+}
+
+// findProviderNetwork returns the index of a provider's affiliation with a network starting on
+// effectiveDate, or -1
+func (s *jsonSnapshot) findProviderNetwork(providerId, networkId string, effectiveDate time.Time) int {
+	for _, i := range s.idx.networksByProvider[providerId] {
+		if s.providerNetwork[i].NetworkID == networkId && s.providerNetwork[i].EffectiveDate.Equal(effectiveDate) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (r *JSONRepository) GetProviderNetwork(providerId, networkId string, effectiveDate time.Time) (*models.ProviderNetwork, error) {
+	s := r.snapshot()
+	i := s.findProviderNetwork(providerId, networkId, effectiveDate)
+	if i < 0 {
+		return nil, fmt.Errorf("%s %w", affiliationKey(providerId, networkId, effectiveDate), ErrNotFound)
+	}
+	network := s.providerNetwork[i]
+	return &network, nil
+}
+
+func (r *JSONRepository) CreateProviderNetwork(network models.ProviderNetwork) error {
+	return r.write(providerNetworksFile, func(cur, next *jsonSnapshot) error {
+		if _, ok := cur.idx.providersByID[network.ProviderID]; !ok {
+			return fmt.Errorf("provider %s %w", network.ProviderID, ErrNotFound)
+		}
+		if cur.findProviderNetwork(network.ProviderID, network.NetworkID, network.EffectiveDate) >= 0 {
+			return fmt.Errorf("%s %w", affiliationKey(network.ProviderID, network.NetworkID, network.EffectiveDate), ErrAlreadyExists)
+		}
+		next.providerNetwork = append(clone(cur.providerNetwork), network)
+		return nil
+	})
+}
+
+func (r *JSONRepository) UpdateProviderNetwork(effectiveDate time.Time, network models.ProviderNetwork, version string) error {
+	return r.write(providerNetworksFile, func(cur, next *jsonSnapshot) error {
+		key := affiliationKey(network.ProviderID, network.NetworkID, effectiveDate)
+		i := cur.findProviderNetwork(network.ProviderID, network.NetworkID, effectiveDate)
+		if i < 0 {
+			return fmt.Errorf("%s %w", key, ErrNotFound)
+		}
+		if err := checkVersion(key, cur.providerNetwork[i], version); err != nil {
+			return err
+		}
+		if !network.EffectiveDate.Equal(effectiveDate) && cur.findProviderNetwork(network.ProviderID, network.NetworkID, network.EffectiveDate) >= 0 {
+			return fmt.Errorf("%s %w", affiliationKey(network.ProviderID, network.NetworkID, network.EffectiveDate), ErrAlreadyExists)
+		}
+		next.providerNetwork = clone(cur.providerNetwork)
+		next.providerNetwork[i] = network
+		return nil
+	})
+}
+
+func (r *JSONRepository) DeleteProviderNetwork(providerId, networkId string, effectiveDate time.Time, version string) error {
+	return r.write(providerNetworksFile, func(cur, next *jsonSnapshot) error {
+		key := affiliationKey(providerId, networkId, effectiveDate)
+		i := cur.findProviderNetwork(providerId, networkId, effectiveDate)
+		if i < 0 {
+			return fmt.Errorf("%s %w", key, ErrNotFound)
+		}
+		if err := checkVersion(key, cur.providerNetwork[i], version); err != nil {
+			return err
+		}
+		next.providerNetwork = append(clone(cur.providerNetwork[:i]), cur.providerNetwork[i+1:]...)
+		return nil
+	})
+}
+
+func (r *JSONRepository) GetServiceLocation(locationId string) (*models.ProviderServiceLocation, error) {
+	s := r.snapshot()
+	i, ok := s.idx.locationsByID[locationId]
+	if !ok {
+		return nil, fmt.Errorf("service location %s %w", locationId, ErrNotFound)
+	}
+	location := s.providerServiceLocations[i]
+	return &location, nil
+}
+
+// CreateServiceLocation adds a service location, assigning it the next free location ID of its provider
+func (r *JSONRepository) CreateServiceLocation(location models.ProviderServiceLocation) (*models.ProviderServiceLocation, error) {
+	err := r.write(providerServiceLocationsFile, func(cur, next *jsonSnapshot) error {
+		if _, ok := cur.idx.providersByID[location.ProviderID]; !ok {
+			return fmt.Errorf("provider %s %w", location.ProviderID, ErrNotFound)
+		}
+		location.LocationID = nextLocationID(location.ProviderID, func(id string) bool {
+			_, taken := cur.idx.locationsByID[id]
+			return taken
+		})
+		next.providerServiceLocations = append(clone(cur.providerServiceLocations), location)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &location, nil
+}
+
+func (r *JSONRepository) UpdateServiceLocation(location models.ProviderServiceLocation, version string) error {
+	return r.write(providerServiceLocationsFile, func(cur, next *jsonSnapshot) error {
+		key := "service location " + location.LocationID
+		i, ok := cur.idx.locationsByID[location.LocationID]
+		if !ok {
+			return fmt.Errorf("%s %w", key, ErrNotFound)
+		}
+		if err := checkVersion(key, cur.providerServiceLocations[i], version); err != nil {
+			return err
+		}
+		if _, ok := cur.idx.providersByID[location.ProviderID]; !ok {
+			return fmt.Errorf("provider %s %w", location.ProviderID, ErrNotFound)
+		}
+		next.providerServiceLocations = clone(cur.providerServiceLocations)
+		next.providerServiceLocations[i] = location
+		return nil
+	})
+}
+
+func (r *JSONRepository) DeleteServiceLocation(locationId, version string) error {
+	return r.write(providerServiceLocationsFile, func(cur, next *jsonSnapshot) error {
+		key := "service location " + locationId
+		i, ok := cur.idx.locationsByID[locationId]
+		if !ok {
+			return fmt.Errorf("%s %w", key, ErrNotFound)
+		}
+		if err := checkVersion(key, cur.providerServiceLocations[i], version); err != nil {
+			return err
+		}
+		next.providerServiceLocations = append(clone(cur.providerServiceLocations[:i]), cur.providerServiceLocations[i+1:]...)
+		return nil
+	})
+}
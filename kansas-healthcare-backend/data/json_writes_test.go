@@ -0,0 +1,181 @@
+package data
+
+import (
+	"kansas-healthcare-api/models"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newWritableTestRepository(t *testing.T) (*JSONRepository, string) {
+	dir := t.TempDir()
+	writeTestDataset(t, dir, []models.Provider{
+		{ProviderID: "P1", NPI: "1000000001", ProviderType: "Primary Care", Status: "Active", County: "Sedgwick"},
+	})
+	repo, err := NewJSONRepository(LoadOptions{DataDir: dir})
+	require.NoError(t, err)
+	return repo, dir
+}
+
+func TestJSONProviderWritesArePersisted(t *testing.T) {
+	repo, dir := newWritableTestRepository(t)
+
+	provider := models.Provider{ProviderID: "P2", NPI: "1000000002", ProviderType: "Cardiology", Status: "Active", County: "Sedgwick"}
+	require.NoError(t, repo.CreateProvider(provider))
+	assert.ErrorIs(t, repo.CreateProvider(provider), ErrAlreadyExists)
+
+	count, _ := repo.GetActiveProviderCount()
+	assert.Equal(t, 2, count)
+
+	reloaded, err := repo.reloadIfChanged()
+	assert.NoError(t, err)
+	assert.False(t, reloaded, "the watcher must not reload a file the repository just wrote")
+
+	fresh, err := NewJSONRepository(LoadOptions{DataDir: dir})
+	require.NoError(t, err)
+	stored, err := fresh.GetProvider("P2")
+	require.NoError(t, err)
+	assert.Equal(t, provider, *stored)
+}
+
+func TestJSONUpdateRequiresCurrentVersion(t *testing.T) {
+	repo, _ := newWritableTestRepository(t)
+
+	current, err := repo.GetProvider("P1")
+	require.NoError(t, err)
+	version := RecordVersion(current)
+
+	updated := *current
+	updated.Status = "Terminated"
+	require.NoError(t, repo.UpdateProvider(updated, version))
+
+	// A second writer still holding the old version is rejected
+	stale := *current
+	stale.County = "Butler"
+	assert.ErrorIs(t, repo.UpdateProvider(stale, version), ErrVersionMismatch)
+
+	stored, _ := repo.GetProvider("P1")
+	assert.Equal(t, "Terminated", stored.Status)
+	count, _ := repo.GetActiveProviderCount()
+	assert.Equal(t, 0, count, "indexes are rebuilt after a write")
+}
+
+func TestJSONDeleteProviderInUse(t *testing.T) {
+	repo, _ := newWritableTestRepository(t)
+	open := time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	network := models.ProviderNetwork{ProviderID: "P1", NetworkID: "Commercial", EffectiveDate: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), TerminationDate: open}
+	require.NoError(t, repo.CreateProviderNetwork(network))
+	assert.ErrorIs(t, repo.CreateProviderNetwork(network), ErrAlreadyExists)
+
+	provider, _ := repo.GetProvider("P1")
+	assert.ErrorIs(t, repo.DeleteProvider("P1", RecordVersion(provider)), ErrInUse)
+
+	require.NoError(t, repo.DeleteProviderNetwork("P1", "Commercial", network.EffectiveDate, RecordVersion(network)))
+	// The roster may never become empty, as with a truncated providers.json
+	assert.ErrorContains(t, repo.DeleteProvider("P1", RecordVersion(provider)), "contains no providers")
+
+	require.NoError(t, repo.CreateProvider(models.Provider{ProviderID: "P2", NPI: "1000000002", ProviderType: "Cardiology", Status: "Active", County: "Sedgwick"}))
+	require.NoError(t, repo.DeleteProvider("P1", RecordVersion(provider)))
+	_, err := repo.GetProvider("P1")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestJSONServiceLocationWrites(t *testing.T) {
+	repo, dir := newWritableTestRepository(t)
+
+	location := models.ProviderServiceLocation{
+		ProviderID: "P1", County: "Sedgwick", Latitude: 37.69, Longitude: -97.34,
+		EffectiveDate: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), TerminationDate: time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC),
+	}
+	created, err := repo.CreateServiceLocation(location)
+	require.NoError(t, err)
+	assert.Equal(t, "P1-1", created.LocationID)
+
+	second, err := repo.CreateServiceLocation(location)
+	require.NoError(t, err)
+	assert.Equal(t, "P1-2", second.LocationID)
+
+	moved := *created
+	moved.City = "Wichita"
+	require.NoError(t, repo.UpdateServiceLocation(moved, RecordVersion(created)))
+	assert.ErrorIs(t, repo.DeleteServiceLocation(created.LocationID, RecordVersion(created)), ErrVersionMismatch)
+	require.NoError(t, repo.DeleteServiceLocation(created.LocationID, RecordVersion(moved)))
+
+	orphan := location
+	orphan.ProviderID = "P404"
+	_, err = repo.CreateServiceLocation(orphan)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	body, err := os.ReadFile(filepath.Join(dir, providerServiceLocationsFile))
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `"location_id": "P1-2"`)
+	assert.NotContains(t, string(body), `"location_id": "P1-1"`)
+}
+
+func TestLocationIDsAreAssignedOnLoad(t *testing.T) {
+	locations := []models.ProviderServiceLocation{
+		{ProviderID: "P1"},
+		{ProviderID: "P1", LocationID: "P1-1"},
+		{ProviderID: "P2"},
+	}
+	assignLocationIDs(locations)
+	assert.Equal(t, "P1-2", locations[0].LocationID)
+	assert.Equal(t, "P1-1", locations[1].LocationID)
+	assert.Equal(t, "P2-1", locations[2].LocationID)
+}
+
+// assertProviderNetworkSpells checks that each of two spells of provider P1 in a network is read,
+// updated and deleted on its own
+func assertProviderNetworkSpells(t *testing.T, repo Repository) {
+	first := models.ProviderNetwork{ProviderID: "P1", NetworkID: "Medicaid", EffectiveDate: time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC),
+		TerminationDate: time.Date(2019, 6, 30, 0, 0, 0, 0, time.UTC), TerminationReason: "Left Network"}
+	second := models.ProviderNetwork{ProviderID: "P1", NetworkID: "Medicaid", EffectiveDate: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+		TerminationDate: time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)}
+	require.NoError(t, repo.CreateProviderNetwork(first))
+	require.NoError(t, repo.CreateProviderNetwork(second))
+	assert.ErrorIs(t, repo.CreateProviderNetwork(second), ErrAlreadyExists)
+
+	stored, err := repo.GetProviderNetwork("P1", "Medicaid", second.EffectiveDate)
+	require.NoError(t, err)
+	assert.Equal(t, RecordVersion(second), RecordVersion(stored))
+	_, err = repo.GetProviderNetwork("P1", "Medicaid", time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// Each spell carries its own version, and a write touches only the spell it names
+	updated := first
+	updated.TerminationReason = "Contract Ended"
+	assert.ErrorIs(t, repo.UpdateProviderNetwork(first.EffectiveDate, updated, RecordVersion(second)), ErrVersionMismatch)
+	require.NoError(t, repo.UpdateProviderNetwork(first.EffectiveDate, updated, RecordVersion(first)))
+	stored, err = repo.GetProviderNetwork("P1", "Medicaid", second.EffectiveDate)
+	require.NoError(t, err)
+	assert.Equal(t, RecordVersion(second), RecordVersion(stored))
+
+	// Moving a spell's start onto another spell would duplicate the key
+	moved := second
+	moved.EffectiveDate = first.EffectiveDate
+	assert.ErrorIs(t, repo.UpdateProviderNetwork(second.EffectiveDate, moved, RecordVersion(second)), ErrAlreadyExists)
+
+	require.NoError(t, repo.DeleteProviderNetwork("P1", "Medicaid", first.EffectiveDate, RecordVersion(updated)))
+	_, err = repo.GetProviderNetwork("P1", "Medicaid", first.EffectiveDate)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = repo.GetProviderNetwork("P1", "Medicaid", second.EffectiveDate)
+	assert.NoError(t, err)
+}
+
+func TestJSONProviderNetworkSpells(t *testing.T) {
+	repo, dir := newWritableTestRepository(t)
+	assertProviderNetworkSpells(t, repo)
+
+	// A provider that left a network and rejoined it loads back from disk
+	require.NoError(t, repo.CreateProviderNetwork(models.ProviderNetwork{ProviderID: "P1", NetworkID: "Medicaid",
+		EffectiveDate: time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC), TerminationDate: time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)}))
+	fresh, err := NewJSONRepository(LoadOptions{DataDir: dir})
+	require.NoError(t, err)
+	networks, _ := fresh.GetProviderNetworks()
+	assert.Len(t, networks, 2)
+}
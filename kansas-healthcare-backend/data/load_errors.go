@@ -0,0 +1,187 @@
+package data
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LoadError pinpoints why a data file could not be loaded: the file, where in it the problem
+// sits, which top-level record was being decoded and which field, when those are known.
+type LoadError struct {
+	File   string
+	Line   int   // 1-based line of the offending JSON, 0 when unknown
+	Column int   // 1-based column of the offending JSON, 0 when unknown
+	Offset int64 // byte offset of the offending JSON, -1 when unknown
+	Record int   // index of the offending record in the top-level array, -1 when not applicable
+	Field  string
+	Err    error
+}
+
+func (e *LoadError) Error() string {
+	var b strings.Builder
+	b.WriteString(e.File)
+	if e.Line > 0 {
+		fmt.Fprintf(&b, ":%d:%d", e.Line, e.Column)
+	}
+	if e.Record >= 0 {
+		fmt.Fprintf(&b, " record %d", e.Record)
+	}
+	if e.Field != "" {
+		fmt.Fprintf(&b, " field %q", e.Field)
+	}
+	b.WriteString(": ")
+	b.WriteString(e.Err.Error())
+	return b.String()
+}
+
+func (e *LoadError) Unwrap() error {
+	return e.Err
+}
+
+// fileError is a LoadError that applies to a whole file rather than a position in it
+func fileError(path string, err error) *LoadError {
+	return &LoadError{File: path, Offset: -1, Record: -1, Err: err}
+}
+
+// recordError is a LoadError for a record that parsed but failed validation
+func recordError(path string, record int, field string, err error) *LoadError {
+	return &LoadError{File: path, Offset: -1, Record: record, Field: field, Err: err}
+}
+
+// positionError is a LoadError located at a byte offset within body
+func positionError(path string, body []byte, offset int64, record int, field string, err error) *LoadError {
+	line, column := lineAndColumn(body, offset)
+	return &LoadError{File: path, Line: line, Column: column, Offset: offset, Record: record, Field: field, Err: err}
+}
+
+func lineAndColumn(body []byte, offset int64) (int, int) {
+	if offset < 0 || offset > int64(len(body)) {
+		return 0, 0
+	}
+	prefix := body[:offset]
+	line := bytes.Count(prefix, []byte("\n")) + 1
+	column := len(prefix) - bytes.LastIndexByte(prefix, '\n')
+	return line, column
+}
+
+// skipSeparators advances past whitespace and the comma between array elements
+func skipSeparators(body []byte, offset int64) int64 {
+	for offset < int64(len(body)) {
+		switch body[offset] {
+		case ' ', '\t', '\r', '\n', ',':
+			offset++
+		default:
+			return offset
+		}
+	}
+	return offset
+}
+
+// loadRecords decodes a file holding a JSON array one element at a time, so a failure can be
+// reported with the index of the record and, where possible, the field that broke it
+func loadRecords[T any](path string, body []byte, target *[]T) error {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	token, err := dec.Token()
+	if err != nil {
+		return decodeError(path, body, 0, -1, err)
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return positionError(path, body, skipSeparators(body, 0), -1, "", errors.New("expected a JSON array of records"))
+	}
+
+	var records []T
+	for index := 0; dec.More(); index++ {
+		start := skipSeparators(body, dec.InputOffset())
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return decodeError(path, body, start, index, err)
+		}
+		var record T
+		if err := json.Unmarshal(raw, &record); err != nil {
+			field, offset := failingField[T](raw)
+			return positionError(path, body, start+offset, index, field, unwrapTypeError(err))
+		}
+		records = append(records, record)
+	}
+	if _, err := dec.Token(); err != nil {
+		return decodeError(path, body, dec.InputOffset(), -1, err)
+	}
+
+	*target = records
+	return nil
+}
+
+// loadObject decodes a file holding a single JSON object
+func loadObject(path string, body []byte, target interface{}) error {
+	if err := json.Unmarshal(body, target); err != nil {
+		return decodeError(path, body, 0, -1, err)
+	}
+	return nil
+}
+
+// decodeError converts an encoding/json error raised while reading body into a LoadError. Syntax
+// error offsets count from the start of body and point just past the offending byte; errors
+// without a position are reported at fallback.
+func decodeError(path string, body []byte, fallback int64, record int, err error) *LoadError {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		offset := syntaxErr.Offset - 1
+		if offset < 0 {
+			offset = 0
+		}
+		return positionError(path, body, offset, record, "", err)
+	case errors.As(err, &typeErr):
+		return positionError(path, body, typeErr.Offset, record, typeErr.Field, err)
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return positionError(path, body, int64(len(body)), record, "", errors.New("unexpected end of JSON input"))
+	}
+	return positionError(path, body, fallback, record, "", err)
+}
+
+// failingField finds which top-level field of a record cannot be decoded into T and the byte
+// offset of its value within raw. Errors raised by custom unmarshalers such as time.Time carry no
+// field information, so each field is tried on its own.
+func failingField[T any](raw json.RawMessage) (string, int64) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return "", 0
+	}
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		single, _ := json.Marshal(map[string]json.RawMessage{name: fields[name]})
+		var probe T
+		if err := json.Unmarshal(single, &probe); err != nil {
+			key := bytes.Index(raw, []byte(strconv.Quote(name)))
+			if key < 0 {
+				return name, 0
+			}
+			if value := bytes.Index(raw[key:], fields[name]); value >= 0 {
+				return name, int64(key + value)
+			}
+			return name, int64(key)
+		}
+	}
+	return "", 0
+}
+
+// unwrapTypeError drops the offset of a type error, which is relative to the record rather than the file
+func unwrapTypeError(err error) error {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return fmt.Errorf("cannot use JSON %s as %s", typeErr.Value, typeErr.Type)
+	}
+	return err
+}
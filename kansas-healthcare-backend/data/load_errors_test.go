@@ -0,0 +1,71 @@
+package data
+
+import (
+	"errors"
+	"io/fs"
+	"kansas-healthcare-api/models"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadReportsFieldOfMalformedRecord(t *testing.T) {
+	dir := t.TempDir()
+	writeTestDataset(t, dir, []models.Provider{{ProviderID: "P1", Status: "Active", County: "Sedgwick"}})
+	writeTestFile(t, filepath.Join(dir, providerNetworksFile), []byte(`[
+  {"provider_id": "P1", "network_id": "Commercial", "effective_date": "2020-01-01T00:00:00Z"},
+  {"provider_id": "P1", "network_id": "Medicare", "effective_date": "01/02/2020"}
+]`))
+
+	_, err := NewJSONRepository(LoadOptions{DataDir: dir})
+	var loadErr *LoadError
+	require.True(t, errors.As(err, &loadErr))
+	assert.Equal(t, filepath.Join(dir, providerNetworksFile), loadErr.File)
+	assert.Equal(t, 1, loadErr.Record)
+	assert.Equal(t, "effective_date", loadErr.Field)
+	assert.Equal(t, 3, loadErr.Line)
+	assert.Equal(t, 69, loadErr.Column)
+}
+
+func TestLoadReportsPositionOfSyntaxError(t *testing.T) {
+	dir := t.TempDir()
+	writeTestDataset(t, dir, []models.Provider{{ProviderID: "P1", Status: "Active", County: "Sedgwick"}})
+	writeTestFile(t, filepath.Join(dir, claimsFile), []byte("[\n  {\"county\": \"Sedgwick\"},\n  {\"county\": \"Butler\" \"claims_count\": 4}\n]"))
+
+	_, err := NewJSONRepository(LoadOptions{DataDir: dir})
+	var loadErr *LoadError
+	require.True(t, errors.As(err, &loadErr))
+	assert.Equal(t, 1, loadErr.Record)
+	assert.Equal(t, 3, loadErr.Line)
+	assert.Equal(t, 23, loadErr.Column)
+	assert.Contains(t, err.Error(), claimsFile+":3:23 record 1")
+}
+
+func TestLoadReportsMissingFile(t *testing.T) {
+	dir := t.TempDir()
+	writeTestDataset(t, dir, []models.Provider{{ProviderID: "P1", Status: "Active", County: "Sedgwick"}})
+	require.NoError(t, os.Remove(filepath.Join(dir, providersFile)))
+
+	_, err := NewJSONRepository(LoadOptions{DataDir: dir, AllowDegraded: true})
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+	assert.ErrorContains(t, err, providersFile)
+}
+
+func TestDegradedLoadSkipsOptionalDatasets(t *testing.T) {
+	dir := t.TempDir()
+	writeTestDataset(t, dir, []models.Provider{{ProviderID: "P1", Status: "Active", County: "Sedgwick"}})
+	require.NoError(t, os.Remove(filepath.Join(dir, countyAreasFile)))
+
+	_, err := NewJSONRepository(LoadOptions{DataDir: dir})
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+
+	repo, err := NewJSONRepository(LoadOptions{DataDir: dir, AllowDegraded: true})
+	require.NoError(t, err)
+	degraded := repo.ReloadStatus().Degraded
+	require.Len(t, degraded, 1)
+	assert.Contains(t, degraded[0], countyAreasFile)
+	assert.Equal(t, float64(defaultCountyAreaSqMiles), repo.GetCountyArea("Sedgwick"))
+}
@@ -0,0 +1,261 @@
+package data
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+)
+
+// migration is a single, append-only schema change. Applied versions are recorded in
+// schema_migrations, so released migrations must never be edited - add a new one instead.
+type migration struct {
+	version    int
+	name       string
+	statements []string
+}
+
+// migrations lists every schema version in order. The DDL sticks to the subset of SQL that
+// both SQLite and PostgreSQL accept.
+var migrations = []migration{
+	{
+		version: 1,
+		name:    "initial schema",
+		statements: []string{
+			`CREATE TABLE providers (
+				provider_id   VARCHAR(32) PRIMARY KEY,
+				npi           VARCHAR(16) NOT NULL,
+				provider_type VARCHAR(64) NOT NULL,
+				status        VARCHAR(32) NOT NULL,
+				county        VARCHAR(64) NOT NULL
+			)`,
+			`CREATE INDEX idx_providers_county_status ON providers (county, status)`,
+			`CREATE INDEX idx_providers_type ON providers (provider_type)`,
+			`CREATE TABLE provider_networks (
+				provider_id        VARCHAR(32) NOT NULL,
+				network_id         VARCHAR(32) NOT NULL,
+				effective_date     TIMESTAMP   NOT NULL,
+				termination_date   TIMESTAMP   NOT NULL,
+				termination_reason VARCHAR(64) NOT NULL DEFAULT '',
+				PRIMARY KEY (provider_id, network_id, effective_date)
+			)`,
+			`CREATE INDEX idx_provider_networks_network ON provider_networks (network_id, termination_reason, termination_date)`,
+			`CREATE TABLE provider_service_locations (
+				provider_id      VARCHAR(32)  NOT NULL,
+				effective_date   TIMESTAMP    NOT NULL,
+				termination_date TIMESTAMP    NOT NULL,
+				address1         VARCHAR(128) NOT NULL DEFAULT '',
+				address2         VARCHAR(128) NOT NULL DEFAULT '',
+				city             VARCHAR(64)  NOT NULL DEFAULT '',
+				zip_code         VARCHAR(10)  NOT NULL DEFAULT '',
+				county           VARCHAR(64)  NOT NULL,
+				latitude         DOUBLE PRECISION NOT NULL DEFAULT 0,
+				longitude        DOUBLE PRECISION NOT NULL DEFAULT 0
+			)`,
+			`CREATE INDEX idx_service_locations_provider ON provider_service_locations (provider_id)`,
+			`CREATE INDEX idx_service_locations_county ON provider_service_locations (county, termination_date)`,
+			`CREATE TABLE county_claims (
+				county           VARCHAR(64) PRIMARY KEY,
+				claims_count     INTEGER     NOT NULL,
+				avg_claim_amount DOUBLE PRECISION NOT NULL
+			)`,
+			`CREATE TABLE county_areas (
+				county        VARCHAR(64) PRIMARY KEY,
+				area_sq_miles DOUBLE PRECISION NOT NULL
+			)`,
+			`CREATE TABLE specialty_density_standards (
+				specialty             VARCHAR(64) PRIMARY KEY,
+				providers_per_sq_mile DOUBLE PRECISION NOT NULL
+			)`,
+		},
+	},
+	{
+		version: 2,
+		name:    "service location ids",
+		statements: []string{
+			// Rebuild the table with a primary key, numbering existing rows per provider the same
+			// way the JSON repository assigns IDs to locations loaded without one
+			`CREATE TABLE provider_service_locations_v2 (
+				location_id      VARCHAR(48)  PRIMARY KEY,
+				provider_id      VARCHAR(32)  NOT NULL,
+				effective_date   TIMESTAMP    NOT NULL,
+				termination_date TIMESTAMP    NOT NULL,
+				address1         VARCHAR(128) NOT NULL DEFAULT '',
+				address2         VARCHAR(128) NOT NULL DEFAULT '',
+				city             VARCHAR(64)  NOT NULL DEFAULT '',
+				zip_code         VARCHAR(10)  NOT NULL DEFAULT '',
+				county           VARCHAR(64)  NOT NULL,
+				latitude         DOUBLE PRECISION NOT NULL DEFAULT 0,
+				longitude        DOUBLE PRECISION NOT NULL DEFAULT 0
+			)`,
+			`INSERT INTO provider_service_locations_v2 (location_id, provider_id, effective_date, termination_date,
+				address1, address2, city, zip_code, county, latitude, longitude)
+			SELECT provider_id || '-' || CAST(ROW_NUMBER() OVER (PARTITION BY provider_id
+					ORDER BY effective_date, address1, address2, city, zip_code, county, latitude, longitude) AS VARCHAR(16)),
+				provider_id, effective_date, termination_date, address1, address2, city, zip_code, county, latitude, longitude
+			FROM provider_service_locations`,
+			`DROP TABLE provider_service_locations`,
+			`ALTER TABLE provider_service_locations_v2 RENAME TO provider_service_locations`,
+			`CREATE INDEX idx_service_locations_provider ON provider_service_locations (provider_id)`,
+			`CREATE INDEX idx_service_locations_county ON provider_service_locations (county, termination_date)`,
+		},
+	},
+	{
+		version: 3,
+		name:    "county centroids",
+		statements: []string{
+			`ALTER TABLE county_areas ADD COLUMN latitude DOUBLE PRECISION NOT NULL DEFAULT 0`,
+			`ALTER TABLE county_areas ADD COLUMN longitude DOUBLE PRECISION NOT NULL DEFAULT 0`,
+		},
+	},
+	{
+		version: 4,
+		name:    "service location coordinates index",
+		statements: []string{
+			// Serves the bounding box a radius search narrows to before computing exact distances
+			`CREATE INDEX idx_service_locations_coordinates ON provider_service_locations (latitude, longitude)`,
+		},
+	},
+	{
+		version: 5,
+		name:    "network adequacy standards",
+		statements: []string{
+			`CREATE TABLE adequacy_standards (
+				specialty   VARCHAR(64) NOT NULL,
+				designation VARCHAR(16) NOT NULL,
+				max_minutes INTEGER     NOT NULL,
+				max_miles   DOUBLE PRECISION NOT NULL,
+				PRIMARY KEY (specialty, designation)
+			)`,
+			`CREATE TABLE county_designations (
+				county      VARCHAR(64) PRIMARY KEY,
+				designation VARCHAR(16) NOT NULL
+			)`,
+		},
+	},
+	{
+		version: 6,
+		name:    "county boundaries",
+		statements: []string{
+			// geometry holds the boundary polygons as a GeoJSON MultiPolygon coordinates array
+			`CREATE TABLE county_boundaries (
+				county    VARCHAR(64) PRIMARY KEY,
+				latitude  DOUBLE PRECISION NOT NULL,
+				longitude DOUBLE PRECISION NOT NULL,
+				geometry  TEXT NOT NULL
+			)`,
+		},
+	},
+	{
+		version: 7,
+		name:    "county population",
+		statements: []string{
+			`CREATE TABLE county_population (
+				county     VARCHAR(64) PRIMARY KEY,
+				population INTEGER NOT NULL
+			)`,
+		},
+	},
+	{
+		version: 8,
+		name:    "offline geocoding",
+		statements: []string{
+			`ALTER TABLE provider_service_locations ADD COLUMN coordinate_source VARCHAR(16) NOT NULL DEFAULT ''`,
+			`CREATE TABLE zip_centroids (
+				zip_code  VARCHAR(10) PRIMARY KEY,
+				city      VARCHAR(64) NOT NULL,
+				county    VARCHAR(64) NOT NULL,
+				latitude  DOUBLE PRECISION NOT NULL,
+				longitude DOUBLE PRECISION NOT NULL
+			)`,
+			`CREATE TABLE city_centroids (
+				city      VARCHAR(64) NOT NULL,
+				county    VARCHAR(64) NOT NULL,
+				latitude  DOUBLE PRECISION NOT NULL,
+				longitude DOUBLE PRECISION NOT NULL,
+				PRIMARY KEY (city, county)
+			)`,
+		},
+	},
+	{
+		version: 9,
+		name:    "out-of-state providers",
+		statements: []string{
+			`CREATE TABLE out_of_state_locations (
+				location_id      VARCHAR(48)  PRIMARY KEY,
+				provider_id      VARCHAR(32)  NOT NULL,
+				npi              VARCHAR(16)  NOT NULL DEFAULT '',
+				provider_type    VARCHAR(64)  NOT NULL,
+				effective_date   TIMESTAMP    NOT NULL,
+				termination_date TIMESTAMP    NOT NULL,
+				address1         VARCHAR(128) NOT NULL DEFAULT '',
+				address2         VARCHAR(128) NOT NULL DEFAULT '',
+				city             VARCHAR(64)  NOT NULL DEFAULT '',
+				state            VARCHAR(2)   NOT NULL,
+				zip_code         VARCHAR(10)  NOT NULL DEFAULT '',
+				county           VARCHAR(64)  NOT NULL DEFAULT '',
+				latitude         DOUBLE PRECISION NOT NULL,
+				longitude        DOUBLE PRECISION NOT NULL
+			)`,
+			`CREATE INDEX idx_out_of_state_locations_coordinates ON out_of_state_locations (latitude, longitude)`,
+			`CREATE TABLE out_of_state_networks (
+				location_id      VARCHAR(48) NOT NULL,
+				network_id       VARCHAR(32) NOT NULL,
+				effective_date   TIMESTAMP   NOT NULL,
+				termination_date TIMESTAMP   NOT NULL,
+				PRIMARY KEY (location_id, network_id)
+			)`,
+		},
+	},
+}
+
+// migrate applies every migration newer than the recorded schema version, one transaction each
+func migrate(db *sql.DB, dialect string) error {
+	return applyMigrations(db, dialect, migrations)
+}
+
+// applyMigrations brings the schema up to the last of the given migrations
+func applyMigrations(db *sql.DB, dialect string, migrations []migration) error {
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		name       VARCHAR(128) NOT NULL,
+		applied_at TIMESTAMP    NOT NULL
+	)`); err != nil {
+		return fmt.Errorf("creating schema_migrations: %w", err)
+	}
+
+	var current int
+	if err := db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current); err != nil {
+		return fmt.Errorf("reading schema version: %w", err)
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		if err := applyMigration(db, dialect, m); err != nil {
+			return fmt.Errorf("migration %d (%s): %w", m.version, m.name, err)
+		}
+		log.Printf("[INFO] Applied schema migration %d: %s", m.version, m.name)
+	}
+	return nil
+}
+
+func applyMigration(db *sql.DB, dialect string, m migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, statement := range m.statements {
+		if _, err := tx.Exec(statement); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(rebind(dialect, `INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`),
+		m.version, m.name, time.Now().UTC()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
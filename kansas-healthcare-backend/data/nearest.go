@@ -0,0 +1,70 @@
+package data
+
+import (
+	"kansas-healthcare-api/models"
+	"math"
+	"sort"
+)
+
+// nearestSearchStartMiles is the first radius the SQL repository searches for nearest providers,
+// doubling it until enough locations turn up
+const nearestSearchStartMiles = 25
+
+// matchesSpecialty reports whether a provider type satisfies a query's specialty, "All" matching any
+func matchesSpecialty(providerType, specialty string) bool {
+	return specialty == "All" || providerType == specialty
+}
+
+// nearestMatch is a service location found by a nearest-provider search, with its exact distance.
+// state is set for out-of-state locations.
+type nearestMatch struct {
+	provider models.Provider
+	location models.ProviderServiceLocation
+	state    string
+	distance float64
+}
+
+// nearestResults returns the limit closest matches, nearest first with ties broken by location ID
+func nearestResults(matches []nearestMatch, limit int) []models.NearestProvider {
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].distance != matches[j].distance {
+			return matches[i].distance < matches[j].distance
+		}
+		return matches[i].location.LocationID < matches[j].location.LocationID
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	results := make([]models.NearestProvider, len(matches))
+	for i, m := range matches {
+		results[i] = models.NearestProvider{
+			ProviderLocation: providerLocation(m.provider, m.location),
+			DistanceMiles:    math.Round(m.distance*100) / 100,
+		}
+		results[i].State = m.state
+	}
+	return results
+}
+
+// sortProviderLocations orders locations by ID, so every repository lists them the same way
+func sortProviderLocations(locations []models.ProviderLocation) {
+	sort.Slice(locations, func(i, j int) bool { return locations[i].LocationID < locations[j].LocationID })
+}
+
+// providerLocation pairs a service location with the provider fields reported alongside it
+func providerLocation(provider models.Provider, location models.ProviderServiceLocation) models.ProviderLocation {
+	return models.ProviderLocation{
+		ProviderID: provider.ProviderID,
+		NPI:        provider.NPI,
+		Specialty:  provider.ProviderType,
+		LocationID: location.LocationID,
+		Address1:   location.Address1,
+		Address2:   location.Address2,
+		City:       location.City,
+		ZipCode:    location.ZipCode,
+		County:     location.County,
+		Latitude:   location.Latitude,
+		Longitude:  location.Longitude,
+	}
+}
@@ -0,0 +1,95 @@
+package data
+
+import (
+	"kansas-healthcare-api/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func locationIDs(providers []models.NearestProvider) []string {
+	ids := make([]string, len(providers))
+	for i, p := range providers {
+		ids[i] = p.LocationID
+	}
+	return ids
+}
+
+func TestGetNearestProviders(t *testing.T) {
+	repo := newRadiusFixture()
+	wichita := models.Coordinate{Latitude: 37.6872, Longitude: -97.3301}
+	query := models.NearestProviderQuery{Center: wichita, Specialty: "All", Network: "Commercial", Limit: 2, AsOf: time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)}
+
+	nearest, err := repo.GetNearestProviders(query)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"P1-1", "P1-2"}, locationIDs(nearest), "P5 is closer than P1-2 but out of network")
+	assert.Equal(t, "Primary Care", nearest[0].Specialty)
+	assert.Equal(t, 0.0, nearest[0].DistanceMiles)
+
+	query.Specialty = "Cardiology"
+	nearest, err = repo.GetNearestProviders(query)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"P2-1"}, locationIDs(nearest))
+
+	// Asking for more than there are returns every match, however far
+	query.Specialty, query.Limit = "All", 10
+	nearest, err = repo.GetNearestProviders(query)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"P1-1", "P1-2", "P2-1", "P3-1", "P4-1"}, locationIDs(nearest))
+
+	// Nothing is in force before the affiliations began
+	query.AsOf = time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)
+	nearest, err = repo.GetNearestProviders(query)
+	require.NoError(t, err)
+	assert.Empty(t, nearest)
+}
+
+func TestSQLNearestProvidersMatchJSON(t *testing.T) {
+	jsonRepo := newRadiusFixture()
+	sqlRepo := newSQLiteTestRepository(t, jsonRepo)
+	asOf := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+
+	for _, query := range []models.NearestProviderQuery{
+		{Center: models.Coordinate{Latitude: 37.6872, Longitude: -97.3301}, Specialty: "All", Network: "Commercial", Limit: 3, AsOf: asOf},
+		// Only found once the search has widened well beyond its first radius
+		{Center: models.Coordinate{Latitude: 39.0, Longitude: -101.5}, Specialty: "All", Network: "Commercial", Limit: 10, AsOf: asOf},
+		{Center: models.Coordinate{Latitude: 38.0, Longitude: -97.5}, Specialty: "Pediatrics", Network: "Medicare", Limit: 1, AsOf: asOf},
+	} {
+		expected, err := jsonRepo.GetNearestProviders(query)
+		require.NoError(t, err)
+		actual, err := sqlRepo.GetNearestProviders(query)
+		require.NoError(t, err)
+		assert.Equal(t, expected, actual, "%+v", query)
+	}
+}
+
+func TestSQLNetworkProviderLocationsMatchJSON(t *testing.T) {
+	jsonRepo := newRadiusFixture()
+	sqlRepo := newSQLiteTestRepository(t, jsonRepo)
+	asOf := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+
+	for _, test := range []struct {
+		network, specialty string
+		expected           []string
+	}{
+		{"Commercial", "All", []string{"P1-1", "P1-2", "P2-1", "P3-1", "P4-1"}},
+		{"Commercial", "Primary Care", []string{"P1-1", "P1-2", "P3-1", "P4-1"}},
+		{"Medicare", "All", []string{"P5-1"}},
+		{"", "Pediatrics", []string{"P5-1"}},
+		{"Tricare", "All", nil},
+	} {
+		expected, err := jsonRepo.GetNetworkProviderLocations(test.network, test.specialty, asOf)
+		require.NoError(t, err)
+		actual, err := sqlRepo.GetNetworkProviderLocations(test.network, test.specialty, asOf)
+		require.NoError(t, err)
+		assert.Equal(t, expected, actual, "%s %s", test.network, test.specialty)
+
+		var ids []string
+		for _, location := range expected {
+			ids = append(ids, location.LocationID)
+		}
+		assert.Equal(t, test.expected, ids, "%s %s", test.network, test.specialty)
+	}
+}
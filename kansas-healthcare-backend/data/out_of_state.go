@@ -0,0 +1,72 @@
+package data
+
+import (
+	"kansas-healthcare-api/models"
+	"math"
+	"time"
+)
+
+// inOutOfStateNetwork reports whether an out-of-state location takes members of the network at
+// asOf. An empty networkId accepts any network or none.
+func inOutOfStateNetwork(location models.OutOfStateLocation, networkId string, asOf time.Time) bool {
+	if networkId == "" {
+		return true
+	}
+	for _, network := range location.Networks {
+		if network.NetworkID == networkId && activeAt(network.EffectiveDate, network.TerminationDate, asOf) {
+			return true
+		}
+	}
+	return false
+}
+
+// outOfStateCounty names an out-of-state location's county with its state, e.g. "Jackson, MO", so
+// it cannot be mistaken for a Kansas county of the same name
+func outOfStateCounty(location models.OutOfStateLocation) string {
+	return location.County + ", " + location.State
+}
+
+func outOfStateRadiusMatch(location models.OutOfStateLocation, distance float64) radiusMatch {
+	return radiusMatch{
+		providerID: location.ProviderID,
+		specialty:  location.ProviderType,
+		county:     outOfStateCounty(location),
+		distance:   distance,
+		outOfState: true,
+	}
+}
+
+func outOfStateNearestMatch(location models.OutOfStateLocation, distance float64) nearestMatch {
+	return nearestMatch{
+		provider: models.Provider{ProviderID: location.ProviderID, NPI: location.NPI, ProviderType: location.ProviderType},
+		location: models.ProviderServiceLocation{
+			LocationID: location.LocationID,
+			Address1:   location.Address1,
+			Address2:   location.Address2,
+			City:       location.City,
+			ZipCode:    location.ZipCode,
+			County:     location.County,
+			Latitude:   location.Latitude,
+			Longitude:  location.Longitude,
+		},
+		state:    location.State,
+		distance: distance,
+	}
+}
+
+// outOfStateCoordinates returns the position of each out-of-state location, all of which are
+// geocoded
+func outOfStateCoordinates(locations []models.OutOfStateLocation) []models.Coordinate {
+	coordinates := make([]models.Coordinate, len(locations))
+	for i, location := range locations {
+		coordinates[i] = models.Coordinate{Latitude: location.Latitude, Longitude: location.Longitude}
+	}
+	return coordinates
+}
+
+// keepNearer lowers each of distances to the matching entry of others where that is nearer
+func keepNearer(distances, others []float64) {
+	for i, other := range others {
+		distances[i] = math.Min(distances[i], other)
+	}
+}
@@ -0,0 +1,185 @@
+package data
+
+import (
+	"errors"
+	"io/fs"
+	"kansas-healthcare-api/models"
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newOutOfStateFixture has cardiologists in Overland Park and Wichita, and across the state line
+// in Kansas City, Missouri, Joplin and Beatrice, Nebraska, with a family doctor in Miami, Oklahoma
+func newOutOfStateFixture() *JSONRepository {
+	joined, open := fixtureJoined, fixtureOpen
+	commercial := []models.OutOfStateNetwork{{NetworkID: "Commercial", EffectiveDate: joined, TerminationDate: open}}
+
+	return newProviderFixture(&jsonSnapshot{
+		outOfStateLocations: []models.OutOfStateLocation{
+			{LocationID: "MO-1", ProviderID: "MO-P1", NPI: "1000000011", ProviderType: "Cardiology", EffectiveDate: joined, TerminationDate: open,
+				City: "Kansas City", State: "MO", County: "Jackson", Latitude: 39.0448, Longitude: -94.5906, Networks: commercial},
+			// Left the network before the as-of date
+			{LocationID: "MO-2", ProviderID: "MO-P2", ProviderType: "Cardiology", EffectiveDate: joined, TerminationDate: open,
+				City: "Kansas City", State: "MO", County: "Jackson", Latitude: 39.0082, Longitude: -94.5573,
+				Networks: []models.OutOfStateNetwork{{NetworkID: "Commercial", EffectiveDate: joined, TerminationDate: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}}},
+			// Not open until after the as-of date
+			{LocationID: "MO-3", ProviderID: "MO-P3", ProviderType: "Cardiology", EffectiveDate: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), TerminationDate: open,
+				City: "Joplin", State: "MO", County: "Jasper", Latitude: 37.0718, Longitude: -94.5095, Networks: commercial},
+			{LocationID: "NE-1", ProviderID: "NE-P1", ProviderType: "Cardiology", EffectiveDate: joined, TerminationDate: open,
+				City: "Beatrice", State: "NE", County: "Gage", Latitude: 40.285, Longitude: -96.747,
+				Networks: []models.OutOfStateNetwork{{NetworkID: "Medicare", EffectiveDate: joined, TerminationDate: open}}},
+			{LocationID: "OK-1", ProviderID: "OK-P1", ProviderType: "Family Medicine", EffectiveDate: joined, TerminationDate: open,
+				City: "Miami", State: "OK", County: "Ottawa", Latitude: 36.873, Longitude: -94.879, Networks: commercial},
+		},
+		countyAreas: []models.CountyArea{{County: "Johnson", AreaSqMiles: 480, Latitude: 38.9822, Longitude: -94.6708}},
+	},
+		fixtureProvider{id: "P1", npi: "1000000001", specialty: "Cardiology", county: "Johnson", network: "Commercial",
+			locations: []fixtureLocation{{city: "Overland Park", latitude: 38.9822, longitude: -94.6708}}},
+		fixtureProvider{id: "P2", npi: "1000000002", specialty: "Cardiology", county: "Sedgwick", network: "Commercial",
+			locations: []fixtureLocation{{city: "Wichita", latitude: 37.6872, longitude: -97.3301}}},
+	)
+}
+
+func TestOutOfStateLocationsJoinRadiusAnalysis(t *testing.T) {
+	repo := newOutOfStateFixture()
+	sqlRepo := newSQLiteTestRepository(t, repo)
+	asOf := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+
+	result, err := repo.GetRadiusAnalysis("Johnson", nil, 15, "Commercial", asOf, false)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"Johnson": 1}, result["locations_by_county"])
+	assert.NotContains(t, result, "out_of_state_location_count")
+
+	// Only the Kansas City location in the network on the day is counted, under its own state
+	result, err = repo.GetRadiusAnalysis("Johnson", nil, 15, "Commercial", asOf, true)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"Johnson": 1, "Jackson, MO": 1}, result["locations_by_county"])
+	assert.Equal(t, 2, result["provider_count"])
+	assert.Equal(t, 1, result["out_of_state_location_count"])
+
+	for _, include := range []bool{false, true} {
+		for _, radius := range []int{15, 250} {
+			expected, err := repo.GetRadiusAnalysis("Johnson", nil, radius, "Commercial", asOf, include)
+			require.NoError(t, err)
+			actual, err := sqlRepo.GetRadiusAnalysis("Johnson", nil, radius, "Commercial", asOf, include)
+			require.NoError(t, err)
+			assert.Equal(t, expected, actual, "radius %d, out of state %t", radius, include)
+		}
+	}
+}
+
+func TestOutOfStateLocationsJoinNearestProviders(t *testing.T) {
+	repo := newOutOfStateFixture()
+	sqlRepo := newSQLiteTestRepository(t, repo)
+	query := models.NearestProviderQuery{
+		Center:    models.Coordinate{Latitude: 38.9822, Longitude: -94.6708},
+		Specialty: "Cardiology",
+		Network:   "Commercial",
+		Limit:     3,
+		AsOf:      time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC),
+	}
+
+	nearest, err := repo.GetNearestProviders(query)
+	require.NoError(t, err)
+	require.Len(t, nearest, 2)
+	assert.Equal(t, "P2-1", nearest[1].LocationID)
+
+	query.IncludeOutOfState = true
+	nearest, err = repo.GetNearestProviders(query)
+	require.NoError(t, err)
+	require.Len(t, nearest, 3)
+	assert.Equal(t, "P1-1", nearest[0].LocationID)
+	assert.Empty(t, nearest[0].State)
+	assert.Equal(t, "MO-1", nearest[1].LocationID)
+	assert.Equal(t, "MO", nearest[1].State)
+	assert.Equal(t, "Jackson", nearest[1].County)
+	assert.Equal(t, "1000000011", nearest[1].NPI)
+	assert.Equal(t, "P2-1", nearest[2].LocationID)
+
+	fromSQL, err := sqlRepo.GetNearestProviders(query)
+	require.NoError(t, err)
+	assert.Equal(t, nearest, fromSQL)
+}
+
+func TestOutOfStateLocationsShortenNearestDistances(t *testing.T) {
+	repo := newOutOfStateFixture()
+	sqlRepo := newSQLiteTestRepository(t, repo)
+	asOf := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	// Baxter Springs, in the southeastern corner of Cherokee County, and Overland Park
+	points := []models.Coordinate{{Latitude: 37.0237, Longitude: -94.7355}, {Latitude: 38.9822, Longitude: -94.6708}}
+
+	for _, source := range []Repository{repo, sqlRepo} {
+		distances, err := source.GetNearestProviderDistances(points, "Family Medicine", "Commercial", asOf, false)
+		require.NoError(t, err)
+		assert.Equal(t, []float64{math.Inf(1), math.Inf(1)}, distances)
+
+		distances, err = source.GetNearestProviderDistances(points, "Family Medicine", "Commercial", asOf, true)
+		require.NoError(t, err)
+		assert.InDelta(t, 13, distances[0], 1)
+		assert.InDelta(t, 146, distances[1], 1)
+
+		// An in-state location nearer than any across the line is kept
+		distances, err = source.GetNearestProviderDistances(points, "Cardiology", "Commercial", asOf, true)
+		require.NoError(t, err)
+		assert.Zero(t, distances[1])
+	}
+}
+
+func TestLoadOutOfStateLocations(t *testing.T) {
+	dir := t.TempDir()
+	writeTestDataset(t, dir, []models.Provider{{ProviderID: "P1", Status: "Active", County: "Sedgwick"}})
+
+	// Most deployments have no out-of-state providers, so the file may be left out
+	repo, err := NewJSONRepository(LoadOptions{DataDir: dir})
+	require.NoError(t, err)
+	assert.Empty(t, repo.snapshot().outOfStateLocations)
+	assert.Empty(t, repo.snapshot().degraded)
+
+	// Unless it was configured explicitly
+	_, err = NewJSONRepository(LoadOptions{DataDir: dir, Files: map[string]string{outOfStateLocationsFile: filepath.Join(dir, "missing.json")}})
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+
+	writeTestFile(t, filepath.Join(dir, outOfStateLocationsFile), []byte(`[{"location_id": "MO-1", "provider_id": "MO-P1",
+		"provider_type": "Cardiology", "state": "MO", "latitude": 39.0448, "longitude": -94.5906,
+		"networks": [{"network_id": "Commercial"}]}]`))
+	repo, err = NewJSONRepository(LoadOptions{DataDir: dir})
+	require.NoError(t, err)
+	assert.Len(t, repo.snapshot().outOfStateLocations, 1)
+}
+
+func TestLoadRejectsInvalidOutOfStateLocations(t *testing.T) {
+	for _, test := range []struct {
+		body  string
+		field string
+	}{
+		{`[{"provider_id": "MO-P1", "provider_type": "Cardiology", "state": "MO", "latitude": 39.04, "longitude": -94.59}]`, "location_id"},
+		{`[
+  {"location_id": "MO-1", "provider_id": "MO-P1", "provider_type": "Cardiology", "state": "MO", "latitude": 39.04, "longitude": -94.59},
+  {"location_id": "MO-1", "provider_id": "MO-P2", "provider_type": "Cardiology", "state": "MO", "latitude": 39.04, "longitude": -94.59}
+]`, "location_id"},
+		// The Kansas roster already has a location with this ID
+		{`[{"location_id": "P1-1", "provider_id": "MO-P1", "provider_type": "Cardiology", "state": "MO", "latitude": 39.04, "longitude": -94.59}]`, "location_id"},
+		{`[{"location_id": "MO-1", "provider_type": "Cardiology", "state": "MO", "latitude": 39.04, "longitude": -94.59}]`, "provider_id"},
+		{`[{"location_id": "MO-1", "provider_id": "MO-P1", "state": "MO", "latitude": 39.04, "longitude": -94.59}]`, "provider_type"},
+		{`[{"location_id": "KS-1", "provider_id": "KS-P1", "provider_type": "Cardiology", "state": "KS", "latitude": 39.04, "longitude": -94.61}]`, "state"},
+		{`[{"location_id": "TX-1", "provider_id": "TX-P1", "provider_type": "Cardiology", "state": "TX", "latitude": 35.22, "longitude": -101.83}]`, "state"},
+		{`[{"location_id": "MO-1", "provider_id": "MO-P1", "provider_type": "Cardiology", "state": "MO"}]`, "latitude"},
+		{`[{"location_id": "MO-1", "provider_id": "MO-P1", "provider_type": "Cardiology", "state": "MO", "latitude": 39.04, "longitude": -94.59,
+  "networks": [{"network_id": "Commercial"}, {"network_id": "Commercial"}]}]`, "networks"},
+	} {
+		dir := t.TempDir()
+		writeTestDataset(t, dir, []models.Provider{{ProviderID: "P1", Status: "Active", County: "Sedgwick"}})
+		writeTestFile(t, filepath.Join(dir, providerServiceLocationsFile), []byte(`[{"location_id": "P1-1", "provider_id": "P1", "county": "Sedgwick"}]`))
+		writeTestFile(t, filepath.Join(dir, outOfStateLocationsFile), []byte(test.body))
+
+		_, err := NewJSONRepository(LoadOptions{DataDir: dir})
+		var loadErr *LoadError
+		require.True(t, errors.As(err, &loadErr), test.body)
+		assert.Equal(t, test.field, loadErr.Field, test.body)
+	}
+}
@@ -0,0 +1,127 @@
+package data
+
+import (
+	"fmt"
+	"kansas-healthcare-api/models"
+	"math"
+	"sort"
+)
+
+// radiusBandMiles is the width of each band in a radius analysis distance distribution
+const radiusBandMiles = 5
+
+// radiusMatch is a service location found within a radius search
+type radiusMatch struct {
+	providerID string
+	specialty  string
+	county     string
+	distance   float64
+	outOfState bool
+}
+
+// hasCoordinates reports whether a service location was geocoded. Locations loaded without
+// coordinates carry zero for both.
+func hasCoordinates(location models.ProviderServiceLocation) bool {
+	return location.Latitude != 0 || location.Longitude != 0
+}
+
+// errNoCentroid reports a radius search around a county with no centroid in the area dataset
+func errNoCentroid(county string) error {
+	return fmt.Errorf("centroid for county %s %w", county, ErrNotFound)
+}
+
+// radiusBoundingBox returns latitude and longitude ranges that contain every point within radius
+// miles of center, so a query can discard distant rows before the exact distance is computed
+func radiusBoundingBox(center models.Coordinate, radius float64) (minLat, maxLat, minLng, maxLng float64) {
+	latDelta := radius / milesPerDegreeLatitude
+	minLat, maxLat = center.Latitude-latDelta, center.Latitude+latDelta
+
+	// Degrees of longitude shrink towards the poles, so widen by the edge nearest to one
+	widest := math.Max(math.Abs(minLat), math.Abs(maxLat))
+	if widest >= 90 {
+		return minLat, maxLat, -180, 180
+	}
+	lngDelta := latDelta / math.Cos(widest*math.Pi/180)
+	return minLat, maxLat, center.Longitude - lngDelta, center.Longitude + lngDelta
+}
+
+// radiusAnalysisResult summarises the locations found within radius miles of center: distinct
+// providers by specialty, locations by the county they sit in and locations by distance band.
+// When out-of-state locations were searched too, it also counts how many of them were found.
+func radiusAnalysisResult(county string, center models.Coordinate, radius int, networkId string, includeOutOfState bool, matches []radiusMatch) map[string]interface{} {
+	// Sum distances in a fixed order so every repository reports the same average
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].distance != matches[j].distance {
+			return matches[i].distance < matches[j].distance
+		}
+		return matches[i].providerID < matches[j].providerID
+	})
+
+	providers := make(map[string]bool)
+	specialtyProviders := make(map[string]map[string]bool)
+	byCounty := make(map[string]int)
+	bandCount := int(math.Ceil(float64(radius) / radiusBandMiles))
+	if bandCount < 1 {
+		bandCount = 1
+	}
+	bands := make([]int, bandCount)
+	nearest, total := 0.0, 0.0
+	outOfState := 0
+
+	for i, match := range matches {
+		providers[match.providerID] = true
+		if specialtyProviders[match.specialty] == nil {
+			specialtyProviders[match.specialty] = make(map[string]bool)
+		}
+		specialtyProviders[match.specialty][match.providerID] = true
+		byCounty[match.county]++
+		if match.outOfState {
+			outOfState++
+		}
+
+		band := int(match.distance / radiusBandMiles)
+		if band >= bandCount {
+			band = bandCount - 1
+		}
+		bands[band]++
+
+		if i == 0 || match.distance < nearest {
+			nearest = match.distance
+		}
+		total += match.distance
+	}
+
+	specialties := make(map[string]int, len(specialtyProviders))
+	for specialty, ids := range specialtyProviders {
+		specialties[specialty] = len(ids)
+	}
+	distribution := make([]map[string]interface{}, bandCount)
+	for i, count := range bands {
+		distribution[i] = map[string]interface{}{
+			"min_miles": i * radiusBandMiles,
+			"max_miles": int(math.Min(float64((i+1)*radiusBandMiles), float64(radius))),
+			"count":     count,
+		}
+	}
+
+	result := map[string]interface{}{
+		"county":                county,
+		"radius":                radius,
+		"network":               networkId,
+		"center":                center,
+		"provider_count":        len(providers),
+		"location_count":        len(matches),
+		"specialty_count":       len(specialties),
+		"specialties":           specialties,
+		"locations_by_county":   byCounty,
+		"distance_distribution": distribution,
+	}
+	if includeOutOfState {
+		result["out_of_state_location_count"] = outOfState
+	}
+	if len(matches) > 0 {
+		result["nearest_miles"] = math.Round(nearest*100) / 100
+		result["average_miles"] = math.Round(total/float64(len(matches))*100) / 100
+	}
+	return result
+}
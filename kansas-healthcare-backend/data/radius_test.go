@@ -0,0 +1,111 @@
+package data
+
+import (
+	"errors"
+	"kansas-healthcare-api/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRadiusFixture() *JSONRepository {
+	return newProviderFixture(&jsonSnapshot{
+		countyClaims: []models.CountyClaims{{County: "Sedgwick", ClaimsCount: 5000, AvgClaimAmount: 250.5}},
+		countyAreas: []models.CountyArea{
+			{County: "Sedgwick", AreaSqMiles: 1009.8, Latitude: 37.684, Longitude: -97.461},
+			{County: "Butler", AreaSqMiles: 1428.8},
+		},
+	},
+		fixtureProvider{id: "P1", specialty: "Primary Care", county: "Sedgwick", network: "Commercial",
+			locations: []fixtureLocation{{latitude: 37.6872, longitude: -97.3301}, {latitude: 37.5447, longitude: -97.2689}}},
+		// Across the county line in El Dorado and Hutchinson
+		fixtureProvider{id: "P2", specialty: "Cardiology", county: "Butler", network: "Commercial",
+			locations: []fixtureLocation{{latitude: 37.8172, longitude: -96.8622}}},
+		fixtureProvider{id: "P3", specialty: "Primary Care", county: "Reno", network: "Commercial",
+			locations: []fixtureLocation{{latitude: 38.0608, longitude: -97.9298}}},
+		// Overland Park, far outside any radius below
+		fixtureProvider{id: "P4", specialty: "Primary Care", county: "Johnson", network: "Commercial",
+			locations: []fixtureLocation{{latitude: 38.9822, longitude: -94.6708}}},
+		// Out of network, so never counted
+		fixtureProvider{id: "P5", specialty: "Pediatrics", county: "Sedgwick", network: "Medicare",
+			locations: []fixtureLocation{{latitude: 37.69, longitude: -97.33}}},
+	)
+}
+
+func TestRadiusAnalysisCrossesCountyLines(t *testing.T) {
+	repo := newRadiusFixture()
+	asOf := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+
+	result, err := repo.GetRadiusAnalysis("Sedgwick", nil, 40, "Commercial", asOf, false)
+	require.NoError(t, err)
+	assert.Equal(t, models.Coordinate{Latitude: 37.684, Longitude: -97.461}, result["center"])
+	assert.Equal(t, 3, result["provider_count"])
+	assert.Equal(t, 4, result["location_count"])
+	assert.Equal(t, map[string]int{"Primary Care": 2, "Cardiology": 1}, result["specialties"])
+	assert.Equal(t, map[string]int{"Sedgwick": 2, "Butler": 1, "Reno": 1}, result["locations_by_county"])
+	assert.Equal(t, 5000, result["claims_count"])
+
+	distribution := result["distance_distribution"].([]map[string]interface{})
+	require.Len(t, distribution, 8)
+	total := 0
+	for _, band := range distribution {
+		total += band["count"].(int)
+	}
+	assert.Equal(t, 4, total)
+	assert.Equal(t, 40, distribution[7]["max_miles"])
+
+	// A narrower radius keeps only the Wichita area locations
+	result, err = repo.GetRadiusAnalysis("Sedgwick", nil, 15, "Commercial", asOf, false)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"Sedgwick": 2}, result["locations_by_county"])
+
+	// A supplied point replaces the county centroid
+	center := &models.Coordinate{Latitude: 38.9822, Longitude: -94.6708}
+	result, err = repo.GetRadiusAnalysis("Sedgwick", center, 10, "Commercial", asOf, false)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"Johnson": 1}, result["locations_by_county"])
+}
+
+func TestRadiusAnalysisNeedsCentroid(t *testing.T) {
+	repo := newRadiusFixture()
+	asOf := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+
+	for _, county := range []string{"Butler", "Atlantis"} {
+		_, err := repo.GetRadiusAnalysis(county, nil, 25, "Commercial", asOf, false)
+		assert.True(t, errors.Is(err, ErrNotFound), county)
+
+		_, err = newSQLiteTestRepository(t, repo).GetRadiusAnalysis(county, nil, 25, "Commercial", asOf, false)
+		assert.True(t, errors.Is(err, ErrNotFound), county)
+	}
+}
+
+func TestRadiusBoundingBoxContainsRadius(t *testing.T) {
+	center := models.Coordinate{Latitude: 37.684, Longitude: -97.461}
+	minLat, maxLat, minLng, maxLng := radiusBoundingBox(center, 50)
+
+	// Each edge of the box lies at least the radius away from the centre
+	for _, edge := range []models.Coordinate{
+		{Latitude: minLat, Longitude: center.Longitude},
+		{Latitude: maxLat, Longitude: center.Longitude},
+		{Latitude: center.Latitude, Longitude: minLng},
+		{Latitude: center.Latitude, Longitude: maxLng},
+	} {
+		assert.GreaterOrEqual(t, haversineDistance(center.Latitude, center.Longitude, edge.Latitude, edge.Longitude), 49.999)
+	}
+}
+
+func TestSQLRadiusAnalysisMatchesJSON(t *testing.T) {
+	jsonRepo := newRadiusFixture()
+	sqlRepo := newSQLiteTestRepository(t, jsonRepo)
+	asOf := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+
+	for _, radius := range []int{5, 15, 40, 250} {
+		expected, err := jsonRepo.GetRadiusAnalysis("Sedgwick", nil, radius, "Commercial", asOf, false)
+		require.NoError(t, err)
+		actual, err := sqlRepo.GetRadiusAnalysis("Sedgwick", nil, radius, "Commercial", asOf, false)
+		require.NoError(t, err)
+		assert.Equal(t, expected, actual, "radius %d", radius)
+	}
+}
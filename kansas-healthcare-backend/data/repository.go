@@ -1,20 +1,84 @@
 package data
 
-import "kansas-healthcare-api/models"
+import (
+	"kansas-healthcare-api/models"
+	"time"
+)
 
+// Repository is the read and write surface shared by the JSON and SQL backends. Methods that take
+// asOf (or criteria.AsOf) judge network affiliations and service locations by whether their
+// effective and termination dates bracket that instant. Methods that take includeOutOfState also
+// search the service locations in neighboring states when it is true.
 type Repository interface {
 	GetProviders() ([]models.Provider, error)
 	GetProviderNetworks() ([]models.ProviderNetwork, error)
 	GetProviderServiceLocations() ([]models.ProviderServiceLocation, error)
-	GetCountyStats() ([]models.CountyStats, error)
-	GetCountyStatsByName(county string) (*models.CountyStats, error)
-	GetFilteredProviders(filter models.FilterRequest) ([]models.Provider, error)
+	GetCountyStats(asOf time.Time) ([]models.CountyStats, error)
+	GetCountyStatsByName(county string, asOf time.Time) (*models.CountyStats, error)
+	GetFilteredProviders(filter models.FilterRequest, asOf time.Time) ([]models.Provider, error)
 	GetActiveProviderCount() (int, error)
-	GetTerminatedNetworkCount(networkId string) (int, error)
-	GetTerminatedServiceLocationCount(networkId string) (int, error)
+	// GetActiveLocationProviders returns the active providers with a service location in force in
+	// county, or anywhere when county is empty
+	GetActiveLocationProviders(county string, asOf time.Time) ([]models.Provider, error)
+	GetTerminatedNetworkBreakdown(networkId string, criteria models.TerminationCriteria) (*models.TerminationBreakdown, error)
+	GetTerminatedServiceLocationCount(networkId string, criteria models.TerminationCriteria) (int, error)
 	GetProvidersInCounty(county string) ([]models.Provider, error)
-	GetRadiusAnalysis(county string, radius int, networkId string) (map[string]interface{}, error)
-	GetCountyTerminatedNetworkCount(county, networkId string) (int, int, error)
+	GetRadiusAnalysis(county string, center *models.Coordinate, radius int, networkId string, asOf time.Time, includeOutOfState bool) (map[string]interface{}, error)
+	// GetNearestProviders returns the service locations closest to query.Center, nearest first, of
+	// active providers in the network with the requested specialty, including those in neighboring
+	// states when query.IncludeOutOfState is set
+	GetNearestProviders(query models.NearestProviderQuery) ([]models.NearestProvider, error)
+	// GetNetworkProviderLocations returns the service locations in force of active providers with
+	// the specialty ("All" for any) in the network, or in any network when networkId is empty
+	GetNetworkProviderLocations(networkId, specialty string, asOf time.Time) ([]models.ProviderLocation, error)
+	GetCountyTerminatedNetworkBreakdown(county, networkId string, criteria models.TerminationCriteria) (int, *models.TerminationBreakdown, error)
+	// GetTerminationRate returns the numerator and denominator of a termination percentage. An empty
+	// county covers every provider; otherwise the providers with a service location in force there.
+	GetTerminationRate(county, networkId string, criteria models.TerminationCriteria) (int, int, error)
 	GetCountyArea(county string) float64
 	GetSpecialtyDensityStandards() map[string]float64
+	GetAdequacyStandards() ([]models.AdequacyStandard, error)
+	GetCountyDesignations() ([]models.CountyDesignation, error)
+	// GetCountySamplePoints returns the points a county's network adequacy is measured from, a grid
+	// clipped to its boundary, or a square of its area around its centroid when it has no boundary.
+	// It fails with ErrNotFound when the county has neither.
+	GetCountySamplePoints(county string, gridSize int) ([]models.Coordinate, error)
+	// GetNearestProviderDistances returns the miles from each point to the nearest location in force
+	// of an active network provider with the specialty, or +Inf when there is none
+	GetNearestProviderDistances(points []models.Coordinate, specialty, networkId string, asOf time.Time, includeOutOfState bool) ([]float64, error)
+	// GetCoverageGrid returns square cells of cellMiles a side covering the state, each assigned to
+	// the county its centre lies in
+	GetCoverageGrid(cellMiles float64) ([]models.GridCell, error)
+	// GetCatchments returns the Voronoi catchment of each position holding geocoded locations in
+	// force of active network providers with the specialty, clipped to the county boundaries
+	GetCatchments(networkId, specialty string, asOf time.Time) ([]models.Catchment, error)
+	// GetProviderLocationsWithin returns, for each point, the locations in force of active network
+	// providers with the specialty no more than radiusMiles away, ordered by location ID
+	GetProviderLocationsWithin(points []models.Coordinate, radiusMiles float64, specialty, networkId string, asOf time.Time) ([][]models.LocationDistance, error)
+	GetCountyPopulation() ([]models.CountyPopulation, error)
+	GetZipCentroids() ([]models.ZipCentroid, error)
+	// GetGeocoder returns a geocoder over the ZIP code and city centroid tables
+	GetGeocoder() (*Geocoder, error)
+	GetDataQualityReport() (*models.DataQualityReport, error)
+	GetCountyBoundaries() ([]models.CountyBoundary, error)
+	// GetCountyBoundary fails with ErrNotFound when the county has no boundary
+	GetCountyBoundary(county string) (*models.CountyBoundary, error)
+
+	// Roster writes. Updates and deletes take the RecordVersion the caller last read and fail
+	// with ErrVersionMismatch if the record has changed since.
+	GetProvider(providerId string) (*models.Provider, error)
+	CreateProvider(provider models.Provider) error
+	UpdateProvider(provider models.Provider, version string) error
+	DeleteProvider(providerId, version string) error
+	// Affiliations are keyed by provider, network and effective date, as a provider may leave a
+	// network and rejoin it. UpdateProviderNetwork replaces the affiliation starting on
+	// effectiveDate, whose own effective date may move.
+	GetProviderNetwork(providerId, networkId string, effectiveDate time.Time) (*models.ProviderNetwork, error)
+	CreateProviderNetwork(network models.ProviderNetwork) error
+	UpdateProviderNetwork(effectiveDate time.Time, network models.ProviderNetwork, version string) error
+	DeleteProviderNetwork(providerId, networkId string, effectiveDate time.Time, version string) error
+	GetServiceLocation(locationId string) (*models.ProviderServiceLocation, error)
+	CreateServiceLocation(location models.ProviderServiceLocation) (*models.ProviderServiceLocation, error)
+	UpdateServiceLocation(location models.ProviderServiceLocation, version string) error
+	DeleteServiceLocation(locationId, version string) error
 }
@@ -0,0 +1,76 @@
+package data
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"kansas-healthcare-api/config"
+	"kansas-healthcare-api/models"
+	"time"
+)
+
+// Errors returned by roster writes. They are wrapped with the key of the record involved, so
+// match them with errors.Is.
+var (
+	ErrNotFound        = errors.New("not found")
+	ErrAlreadyExists   = errors.New("already exists")
+	ErrVersionMismatch = errors.New("has been modified since it was read")
+	ErrInUse           = errors.New("is still referenced")
+)
+
+// RecordVersion is the optimistic concurrency token of a record: a hash of its JSON encoding, so
+// the same record has the same version in every repository and any change produces a new one
+func RecordVersion(record interface{}) string {
+	body, err := json.Marshal(record)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:8])
+}
+
+// checkVersion rejects a write made against an outdated copy of record
+func checkVersion(key string, record interface{}, version string) error {
+	if RecordVersion(record) != version {
+		return fmt.Errorf("%s %w", key, ErrVersionMismatch)
+	}
+	return nil
+}
+
+// affiliationKey describes a network affiliation, which a provider may hold several times in the
+// same network, once per spell starting on a different effective date
+func affiliationKey(providerId, networkId string, effectiveDate time.Time) string {
+	return fmt.Sprintf("network %s for provider %s from %s", networkId, providerId, effectiveDate.UTC().Format(config.AsOfDateFormat))
+}
+
+// nextLocationID returns the first "<provider>-<n>" identifier not already taken
+func nextLocationID(providerId string, taken func(id string) bool) string {
+	for n := 1; ; n++ {
+		id := fmt.Sprintf("%s-%d", providerId, n)
+		if !taken(id) {
+			return id
+		}
+	}
+}
+
+// assignLocationIDs gives every service location without an identifier one derived from its
+// provider and its position among that provider's locations, so IDs stay stable across reloads
+// of the same file
+func assignLocationIDs(locations []models.ProviderServiceLocation) {
+	taken := make(map[string]bool, len(locations))
+	for _, location := range locations {
+		if location.LocationID != "" {
+			taken[location.LocationID] = true
+		}
+	}
+	for i := range locations {
+		if locations[i].LocationID != "" {
+			continue
+		}
+		id := nextLocationID(locations[i].ProviderID, func(id string) bool { return taken[id] })
+		locations[i].LocationID = id
+		taken[id] = true
+	}
+}
@@ -0,0 +1,274 @@
+package data
+
+import (
+	"container/heap"
+	"kansas-healthcare-api/models"
+	"math"
+)
+
+// spatialIndex is a static k-d tree over points on the Earth's surface. Points are stored as unit
+// vectors, where straight-line (chord) distance grows with great-circle distance, so the usual
+// axis-aligned pruning is exact. Reported distances come from haversineDistance, so they match a
+// brute-force scan exactly.
+//
+// The tree is implicit: within any range of nodes the middle one is the split point, with the
+// nodes before it no greater on the split axis and the nodes after it no smaller.
+type spatialIndex struct {
+	nodes []spatialNode
+}
+
+type spatialNode struct {
+	xyz        [3]float64
+	coordinate models.Coordinate
+	id         int
+}
+
+// newSpatialIndex builds a tree over coordinates. Query results identify points by their position
+// in the slice.
+func newSpatialIndex(coordinates []models.Coordinate) *spatialIndex {
+	nodes := make([]spatialNode, len(coordinates))
+	for i, c := range coordinates {
+		nodes[i] = spatialNode{xyz: unitVector(c), coordinate: c, id: i}
+	}
+	buildSpatialTree(nodes, 0)
+	return &spatialIndex{nodes: nodes}
+}
+
+// within calls visit for every point no more than radius miles from center, in no particular order
+func (t *spatialIndex) within(center models.Coordinate, radius float64, visit func(id int, distance float64)) {
+	if radius < 0 {
+		return
+	}
+	q := unitVector(center)
+	// Pad the chord limit so rounding never prunes a point haversineDistance puts on the boundary
+	limit := chordForMiles(radius) + 1e-9
+	limitSq := limit * limit
+
+	var search func(lo, hi, depth int)
+	search = func(lo, hi, depth int) {
+		if lo >= hi {
+			return
+		}
+		mid := lo + (hi-lo)/2
+		node := &t.nodes[mid]
+		if chordSq(q, node.xyz) <= limitSq {
+			distance := haversineDistance(center.Latitude, center.Longitude, node.coordinate.Latitude, node.coordinate.Longitude)
+			if distance <= radius {
+				visit(node.id, distance)
+			}
+		}
+		diff := q[depth%3] - node.xyz[depth%3]
+		if diff <= limit {
+			search(lo, mid, depth+1)
+		}
+		if -diff <= limit {
+			search(mid+1, hi, depth+1)
+		}
+	}
+	search(0, len(t.nodes), 0)
+}
+
+// nearest returns the point closest to center other than exclude (pass -1 to consider every
+// point) and its distance in miles. ok is false when there is no such point.
+func (t *spatialIndex) nearest(center models.Coordinate, exclude int) (id int, distance float64, ok bool) {
+	q := unitVector(center)
+	best := -1
+	bestSq := math.Inf(1)
+
+	var search func(lo, hi, depth int)
+	search = func(lo, hi, depth int) {
+		if lo >= hi {
+			return
+		}
+		mid := lo + (hi-lo)/2
+		node := &t.nodes[mid]
+		if node.id != exclude {
+			if d := chordSq(q, node.xyz); d < bestSq {
+				best, bestSq = mid, d
+			}
+		}
+		// Descend into the side holding the query first, then the other only if it can hold a closer point
+		diff := q[depth%3] - node.xyz[depth%3]
+		if diff < 0 {
+			search(lo, mid, depth+1)
+			if diff*diff <= bestSq {
+				search(mid+1, hi, depth+1)
+			}
+		} else {
+			search(mid+1, hi, depth+1)
+			if diff*diff <= bestSq {
+				search(lo, mid, depth+1)
+			}
+		}
+	}
+	search(0, len(t.nodes), 0)
+
+	if best < 0 {
+		return -1, 0, false
+	}
+	node := t.nodes[best]
+	return node.id, haversineDistance(center.Latitude, center.Longitude, node.coordinate.Latitude, node.coordinate.Longitude), true
+}
+
+// spatialNeighbour is a point found by nearestN
+type spatialNeighbour struct {
+	id       int
+	distance float64
+}
+
+// nearestN returns up to n points closest to center for which accept returns true, nearest first.
+// Points at the same distance are ordered by before when given, otherwise by id.
+func (t *spatialIndex) nearestN(center models.Coordinate, n int, accept func(id int) bool, before func(a, b int) bool) []spatialNeighbour {
+	if n <= 0 {
+		return nil
+	}
+	if before == nil {
+		before = func(a, b int) bool { return a < b }
+	}
+	q := unitVector(center)
+
+	// found is a max-heap on distance holding the best n points so far, the furthest at the root
+	found := &neighbourHeap{before: before}
+	var search func(lo, hi, depth int)
+	search = func(lo, hi, depth int) {
+		if lo >= hi {
+			return
+		}
+		mid := lo + (hi-lo)/2
+		node := &t.nodes[mid]
+		d := chordSq(q, node.xyz)
+		if (found.Len() < n || found.closer(d, node.id)) && accept(node.id) {
+			heap.Push(found, neighbourCandidate{id: node.id, chordSq: d, node: mid})
+			if found.Len() > n {
+				heap.Pop(found)
+			}
+		}
+		diff := q[depth%3] - node.xyz[depth%3]
+		nearLo, nearHi, farLo, farHi := mid+1, hi, lo, mid
+		if diff < 0 {
+			nearLo, nearHi, farLo, farHi = lo, mid, mid+1, hi
+		}
+		search(nearLo, nearHi, depth+1)
+		if found.Len() < n || diff*diff <= found.items[0].chordSq {
+			search(farLo, farHi, depth+1)
+		}
+	}
+	search(0, len(t.nodes), 0)
+
+	neighbours := make([]spatialNeighbour, found.Len())
+	for i := len(neighbours) - 1; i >= 0; i-- {
+		c := heap.Pop(found).(neighbourCandidate)
+		node := t.nodes[c.node]
+		neighbours[i] = spatialNeighbour{
+			id:       c.id,
+			distance: haversineDistance(center.Latitude, center.Longitude, node.coordinate.Latitude, node.coordinate.Longitude),
+		}
+	}
+	return neighbours
+}
+
+type neighbourCandidate struct {
+	id      int
+	chordSq float64
+	node    int
+}
+
+// neighbourHeap orders candidates furthest first, so the root is the first to drop
+type neighbourHeap struct {
+	items  []neighbourCandidate
+	before func(a, b int) bool
+}
+
+// closer reports whether a point would displace the furthest candidate held
+func (h *neighbourHeap) closer(chordSq float64, id int) bool {
+	root := h.items[0]
+	return chordSq < root.chordSq || (chordSq == root.chordSq && h.before(id, root.id))
+}
+
+func (h *neighbourHeap) Len() int { return len(h.items) }
+func (h *neighbourHeap) Less(i, j int) bool {
+	a, b := h.items[i], h.items[j]
+	if a.chordSq != b.chordSq {
+		return a.chordSq > b.chordSq
+	}
+	return h.before(b.id, a.id)
+}
+func (h *neighbourHeap) Swap(i, j int)      { h.items[i], h.items[j] = h.items[j], h.items[i] }
+func (h *neighbourHeap) Push(x interface{}) { h.items = append(h.items, x.(neighbourCandidate)) }
+func (h *neighbourHeap) Pop() interface{} {
+	last := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	return last
+}
+
+// buildSpatialTree arranges nodes into an implicit k-d tree, cycling the split axis with depth
+func buildSpatialTree(nodes []spatialNode, depth int) {
+	if len(nodes) <= 1 {
+		return
+	}
+	mid := len(nodes) / 2
+	selectNth(nodes, mid, depth%3)
+	buildSpatialTree(nodes[:mid], depth+1)
+	buildSpatialTree(nodes[mid+1:], depth+1)
+}
+
+// selectNth partially orders nodes on axis so the nth is in its sorted position, with no greater
+// value before it and no smaller after it (Hoare's quickselect)
+func selectNth(nodes []spatialNode, n, axis int) {
+	lo, hi := 0, len(nodes)-1
+	for lo < hi {
+		// Median of three keeps already sorted input from degrading to quadratic time
+		m := lo + (hi-lo)/2
+		if nodes[m].xyz[axis] < nodes[lo].xyz[axis] {
+			nodes[m], nodes[lo] = nodes[lo], nodes[m]
+		}
+		if nodes[hi].xyz[axis] < nodes[lo].xyz[axis] {
+			nodes[hi], nodes[lo] = nodes[lo], nodes[hi]
+		}
+		if nodes[hi].xyz[axis] < nodes[m].xyz[axis] {
+			nodes[hi], nodes[m] = nodes[m], nodes[hi]
+		}
+		pivot := nodes[m].xyz[axis]
+
+		i, j := lo, hi
+		for i <= j {
+			for nodes[i].xyz[axis] < pivot {
+				i++
+			}
+			for nodes[j].xyz[axis] > pivot {
+				j--
+			}
+			if i <= j {
+				nodes[i], nodes[j] = nodes[j], nodes[i]
+				i++
+				j--
+			}
+		}
+		switch {
+		case n <= j:
+			hi = j
+		case n >= i:
+			lo = i
+		default:
+			return
+		}
+	}
+}
+
+// unitVector converts a coordinate to a point on the unit sphere
+func unitVector(c models.Coordinate) [3]float64 {
+	lat := c.Latitude * math.Pi / 180
+	lng := c.Longitude * math.Pi / 180
+	return [3]float64{math.Cos(lat) * math.Cos(lng), math.Cos(lat) * math.Sin(lng), math.Sin(lat)}
+}
+
+// chordForMiles converts a great-circle distance to the straight-line distance between unit vectors
+func chordForMiles(miles float64) float64 {
+	angle := math.Min(miles/earthRadiusMiles, math.Pi)
+	return 2 * math.Sin(angle/2)
+}
+
+func chordSq(a, b [3]float64) float64 {
+	dx, dy, dz := a[0]-b[0], a[1]-b[1], a[2]-b[2]
+	return dx*dx + dy*dy + dz*dz
+}
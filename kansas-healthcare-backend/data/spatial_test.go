@@ -0,0 +1,184 @@
+package data
+
+import (
+	"kansas-healthcare-api/config"
+	"kansas-healthcare-api/models"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// syntheticLocations scatters n points over Kansas, most of them clustered around a few cities the
+// way service locations are, with a fixed seed so runs are comparable
+func syntheticLocations(n int) []models.Coordinate {
+	rng := rand.New(rand.NewSource(42))
+	cities := []models.Coordinate{
+		{Latitude: 37.6872, Longitude: -97.3301},  // Wichita
+		{Latitude: 38.9822, Longitude: -94.6708},  // Overland Park
+		{Latitude: 39.0473, Longitude: -95.6752},  // Topeka
+		{Latitude: 38.9717, Longitude: -95.2353},  // Lawrence
+		{Latitude: 37.7528, Longitude: -100.0171}, // Dodge City
+	}
+
+	points := make([]models.Coordinate, n)
+	for i := range points {
+		if rng.Intn(10) < 7 {
+			city := cities[rng.Intn(len(cities))]
+			points[i] = models.Coordinate{Latitude: city.Latitude + rng.NormFloat64()*0.15, Longitude: city.Longitude + rng.NormFloat64()*0.2}
+			continue
+		}
+		points[i] = models.Coordinate{
+			Latitude:  config.KansasMinLatitude + rng.Float64()*(config.KansasMaxLatitude-config.KansasMinLatitude),
+			Longitude: config.KansasMinLongitude + rng.Float64()*(config.KansasMaxLongitude-config.KansasMinLongitude),
+		}
+	}
+	return points
+}
+
+func bruteForceWithin(points []models.Coordinate, center models.Coordinate, radius float64) []int {
+	var ids []int
+	for i, p := range points {
+		if haversineDistance(center.Latitude, center.Longitude, p.Latitude, p.Longitude) <= radius {
+			ids = append(ids, i)
+		}
+	}
+	return ids
+}
+
+func bruteForceNearest(points []models.Coordinate, center models.Coordinate, exclude int) float64 {
+	best := math.Inf(1)
+	for i, p := range points {
+		if i == exclude {
+			continue
+		}
+		best = math.Min(best, haversineDistance(center.Latitude, center.Longitude, p.Latitude, p.Longitude))
+	}
+	return best
+}
+
+func TestSpatialIndexMatchesBruteForce(t *testing.T) {
+	points := syntheticLocations(5000)
+	// Repeated coordinates, as for providers sharing a building
+	points = append(points, points[:50]...)
+	index := newSpatialIndex(points)
+
+	for _, center := range []models.Coordinate{points[0], points[4000], {Latitude: 38.5, Longitude: -98.0}, {Latitude: 45, Longitude: -120}} {
+		for _, radius := range []float64{0, 1, 10, 75, 500} {
+			var ids []int
+			index.within(center, radius, func(id int, distance float64) {
+				assert.Equal(t, haversineDistance(center.Latitude, center.Longitude, points[id].Latitude, points[id].Longitude), distance)
+				ids = append(ids, id)
+			})
+			sort.Ints(ids)
+			assert.Equal(t, bruteForceWithin(points, center, radius), ids, "%v within %v miles", center, radius)
+		}
+	}
+
+	for i := 0; i < len(points); i += 97 {
+		_, distance, ok := index.nearest(points[i], i)
+		assert.True(t, ok)
+		assert.Equal(t, bruteForceNearest(points, points[i], i), distance, "nearest to point %d", i)
+	}
+}
+
+func TestSpatialIndexEdgeCases(t *testing.T) {
+	empty := newSpatialIndex(nil)
+	_, _, ok := empty.nearest(models.Coordinate{Latitude: 38, Longitude: -98}, -1)
+	assert.False(t, ok)
+	empty.within(models.Coordinate{Latitude: 38, Longitude: -98}, 100, func(int, float64) { t.Fatal("empty index visited a point") })
+
+	single := newSpatialIndex([]models.Coordinate{{Latitude: 38, Longitude: -98}})
+	_, _, ok = single.nearest(models.Coordinate{Latitude: 38, Longitude: -98}, 0)
+	assert.False(t, ok, "the only point is excluded")
+	id, distance, ok := single.nearest(models.Coordinate{Latitude: 38, Longitude: -97}, -1)
+	assert.True(t, ok)
+	assert.Equal(t, 0, id)
+	assert.InDelta(t, 54.5, distance, 0.5)
+}
+
+// benchmarkLocations is the 500,000 point dataset the benchmarks share, built once on first use
+var benchmarkLocations = sync.OnceValue(func() []models.Coordinate { return syntheticLocations(500000) })
+
+var benchmarkIndex = sync.OnceValue(func() *spatialIndex { return newSpatialIndex(benchmarkLocations()) })
+
+func BenchmarkBuildSpatialIndex(b *testing.B) {
+	points := benchmarkLocations()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		newSpatialIndex(points)
+	}
+}
+
+// The radius benchmarks search 25 miles around Wichita, the radius analysis default
+func BenchmarkWithinRadius(b *testing.B) {
+	points := benchmarkLocations()
+	center := models.Coordinate{Latitude: 37.684, Longitude: -97.461}
+
+	b.Run("BruteForce", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			bruteForceWithin(points, center, 25)
+		}
+	})
+	b.Run("SpatialIndex", func(b *testing.B) {
+		index := benchmarkIndex()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			count := 0
+			index.within(center, 25, func(int, float64) { count++ })
+		}
+	})
+}
+
+// The nearest-neighbour benchmarks answer one query per operation, cycling through the dataset
+func BenchmarkNearestNeighbour(b *testing.B) {
+	points := benchmarkLocations()
+
+	b.Run("BruteForce", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			j := i * 7919 % len(points)
+			bruteForceNearest(points, points[j], j)
+		}
+	})
+	b.Run("SpatialIndex", func(b *testing.B) {
+		index := benchmarkIndex()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			j := i * 7919 % len(points)
+			index.nearest(points[j], j)
+		}
+	})
+}
+
+func TestNearestNMatchesBruteForce(t *testing.T) {
+	points := syntheticLocations(3000)
+	points = append(points, points[:30]...)
+	index := newSpatialIndex(points)
+	// Skip every third point, as a filter on network or specialty would
+	accept := func(id int) bool { return id%3 != 0 }
+
+	for _, center := range []models.Coordinate{points[7], {Latitude: 38.5, Longitude: -98.0}} {
+		var expected []int
+		for id := range points {
+			if accept(id) {
+				expected = append(expected, id)
+			}
+		}
+		sort.SliceStable(expected, func(a, b int) bool {
+			da := haversineDistance(center.Latitude, center.Longitude, points[expected[a]].Latitude, points[expected[a]].Longitude)
+			db := haversineDistance(center.Latitude, center.Longitude, points[expected[b]].Latitude, points[expected[b]].Longitude)
+			return da < db
+		})
+
+		neighbours := index.nearestN(center, 25, accept, nil)
+		require.Len(t, neighbours, 25)
+		for i, neighbour := range neighbours {
+			want := expected[i]
+			assert.Equal(t, haversineDistance(center.Latitude, center.Longitude, points[want].Latitude, points[want].Longitude), neighbour.distance)
+		}
+	}
+}
@@ -0,0 +1,1240 @@
+package data
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"kansas-healthcare-api/config"
+	"kansas-healthcare-api/models"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "github.com/lib/pq"  // PostgreSQL driver
+	_ "modernc.org/sqlite" // Pure-Go SQLite driver, no cgo or database server required
+)
+
+// Supported database dialects, matching config.Config.DBDriver
+const (
+	DialectPostgres = "postgres"
+	DialectSQLite   = "sqlite"
+)
+
+// SQLRepository implements Repository on top of a relational database via database/sql
+type SQLRepository struct {
+	db      *sql.DB
+	dialect string
+}
+
+// OpenDatabase opens and pings a connection pool for the given driver
+func OpenDatabase(driver, dsn string) (*sql.DB, error) {
+	var db *sql.DB
+	var err error
+	switch driver {
+	case DialectSQLite:
+		db, err = sql.Open("sqlite", sqliteDSN(dsn))
+		if err == nil {
+			// SQLite serialises writers anyway, and a single connection keeps ":memory:" databases shared
+			db.SetMaxOpenConns(1)
+		}
+	case DialectPostgres:
+		db, err = sql.Open("postgres", dsn)
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", driver)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// sqliteDSN stores timestamps in a sortable text format so date range filters work in SQL
+func sqliteDSN(path string) string {
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
+	}
+	return path + separator + "_time_format=sqlite"
+}
+
+// NewSQLRepository brings the schema up to date and returns a repository backed by db
+func NewSQLRepository(db *sql.DB, dialect string) (*SQLRepository, error) {
+	if dialect != DialectPostgres && dialect != DialectSQLite {
+		return nil, fmt.Errorf("unsupported database dialect %q", dialect)
+	}
+	if err := migrate(db, dialect); err != nil {
+		return nil, err
+	}
+	return &SQLRepository{db: db, dialect: dialect}, nil
+}
+
+// rebind rewrites ? placeholders into the $n form PostgreSQL expects
+func rebind(dialect, query string) string {
+	if dialect != DialectPostgres {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, ch := range query {
+		if ch == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(ch)
+	}
+	return b.String()
+}
+
+func (r *SQLRepository) query(query string, args ...interface{}) (*sql.Rows, error) {
+	return r.db.Query(rebind(r.dialect, query), args...)
+}
+
+func (r *SQLRepository) queryRow(query string, args ...interface{}) *sql.Row {
+	return r.db.QueryRow(rebind(r.dialect, query), args...)
+}
+
+// Close releases the underlying connection pool
+func (r *SQLRepository) Close() error {
+	return r.db.Close()
+}
+
+// IsEmpty reports whether no providers have been loaded yet
+func (r *SQLRepository) IsEmpty() (bool, error) {
+	var count int
+	if err := r.queryRow(`SELECT COUNT(*) FROM providers`).Scan(&count); err != nil {
+		return false, err
+	}
+	return count == 0, nil
+}
+
+// SeedFromJSON copies every dataset held by a JSON repository into the database in one transaction
+func (r *SQLRepository) SeedFromJSON(repo *JSONRepository) error {
+	src := repo.snapshot()
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	insert := func(query string, rows int, args func(i int) []interface{}) error {
+		stmt, err := tx.Prepare(rebind(r.dialect, query))
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+		for i := 0; i < rows; i++ {
+			if _, err := stmt.Exec(args(i)...); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := insert(`INSERT INTO providers (provider_id, npi, provider_type, status, county) VALUES (?, ?, ?, ?, ?)`,
+		len(src.providers), func(i int) []interface{} {
+			p := src.providers[i]
+			return []interface{}{p.ProviderID, p.NPI, p.ProviderType, p.Status, p.County}
+		}); err != nil {
+		return fmt.Errorf("seeding providers: %w", err)
+	}
+	if err := insert(`INSERT INTO provider_networks (provider_id, network_id, effective_date, termination_date, termination_reason) VALUES (?, ?, ?, ?, ?)`,
+		len(src.providerNetwork), func(i int) []interface{} {
+			n := src.providerNetwork[i]
+			return []interface{}{n.ProviderID, n.NetworkID, n.EffectiveDate.UTC(), n.TerminationDate.UTC(), n.TerminationReason}
+		}); err != nil {
+		return fmt.Errorf("seeding provider networks: %w", err)
+	}
+	if err := insert(`INSERT INTO provider_service_locations (`+serviceLocationColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		len(src.providerServiceLocations), func(i int) []interface{} {
+			l := src.providerServiceLocations[i]
+			return []interface{}{l.LocationID, l.ProviderID, l.EffectiveDate.UTC(), l.TerminationDate.UTC(), l.Address1, l.Address2, l.City, l.ZipCode, l.County, l.Latitude, l.Longitude, l.CoordinateSource}
+		}); err != nil {
+		return fmt.Errorf("seeding service locations: %w", err)
+	}
+	if err := insert(`INSERT INTO county_claims (county, claims_count, avg_claim_amount) VALUES (?, ?, ?)`,
+		len(src.countyClaims), func(i int) []interface{} {
+			c := src.countyClaims[i]
+			return []interface{}{c.County, c.ClaimsCount, c.AvgClaimAmount}
+		}); err != nil {
+		return fmt.Errorf("seeding county claims: %w", err)
+	}
+	if err := insert(`INSERT INTO county_areas (county, area_sq_miles, latitude, longitude) VALUES (?, ?, ?, ?)`,
+		len(src.countyAreas), func(i int) []interface{} {
+			a := src.countyAreas[i]
+			return []interface{}{a.County, a.AreaSqMiles, a.Latitude, a.Longitude}
+		}); err != nil {
+		return fmt.Errorf("seeding county areas: %w", err)
+	}
+
+	specialties := make([]string, 0, len(src.specialtyDensityStandards))
+	for specialty := range src.specialtyDensityStandards {
+		specialties = append(specialties, specialty)
+	}
+	if err := insert(`INSERT INTO specialty_density_standards (specialty, providers_per_sq_mile) VALUES (?, ?)`,
+		len(specialties), func(i int) []interface{} {
+			return []interface{}{specialties[i], src.specialtyDensityStandards[specialties[i]]}
+		}); err != nil {
+		return fmt.Errorf("seeding specialty density standards: %w", err)
+	}
+	if err := insert(`INSERT INTO adequacy_standards (specialty, designation, max_minutes, max_miles) VALUES (?, ?, ?, ?)`,
+		len(src.adequacyStandards), func(i int) []interface{} {
+			a := src.adequacyStandards[i]
+			return []interface{}{a.Specialty, a.Designation, a.MaxMinutes, a.MaxMiles}
+		}); err != nil {
+		return fmt.Errorf("seeding adequacy standards: %w", err)
+	}
+	if err := insert(`INSERT INTO county_designations (county, designation) VALUES (?, ?)`,
+		len(src.countyDesignations), func(i int) []interface{} {
+			d := src.countyDesignations[i]
+			return []interface{}{d.County, d.Designation}
+		}); err != nil {
+		return fmt.Errorf("seeding county designations: %w", err)
+	}
+	geometries := make([]string, len(src.countyBoundaries))
+	for i, boundary := range src.countyBoundaries {
+		geometry, err := json.Marshal(boundary.Polygons)
+		if err != nil {
+			return fmt.Errorf("seeding county boundaries: %w", err)
+		}
+		geometries[i] = string(geometry)
+	}
+	if err := insert(`INSERT INTO county_boundaries (county, latitude, longitude, geometry) VALUES (?, ?, ?, ?)`,
+		len(src.countyBoundaries), func(i int) []interface{} {
+			b := src.countyBoundaries[i]
+			return []interface{}{b.County, b.Centroid.Latitude, b.Centroid.Longitude, geometries[i]}
+		}); err != nil {
+		return fmt.Errorf("seeding county boundaries: %w", err)
+	}
+	if err := insert(`INSERT INTO county_population (county, population) VALUES (?, ?)`,
+		len(src.countyPopulation), func(i int) []interface{} {
+			p := src.countyPopulation[i]
+			return []interface{}{p.County, p.Population}
+		}); err != nil {
+		return fmt.Errorf("seeding county population: %w", err)
+	}
+	if err := insert(`INSERT INTO zip_centroids (zip_code, city, county, latitude, longitude) VALUES (?, ?, ?, ?, ?)`,
+		len(src.zipCentroids), func(i int) []interface{} {
+			z := src.zipCentroids[i]
+			return []interface{}{z.ZipCode, z.City, z.County, z.Latitude, z.Longitude}
+		}); err != nil {
+		return fmt.Errorf("seeding ZIP centroids: %w", err)
+	}
+	if err := insert(`INSERT INTO city_centroids (city, county, latitude, longitude) VALUES (?, ?, ?, ?)`,
+		len(src.cityCentroids), func(i int) []interface{} {
+			c := src.cityCentroids[i]
+			return []interface{}{c.City, c.County, c.Latitude, c.Longitude}
+		}); err != nil {
+		return fmt.Errorf("seeding city centroids: %w", err)
+	}
+	var outOfStateNetworks [][]interface{}
+	if err := insert(`INSERT INTO out_of_state_locations (location_id, provider_id, npi, provider_type, effective_date, termination_date,
+			address1, address2, city, state, zip_code, county, latitude, longitude) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		len(src.outOfStateLocations), func(i int) []interface{} {
+			l := src.outOfStateLocations[i]
+			for _, n := range l.Networks {
+				outOfStateNetworks = append(outOfStateNetworks, []interface{}{l.LocationID, n.NetworkID, n.EffectiveDate.UTC(), n.TerminationDate.UTC()})
+			}
+			return []interface{}{l.LocationID, l.ProviderID, l.NPI, l.ProviderType, l.EffectiveDate.UTC(), l.TerminationDate.UTC(),
+				l.Address1, l.Address2, l.City, l.State, l.ZipCode, l.County, l.Latitude, l.Longitude}
+		}); err != nil {
+		return fmt.Errorf("seeding out-of-state locations: %w", err)
+	}
+	if err := insert(`INSERT INTO out_of_state_networks (location_id, network_id, effective_date, termination_date) VALUES (?, ?, ?, ?)`,
+		len(outOfStateNetworks), func(i int) []interface{} { return outOfStateNetworks[i] }); err != nil {
+		return fmt.Errorf("seeding out-of-state networks: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func scanProviders(rows *sql.Rows) ([]models.Provider, error) {
+	defer rows.Close()
+	var providers []models.Provider
+	for rows.Next() {
+		var p models.Provider
+		if err := rows.Scan(&p.ProviderID, &p.NPI, &p.ProviderType, &p.Status, &p.County); err != nil {
+			return nil, err
+		}
+		providers = append(providers, p)
+	}
+	return providers, rows.Err()
+}
+
+func (r *SQLRepository) GetProviders() ([]models.Provider, error) {
+	rows, err := r.query(`SELECT provider_id, npi, provider_type, status, county FROM providers ORDER BY provider_id`)
+	if err != nil {
+		return nil, err
+	}
+	return scanProviders(rows)
+}
+
+func (r *SQLRepository) GetProviderNetworks() ([]models.ProviderNetwork, error) {
+	rows, err := r.query(`SELECT provider_id, network_id, effective_date, termination_date, termination_reason
+		FROM provider_networks ORDER BY provider_id, network_id, effective_date`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var networks []models.ProviderNetwork
+	for rows.Next() {
+		var n models.ProviderNetwork
+		if err := rows.Scan(&n.ProviderID, &n.NetworkID, &n.EffectiveDate, &n.TerminationDate, &n.TerminationReason); err != nil {
+			return nil, err
+		}
+		networks = append(networks, n)
+	}
+	return networks, rows.Err()
+}
+
+func (r *SQLRepository) GetProviderServiceLocations() ([]models.ProviderServiceLocation, error) {
+	rows, err := r.query(`SELECT ` + serviceLocationColumns + ` FROM provider_service_locations ORDER BY provider_id, effective_date, location_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var locations []models.ProviderServiceLocation
+	for rows.Next() {
+		l, err := scanServiceLocation(rows)
+		if err != nil {
+			return nil, err
+		}
+		locations = append(locations, *l)
+	}
+	return locations, rows.Err()
+}
+
+// activeProviderLocations returns one service location in force at asOf per active provider, keyed
+// by county. An empty county returns every county.
+func (r *SQLRepository) activeProviderLocations(county string, asOf time.Time) (map[string][]models.ProviderServiceLocation, error) {
+	query := `SELECT l.provider_id, l.county, l.latitude, l.longitude
+		FROM provider_service_locations l
+		JOIN providers p ON p.provider_id = l.provider_id
+		WHERE p.status = ? AND p.county = l.county AND l.effective_date <= ? AND l.termination_date > ?`
+	args := []interface{}{"Active", asOf.UTC(), asOf.UTC()}
+	if county != "" {
+		query += ` AND l.county = ?`
+		args = append(args, county)
+	}
+	query += ` ORDER BY l.provider_id, l.effective_date`
+
+	rows, err := r.query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	locations := make(map[string][]models.ProviderServiceLocation)
+	seen := make(map[string]bool)
+	for rows.Next() {
+		var l models.ProviderServiceLocation
+		if err := rows.Scan(&l.ProviderID, &l.County, &l.Latitude, &l.Longitude); err != nil {
+			return nil, err
+		}
+		if seen[l.ProviderID] {
+			continue
+		}
+		seen[l.ProviderID] = true
+		locations[l.County] = append(locations[l.County], l)
+	}
+	return locations, rows.Err()
+}
+
+func (r *SQLRepository) countyAreas() (map[string]float64, error) {
+	rows, err := r.query(`SELECT county, area_sq_miles FROM county_areas`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	areas := make(map[string]float64)
+	for rows.Next() {
+		var county string
+		var area float64
+		if err := rows.Scan(&county, &area); err != nil {
+			return nil, err
+		}
+		areas[county] = area
+	}
+	return areas, rows.Err()
+}
+
+func (r *SQLRepository) countyClaims() ([]models.CountyClaims, error) {
+	rows, err := r.query(`SELECT county, claims_count, avg_claim_amount FROM county_claims ORDER BY county`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var claims []models.CountyClaims
+	for rows.Next() {
+		var c models.CountyClaims
+		if err := rows.Scan(&c.County, &c.ClaimsCount, &c.AvgClaimAmount); err != nil {
+			return nil, err
+		}
+		claims = append(claims, c)
+	}
+	return claims, rows.Err()
+}
+
+// GetDataQualityReport runs the data quality checks over the current table contents
+func (r *SQLRepository) GetDataQualityReport() (*models.DataQualityReport, error) {
+	providers, err := r.GetProviders()
+	if err != nil {
+		return nil, err
+	}
+	networks, err := r.GetProviderNetworks()
+	if err != nil {
+		return nil, err
+	}
+	locations, err := r.GetProviderServiceLocations()
+	if err != nil {
+		return nil, err
+	}
+	claims, err := r.countyClaims()
+	if err != nil {
+		return nil, err
+	}
+	areaByCounty, err := r.countyAreas()
+	if err != nil {
+		return nil, err
+	}
+	areas := make([]models.CountyArea, 0, len(areaByCounty))
+	for county, area := range areaByCounty {
+		areas = append(areas, models.CountyArea{County: county, AreaSqMiles: area})
+	}
+	boundaries, err := r.GetCountyBoundaries()
+	if err != nil {
+		return nil, err
+	}
+	geocoder, err := r.GetGeocoder()
+	if err != nil {
+		return nil, err
+	}
+	return checkDataQuality(providers, networks, locations, claims, areas, boundaries, geocoder), nil
+}
+
+func (r *SQLRepository) GetCountyArea(county string) float64 {
+	var area float64
+	err := r.queryRow(`SELECT area_sq_miles FROM county_areas WHERE county = ?`, county).Scan(&area)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("[ERROR] Failed to read area for county %s: %v", county, err)
+		}
+		// Fallback to average if county not found
+		return defaultCountyAreaSqMiles
+	}
+	return area
+}
+
+func (r *SQLRepository) GetSpecialtyDensityStandards() map[string]float64 {
+	standards := make(map[string]float64)
+	rows, err := r.query(`SELECT specialty, providers_per_sq_mile FROM specialty_density_standards`)
+	if err != nil {
+		log.Printf("[ERROR] Failed to read specialty density standards: %v", err)
+		return standards
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var specialty string
+		var density float64
+		if err := rows.Scan(&specialty, &density); err != nil {
+			log.Printf("[ERROR] Failed to read specialty density standards: %v", err)
+			return standards
+		}
+		standards[specialty] = density
+	}
+	return standards
+}
+
+func (r *SQLRepository) GetCountyStats(asOf time.Time) ([]models.CountyStats, error) {
+	rows, err := r.query(`SELECT c.county, c.claims_count, c.avg_claim_amount, COUNT(DISTINCT a.provider_id)
+		FROM county_claims c
+		LEFT JOIN (SELECT l.county, l.provider_id
+			FROM provider_service_locations l
+			JOIN providers p ON p.provider_id = l.provider_id
+			WHERE p.status = ? AND l.effective_date <= ? AND l.termination_date > ?) a ON a.county = c.county
+		GROUP BY c.county, c.claims_count, c.avg_claim_amount
+		ORDER BY c.county`, "Active", asOf.UTC(), asOf.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var countyStats []models.CountyStats
+	for rows.Next() {
+		var stats models.CountyStats
+		if err := rows.Scan(&stats.County, &stats.ClaimsCount, &stats.AvgClaimAmount, &stats.ProviderCount); err != nil {
+			return nil, err
+		}
+		countyStats = append(countyStats, stats)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	locations, err := r.activeProviderLocations("", asOf)
+	if err != nil {
+		return nil, err
+	}
+	areas, err := r.countyAreas()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range countyStats {
+		stats := &countyStats[i]
+		area, ok := areas[stats.County]
+		if !ok {
+			area = defaultCountyAreaSqMiles
+		}
+		stats.Density = providerDensity(stats.ProviderCount)
+		stats.DensityMiles = providerDensityMiles(stats.ProviderCount, averageNearestProviderDistance(locations[stats.County]), area)
+	}
+	return countyStats, nil
+}
+
+func (r *SQLRepository) GetCountyStatsByName(county string, asOf time.Time) (*models.CountyStats, error) {
+	stats := models.CountyStats{County: county}
+	err := r.queryRow(`SELECT claims_count, avg_claim_amount FROM county_claims WHERE county = ?`, county).
+		Scan(&stats.ClaimsCount, &stats.AvgClaimAmount)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.queryRow(`SELECT COUNT(DISTINCT l.provider_id)
+		FROM provider_service_locations l
+		JOIN providers p ON p.provider_id = l.provider_id
+		WHERE p.status = ? AND l.county = ? AND l.effective_date <= ? AND l.termination_date > ?`,
+		"Active", county, asOf.UTC(), asOf.UTC()).Scan(&stats.ProviderCount); err != nil {
+		return nil, err
+	}
+
+	locations, err := r.activeProviderLocations(county, asOf)
+	if err != nil {
+		return nil, err
+	}
+	stats.Density = providerDensity(stats.ProviderCount)
+	stats.DensityMiles = providerDensityMiles(stats.ProviderCount, averageNearestProviderDistance(locations[county]), r.GetCountyArea(county))
+	return &stats, nil
+}
+
+func (r *SQLRepository) GetProvidersInCounty(county string) ([]models.Provider, error) {
+	rows, err := r.query(`SELECT provider_id, npi, provider_type, status, county FROM providers WHERE county = ? ORDER BY provider_id`, county)
+	if err != nil {
+		return nil, err
+	}
+	return scanProviders(rows)
+}
+
+func (r *SQLRepository) GetFilteredProviders(filter models.FilterRequest, asOf time.Time) ([]models.Provider, error) {
+	query := `SELECT p.provider_id, p.npi, p.provider_type, p.status, p.county
+		FROM providers p
+		WHERE p.status = ?
+		AND EXISTS (SELECT 1 FROM provider_networks n
+			WHERE n.provider_id = p.provider_id AND n.network_id = ? AND n.effective_date <= ? AND n.termination_date > ?)`
+	args := []interface{}{"Active", filter.Network, asOf.UTC(), asOf.UTC()}
+
+	// Filter by specialty (skip if "All")
+	if filter.Specialty != "All" {
+		query += ` AND p.provider_type = ?`
+		args = append(args, filter.Specialty)
+	}
+	query += ` ORDER BY p.provider_id`
+
+	rows, err := r.query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return scanProviders(rows)
+}
+
+func (r *SQLRepository) GetActiveProviderCount() (int, error) {
+	var count int
+	err := r.queryRow(`SELECT COUNT(*) FROM providers WHERE status = ?`, "Active").Scan(&count)
+	return count, err
+}
+
+func (r *SQLRepository) GetActiveLocationProviders(county string, asOf time.Time) ([]models.Provider, error) {
+	query := `SELECT p.provider_id, p.npi, p.provider_type, p.status, p.county
+		FROM providers p
+		WHERE p.status = ?
+		AND EXISTS (SELECT 1 FROM provider_service_locations l
+			WHERE l.provider_id = p.provider_id AND l.effective_date <= ? AND l.termination_date > ?`
+	args := []interface{}{"Active", asOf.UTC(), asOf.UTC()}
+	if county != "" {
+		query += ` AND l.county = ?`
+		args = append(args, county)
+	}
+	query += `)
+		ORDER BY p.provider_id`
+
+	rows, err := r.query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return scanProviders(rows)
+}
+
+// terminationRows scans affiliation rows joined with the provider's specialty and adds those
+// matching criteria to a breakdown, returning it with the number of providers seen. With
+// perProvider set only each provider's first row is considered, and a provider without an
+// affiliation (a NULL network id) is counted as having none at all.
+func terminationRows(rows *sql.Rows, criteria models.TerminationCriteria, perProvider bool) (*models.TerminationBreakdown, int, error) {
+	defer rows.Close()
+
+	breakdown := newTerminationBreakdown()
+	seen := make(map[string]bool)
+	for rows.Next() {
+		var networkId, specialty, reason sql.NullString
+		var effective, termination sql.NullTime
+		var providerId string
+		if err := rows.Scan(&providerId, &specialty, &networkId, &effective, &termination, &reason); err != nil {
+			return nil, 0, err
+		}
+		if perProvider && seen[providerId] {
+			continue
+		}
+		seen[providerId] = true
+		if !networkId.Valid {
+			if perProvider {
+				addTermination(breakdown, config.NoAffiliationReason, specialty.String, time.Time{})
+			}
+			continue
+		}
+		network := models.ProviderNetwork{ProviderID: providerId, NetworkID: networkId.String,
+			EffectiveDate: effective.Time, TerminationDate: termination.Time, TerminationReason: reason.String}
+		if matchesTermination(network, criteria) {
+			addTermination(breakdown, network.TerminationReason, specialty.String, network.TerminationDate)
+		}
+	}
+	return breakdown, len(seen), rows.Err()
+}
+
+// reasonFilter returns a termination_reason condition on column and its arguments, or an empty
+// condition when every reason is accepted
+func reasonFilter(column string, reasons []string) (string, []interface{}) {
+	if len(reasons) == 0 {
+		return "", nil
+	}
+	placeholders := make([]string, len(reasons))
+	args := make([]interface{}, len(reasons))
+	for i, reason := range reasons {
+		placeholders[i] = "?"
+		args[i] = strings.ToLower(reason)
+	}
+	return ` AND LOWER(` + column + `) IN (` + strings.Join(placeholders, ", ") + `)`, args
+}
+
+// GetTerminatedNetworkBreakdown counts the providers who left the network in the window, or under
+// the legacy percentage every matching affiliation, as the JSON repository does
+func (r *SQLRepository) GetTerminatedNetworkBreakdown(networkId string, criteria models.TerminationCriteria) (*models.TerminationBreakdown, error) {
+	if !criteria.LegacyPercentage {
+		return r.terminationBreakdown("", networkId, criteria)
+	}
+	reasons, reasonArgs := reasonFilter("n.termination_reason", criteria.Reasons)
+	args := append([]interface{}{networkId, criteria.From.UTC(), criteria.To.UTC()}, reasonArgs...)
+
+	rows, err := r.query(`SELECT n.provider_id, p.provider_type, n.network_id, n.effective_date, n.termination_date, n.termination_reason
+		FROM provider_networks n
+		LEFT JOIN providers p ON p.provider_id = n.provider_id
+		WHERE n.network_id = ? AND n.termination_date > ? AND n.termination_date < ?`+reasons, args...)
+	if err != nil {
+		return nil, err
+	}
+	breakdown, _, err := terminationRows(rows, criteria, false)
+	return breakdown, err
+}
+
+// GetCountyTerminatedNetworkBreakdown mirrors the JSON repository: active providers with a service
+// location in force in the county, counted as terminated when they left the network in the analysis
+// window or, under the legacy percentage, when they have no affiliation row for it at all
+func (r *SQLRepository) GetCountyTerminatedNetworkBreakdown(county, networkId string, criteria models.TerminationCriteria) (int, *models.TerminationBreakdown, error) {
+	if !criteria.LegacyPercentage {
+		var totProvbyCounty int
+		if err := r.queryRow(`SELECT COUNT(DISTINCT p.provider_id)
+			FROM providers p
+			JOIN provider_service_locations l ON l.provider_id = p.provider_id
+			WHERE p.status = ? AND l.county = ? AND l.effective_date <= ? AND l.termination_date > ?`,
+			"Active", county, criteria.AsOf.UTC(), criteria.AsOf.UTC()).Scan(&totProvbyCounty); err != nil {
+			return 0, nil, err
+		}
+		breakdown, err := r.terminationBreakdown(county, networkId, criteria)
+		if err != nil {
+			return 0, nil, err
+		}
+		return totProvbyCounty, breakdown, nil
+	}
+
+	rows, err := r.query(`SELECT a.provider_id, a.provider_type, n.network_id, n.effective_date, n.termination_date, n.termination_reason
+		FROM (SELECT DISTINCT p.provider_id, p.provider_type
+			FROM providers p
+			JOIN provider_service_locations l ON l.provider_id = p.provider_id
+			WHERE p.status = ? AND l.county = ? AND l.effective_date <= ? AND l.termination_date > ?) a
+		LEFT JOIN provider_networks n ON n.provider_id = a.provider_id AND n.network_id = ?
+		ORDER BY a.provider_id, n.effective_date`,
+		"Active", county, criteria.AsOf.UTC(), criteria.AsOf.UTC(), networkId)
+	if err != nil {
+		return 0, nil, err
+	}
+	breakdown, totProvbyCounty, err := terminationRows(rows, criteria, true)
+	if err != nil {
+		return 0, nil, err
+	}
+	return totProvbyCounty, breakdown, nil
+}
+
+// terminationPopulation returns the affiliations with a network that a termination percentage is
+// taken over, restricted to the active providers with a service location in force in county when it
+// is given, together with each provider's specialty
+func (r *SQLRepository) terminationPopulation(county, networkId string, criteria models.TerminationCriteria) ([]models.ProviderNetwork, map[string]string, error) {
+	query := `SELECT n.provider_id, p.provider_type, n.network_id, n.effective_date, n.termination_date, n.termination_reason
+		FROM provider_networks n
+		LEFT JOIN providers p ON p.provider_id = n.provider_id
+		WHERE n.network_id = ?`
+	args := []interface{}{networkId}
+	if county != "" {
+		query += ` AND n.provider_id IN (SELECT p.provider_id
+			FROM providers p
+			JOIN provider_service_locations l ON l.provider_id = p.provider_id
+			WHERE p.status = ? AND l.county = ? AND l.effective_date <= ? AND l.termination_date > ?)`
+		args = append(args, "Active", county, criteria.AsOf.UTC(), criteria.AsOf.UTC())
+	}
+	query += ` ORDER BY n.provider_id, n.effective_date`
+
+	rows, err := r.query(query, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var networks []models.ProviderNetwork
+	specialties := make(map[string]string)
+	for rows.Next() {
+		var n models.ProviderNetwork
+		var specialty sql.NullString
+		if err := rows.Scan(&n.ProviderID, &specialty, &n.NetworkID, &n.EffectiveDate, &n.TerminationDate, &n.TerminationReason); err != nil {
+			return nil, nil, err
+		}
+		networks = append(networks, n)
+		specialties[n.ProviderID] = specialty.String
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	return networks, specialties, nil
+}
+
+// terminationBreakdown breaks down the providers GetTerminationRate counts in its numerator
+func (r *SQLRepository) terminationBreakdown(county, networkId string, criteria models.TerminationCriteria) (*models.TerminationBreakdown, error) {
+	networks, specialties, err := r.terminationPopulation(county, networkId, criteria)
+	if err != nil {
+		return nil, err
+	}
+	return terminationBreakdown(networks, func(providerId string) string { return specialties[providerId] }, criteria), nil
+}
+
+func (r *SQLRepository) GetTerminationRate(county, networkId string, criteria models.TerminationCriteria) (int, int, error) {
+	networks, _, err := r.terminationPopulation(county, networkId, criteria)
+	if err != nil {
+		return 0, 0, err
+	}
+	numerator, denominator := terminationRate(networks, criteria)
+	return numerator, denominator, nil
+}
+
+func (r *SQLRepository) GetTerminatedServiceLocationCount(networkId string, criteria models.TerminationCriteria) (int, error) {
+	reasons, reasonArgs := reasonFilter("n.termination_reason", criteria.Reasons)
+	args := append([]interface{}{criteria.From.UTC(), criteria.To.UTC(), networkId}, reasonArgs...)
+	args = append(args, criteria.From.UTC(), criteria.To.UTC())
+
+	var count int
+	err := r.queryRow(`SELECT COUNT(*) FROM provider_service_locations l
+		WHERE l.termination_date > ? AND l.termination_date < ?
+		AND l.provider_id IN (SELECT n.provider_id FROM provider_networks n
+			WHERE n.network_id = ?`+reasons+`
+			AND n.termination_date > ? AND n.termination_date < ?)`, args...).Scan(&count)
+	return count, err
+}
+
+func (r *SQLRepository) GetRadiusAnalysis(county string, center *models.Coordinate, radius int, networkId string, asOf time.Time, includeOutOfState bool) (map[string]interface{}, error) {
+	if center == nil {
+		var centroid models.Coordinate
+		err := r.queryRow(`SELECT latitude, longitude FROM county_areas WHERE county = ?`, county).
+			Scan(&centroid.Latitude, &centroid.Longitude)
+		if err == sql.ErrNoRows || (err == nil && centroid.Latitude == 0 && centroid.Longitude == 0) {
+			return nil, errNoCentroid(county)
+		}
+		if err != nil {
+			return nil, err
+		}
+		center = &centroid
+	}
+
+	// Narrow to the bounding box in SQL, then keep the locations truly within the radius
+	minLat, maxLat, minLng, maxLng := radiusBoundingBox(*center, float64(radius))
+	rows, err := r.query(`SELECT l.provider_id, p.provider_type, l.county, l.latitude, l.longitude
+		FROM provider_service_locations l
+		JOIN providers p ON p.provider_id = l.provider_id
+		WHERE p.status = ? AND l.effective_date <= ? AND l.termination_date > ?
+		AND l.latitude BETWEEN ? AND ? AND l.longitude BETWEEN ? AND ?
+		AND EXISTS (SELECT 1 FROM provider_networks n
+			WHERE n.provider_id = p.provider_id AND n.network_id = ? AND n.effective_date <= ? AND n.termination_date > ?)`,
+		"Active", asOf.UTC(), asOf.UTC(), minLat, maxLat, minLng, maxLng, networkId, asOf.UTC(), asOf.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var matches []radiusMatch
+	for rows.Next() {
+		var match radiusMatch
+		var location models.ProviderServiceLocation
+		if err := rows.Scan(&match.providerID, &match.specialty, &match.county, &location.Latitude, &location.Longitude); err != nil {
+			return nil, err
+		}
+		if !hasCoordinates(location) {
+			continue
+		}
+		match.distance = haversineDistance(center.Latitude, center.Longitude, location.Latitude, location.Longitude)
+		if match.distance <= float64(radius) {
+			matches = append(matches, match)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if includeOutOfState {
+		locations, err := r.outOfStateNetworkLocations(networkId, "All", asOf, &[4]float64{minLat, maxLat, minLng, maxLng})
+		if err != nil {
+			return nil, err
+		}
+		for _, location := range locations {
+			distance := haversineDistance(center.Latitude, center.Longitude, location.Latitude, location.Longitude)
+			if distance <= float64(radius) {
+				matches = append(matches, outOfStateRadiusMatch(location, distance))
+			}
+		}
+	}
+
+	result := radiusAnalysisResult(county, *center, radius, networkId, includeOutOfState, matches)
+
+	var claims models.CountyClaims
+	err = r.queryRow(`SELECT claims_count, avg_claim_amount FROM county_claims WHERE county = ?`, county).
+		Scan(&claims.ClaimsCount, &claims.AvgClaimAmount)
+	switch {
+	case err == nil:
+		result["claims_count"] = claims.ClaimsCount
+		result["avg_claim_amount"] = claims.AvgClaimAmount
+	case err != sql.ErrNoRows:
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetNearestProviders searches a bounding box around the centre, doubling its radius until it holds
+// enough locations within that radius, so only rows near the centre are read
+func (r *SQLRepository) GetNearestProviders(query models.NearestProviderQuery) ([]models.NearestProvider, error) {
+	if query.Limit <= 0 {
+		return []models.NearestProvider{}, nil
+	}
+	specialty, specialtyArgs := "", []interface{}{}
+	if query.Specialty != "All" {
+		specialty, specialtyArgs = " AND p.provider_type = ?", []interface{}{query.Specialty}
+	}
+
+	// Half the Earth's circumference, beyond which the bounding box already spans the globe
+	maxRadius := earthRadiusMiles * math.Pi
+	for radius := float64(nearestSearchStartMiles); ; radius *= 2 {
+		minLat, maxLat, minLng, maxLng := radiusBoundingBox(query.Center, radius)
+		args := append([]interface{}{"Active", query.AsOf.UTC(), query.AsOf.UTC(), minLat, maxLat, minLng, maxLng}, specialtyArgs...)
+		args = append(args, query.Network, query.AsOf.UTC(), query.AsOf.UTC())
+		rows, err := r.query(`SELECT p.provider_id, p.npi, p.provider_type, l.location_id, l.address1, l.address2,
+				l.city, l.zip_code, l.county, l.latitude, l.longitude
+			FROM provider_service_locations l
+			JOIN providers p ON p.provider_id = l.provider_id
+			WHERE p.status = ? AND l.effective_date <= ? AND l.termination_date > ?
+			AND l.latitude BETWEEN ? AND ? AND l.longitude BETWEEN ? AND ?`+specialty+`
+			AND EXISTS (SELECT 1 FROM provider_networks n
+				WHERE n.provider_id = p.provider_id AND n.network_id = ? AND n.effective_date <= ? AND n.termination_date > ?)`,
+			args...)
+		if err != nil {
+			return nil, err
+		}
+
+		var matches []nearestMatch
+		for rows.Next() {
+			var m nearestMatch
+			if err := rows.Scan(&m.provider.ProviderID, &m.provider.NPI, &m.provider.ProviderType, &m.location.LocationID,
+				&m.location.Address1, &m.location.Address2, &m.location.City, &m.location.ZipCode, &m.location.County,
+				&m.location.Latitude, &m.location.Longitude); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			if !hasCoordinates(m.location) {
+				continue
+			}
+			// Corners of the box lie beyond the radius and may hide closer locations outside it
+			m.distance = haversineDistance(query.Center.Latitude, query.Center.Longitude, m.location.Latitude, m.location.Longitude)
+			if m.distance <= radius || radius >= maxRadius {
+				matches = append(matches, m)
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		if query.IncludeOutOfState {
+			locations, err := r.outOfStateNetworkLocations(query.Network, query.Specialty, query.AsOf, &[4]float64{minLat, maxLat, minLng, maxLng})
+			if err != nil {
+				return nil, err
+			}
+			for _, location := range locations {
+				distance := haversineDistance(query.Center.Latitude, query.Center.Longitude, location.Latitude, location.Longitude)
+				if distance <= radius || radius >= maxRadius {
+					matches = append(matches, outOfStateNearestMatch(location, distance))
+				}
+			}
+		}
+
+		if len(matches) >= query.Limit || radius >= maxRadius {
+			return nearestResults(matches, query.Limit), nil
+		}
+	}
+}
+
+func (r *SQLRepository) GetAdequacyStandards() ([]models.AdequacyStandard, error) {
+	rows, err := r.query(`SELECT specialty, designation, max_minutes, max_miles FROM adequacy_standards ORDER BY specialty, designation`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var standards []models.AdequacyStandard
+	for rows.Next() {
+		var s models.AdequacyStandard
+		if err := rows.Scan(&s.Specialty, &s.Designation, &s.MaxMinutes, &s.MaxMiles); err != nil {
+			return nil, err
+		}
+		standards = append(standards, s)
+	}
+	return standards, rows.Err()
+}
+
+func (r *SQLRepository) GetCountyDesignations() ([]models.CountyDesignation, error) {
+	rows, err := r.query(`SELECT county, designation FROM county_designations ORDER BY county`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var designations []models.CountyDesignation
+	for rows.Next() {
+		var d models.CountyDesignation
+		if err := rows.Scan(&d.County, &d.Designation); err != nil {
+			return nil, err
+		}
+		designations = append(designations, d)
+	}
+	return designations, rows.Err()
+}
+
+func (r *SQLRepository) GetCountySamplePoints(county string, gridSize int) ([]models.Coordinate, error) {
+	boundary, err := r.GetCountyBoundary(county)
+	if err == nil {
+		points, _ := newCountyLocator([]models.CountyBoundary{*boundary}).samplePoints(county, gridSize)
+		return points, nil
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	var centroid models.Coordinate
+	var area float64
+	err = r.queryRow(`SELECT area_sq_miles, latitude, longitude FROM county_areas WHERE county = ?`, county).
+		Scan(&area, &centroid.Latitude, &centroid.Longitude)
+	if err == sql.ErrNoRows || (err == nil && centroid.Latitude == 0 && centroid.Longitude == 0) {
+		return nil, errNoCentroid(county)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return countySamplePoints(centroid, area, gridSize), nil
+}
+
+func (r *SQLRepository) GetNearestProviderDistances(points []models.Coordinate, specialty, networkId string, asOf time.Time, includeOutOfState bool) ([]float64, error) {
+	// Every point needs a nearest location however far away, so index them all rather than searching boxes per point
+	locations, err := r.geocodedNetworkLocations(specialty, networkId, asOf)
+	if err != nil {
+		return nil, err
+	}
+	distances := nearestDistances(newSpatialIndex(locationCoordinates(locations)), points, func(int) bool { return true })
+	if includeOutOfState {
+		outOfState, err := r.outOfStateNetworkLocations(networkId, specialty, asOf, nil)
+		if err != nil {
+			return nil, err
+		}
+		keepNearer(distances, nearestDistances(newSpatialIndex(outOfStateCoordinates(outOfState)), points, func(int) bool { return true }))
+	}
+	return distances, nil
+}
+
+func (r *SQLRepository) GetProviderLocationsWithin(points []models.Coordinate, radiusMiles float64, specialty, networkId string, asOf time.Time) ([][]models.LocationDistance, error) {
+	locations, err := r.geocodedNetworkLocations(specialty, networkId, asOf)
+	if err != nil {
+		return nil, err
+	}
+	locationID := func(id int) string { return locations[id].LocationID }
+	return locationsWithin(newSpatialIndex(locationCoordinates(locations)), points, radiusMiles, func(int) bool { return true }, locationID), nil
+}
+
+// geocodedNetworkLocations returns the ID and coordinates of every geocoded location in force of
+// an active network provider with the specialty
+func (r *SQLRepository) geocodedNetworkLocations(specialty, networkId string, asOf time.Time) ([]models.ProviderServiceLocation, error) {
+	filter, args := "", []interface{}{"Active", asOf.UTC(), asOf.UTC()}
+	if specialty != "All" {
+		filter, args = " AND p.provider_type = ?", append(args, specialty)
+	}
+	args = append(args, networkId, asOf.UTC(), asOf.UTC())
+	rows, err := r.query(`SELECT l.location_id, l.latitude, l.longitude
+		FROM provider_service_locations l
+		JOIN providers p ON p.provider_id = l.provider_id
+		WHERE p.status = ? AND l.effective_date <= ? AND l.termination_date > ?`+filter+`
+		AND EXISTS (SELECT 1 FROM provider_networks n
+			WHERE n.provider_id = p.provider_id AND n.network_id = ? AND n.effective_date <= ? AND n.termination_date > ?)`,
+		args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var locations []models.ProviderServiceLocation
+	for rows.Next() {
+		var location models.ProviderServiceLocation
+		if err := rows.Scan(&location.LocationID, &location.Latitude, &location.Longitude); err != nil {
+			return nil, err
+		}
+		if hasCoordinates(location) {
+			locations = append(locations, location)
+		}
+	}
+	return locations, rows.Err()
+}
+
+// outOfStateNetworkLocations returns the out-of-state locations in force at asOf of the specialty
+// that are in the network then, without their networks. A non-nil box narrows them to a minimum
+// and maximum latitude and longitude.
+func (r *SQLRepository) outOfStateNetworkLocations(networkId, specialty string, asOf time.Time, box *[4]float64) ([]models.OutOfStateLocation, error) {
+	filter, args := "", []interface{}{asOf.UTC(), asOf.UTC()}
+	if specialty != "All" {
+		filter, args = " AND l.provider_type = ?", append(args, specialty)
+	}
+	if box != nil {
+		filter += " AND l.latitude BETWEEN ? AND ? AND l.longitude BETWEEN ? AND ?"
+		args = append(args, box[0], box[1], box[2], box[3])
+	}
+	if networkId != "" {
+		filter += ` AND EXISTS (SELECT 1 FROM out_of_state_networks n
+			WHERE n.location_id = l.location_id AND n.network_id = ? AND n.effective_date <= ? AND n.termination_date > ?)`
+		args = append(args, networkId, asOf.UTC(), asOf.UTC())
+	}
+	rows, err := r.query(`SELECT l.location_id, l.provider_id, l.npi, l.provider_type, l.address1, l.address2,
+			l.city, l.state, l.zip_code, l.county, l.latitude, l.longitude
+		FROM out_of_state_locations l
+		WHERE l.effective_date <= ? AND l.termination_date > ?`+filter, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var locations []models.OutOfStateLocation
+	for rows.Next() {
+		var l models.OutOfStateLocation
+		if err := rows.Scan(&l.LocationID, &l.ProviderID, &l.NPI, &l.ProviderType, &l.Address1, &l.Address2,
+			&l.City, &l.State, &l.ZipCode, &l.County, &l.Latitude, &l.Longitude); err != nil {
+			return nil, err
+		}
+		locations = append(locations, l)
+	}
+	return locations, rows.Err()
+}
+
+func locationCoordinates(locations []models.ProviderServiceLocation) []models.Coordinate {
+	coordinates := make([]models.Coordinate, len(locations))
+	for i, location := range locations {
+		coordinates[i] = models.Coordinate{Latitude: location.Latitude, Longitude: location.Longitude}
+	}
+	return coordinates
+}
+
+func (r *SQLRepository) GetCountyPopulation() ([]models.CountyPopulation, error) {
+	rows, err := r.query(`SELECT county, population FROM county_population ORDER BY county`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var population []models.CountyPopulation
+	for rows.Next() {
+		var p models.CountyPopulation
+		if err := rows.Scan(&p.County, &p.Population); err != nil {
+			return nil, err
+		}
+		population = append(population, p)
+	}
+	return population, rows.Err()
+}
+
+func (r *SQLRepository) GetZipCentroids() ([]models.ZipCentroid, error) {
+	rows, err := r.query(`SELECT zip_code, city, county, latitude, longitude FROM zip_centroids ORDER BY zip_code`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var zips []models.ZipCentroid
+	for rows.Next() {
+		var z models.ZipCentroid
+		if err := rows.Scan(&z.ZipCode, &z.City, &z.County, &z.Latitude, &z.Longitude); err != nil {
+			return nil, err
+		}
+		zips = append(zips, z)
+	}
+	return zips, rows.Err()
+}
+
+func (r *SQLRepository) GetGeocoder() (*Geocoder, error) {
+	zips, err := r.GetZipCentroids()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.query(`SELECT city, county, latitude, longitude FROM city_centroids ORDER BY city, county`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cities []models.CityCentroid
+	for rows.Next() {
+		var c models.CityCentroid
+		if err := rows.Scan(&c.City, &c.County, &c.Latitude, &c.Longitude); err != nil {
+			return nil, err
+		}
+		cities = append(cities, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return NewGeocoder(zips, cities), nil
+}
+
+func (r *SQLRepository) GetCountyBoundaries() ([]models.CountyBoundary, error) {
+	rows, err := r.query(`SELECT county, latitude, longitude, geometry FROM county_boundaries ORDER BY county`)
+	if err != nil {
+		return nil, err
+	}
+	return scanCountyBoundaries(rows)
+}
+
+func (r *SQLRepository) GetCountyBoundary(county string) (*models.CountyBoundary, error) {
+	rows, err := r.query(`SELECT county, latitude, longitude, geometry FROM county_boundaries WHERE county = ?`, county)
+	if err != nil {
+		return nil, err
+	}
+	boundaries, err := scanCountyBoundaries(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(boundaries) == 0 {
+		return nil, fmt.Errorf("boundary for county %s %w", county, ErrNotFound)
+	}
+	return &boundaries[0], nil
+}
+
+func scanCountyBoundaries(rows *sql.Rows) ([]models.CountyBoundary, error) {
+	defer rows.Close()
+	var boundaries []models.CountyBoundary
+	for rows.Next() {
+		var b models.CountyBoundary
+		var geometry string
+		if err := rows.Scan(&b.County, &b.Centroid.Latitude, &b.Centroid.Longitude, &geometry); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(geometry), &b.Polygons); err != nil {
+			return nil, fmt.Errorf("reading boundary of county %s: %w", b.County, err)
+		}
+		boundaries = append(boundaries, b)
+	}
+	return boundaries, rows.Err()
+}
+
+func (r *SQLRepository) GetNetworkProviderLocations(networkId, specialty string, asOf time.Time) ([]models.ProviderLocation, error) {
+	filter, args := "", []interface{}{"Active", asOf.UTC(), asOf.UTC()}
+	if specialty != "All" {
+		filter, args = " AND p.provider_type = ?", append(args, specialty)
+	}
+	if networkId != "" {
+		filter += ` AND EXISTS (SELECT 1 FROM provider_networks n
+			WHERE n.provider_id = p.provider_id AND n.network_id = ? AND n.effective_date <= ? AND n.termination_date > ?)`
+		args = append(args, networkId, asOf.UTC(), asOf.UTC())
+	}
+	rows, err := r.query(`SELECT p.provider_id, p.npi, p.provider_type, l.location_id, l.address1, l.address2,
+			l.city, l.zip_code, l.county, l.latitude, l.longitude
+		FROM provider_service_locations l
+		JOIN providers p ON p.provider_id = l.provider_id
+		WHERE p.status = ? AND l.effective_date <= ? AND l.termination_date > ?`+filter, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	locations := []models.ProviderLocation{}
+	for rows.Next() {
+		var l models.ProviderLocation
+		if err := rows.Scan(&l.ProviderID, &l.NPI, &l.Specialty, &l.LocationID, &l.Address1, &l.Address2,
+			&l.City, &l.ZipCode, &l.County, &l.Latitude, &l.Longitude); err != nil {
+			return nil, err
+		}
+		locations = append(locations, l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	sortProviderLocations(locations)
+	return locations, nil
+}
+
+func (r *SQLRepository) GetCoverageGrid(cellMiles float64) ([]models.GridCell, error) {
+	boundaries, err := r.GetCountyBoundaries()
+	if err != nil {
+		return nil, err
+	}
+	return coverageGrid(newCountyLocator(boundaries), cellMiles), nil
+}
+
+func (r *SQLRepository) GetCatchments(networkId, specialty string, asOf time.Time) ([]models.Catchment, error) {
+	locations, err := r.GetNetworkProviderLocations(networkId, specialty, asOf)
+	if err != nil {
+		return nil, err
+	}
+	boundaries, err := r.GetCountyBoundaries()
+	if err != nil {
+		return nil, err
+	}
+	return catchments(newCountyLocator(boundaries), locations), nil
+}
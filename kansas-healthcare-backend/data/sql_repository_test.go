@@ -0,0 +1,194 @@
+package data
+
+import (
+	"kansas-healthcare-api/config"
+	"kansas-healthcare-api/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fixtureAsOf is the date the fixture's relative termination dates are measured from
+var fixtureAsOf = time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+
+// leftNetworkCriteria selects the default terminated analysis: providers who left in the 2-5 years before asOf
+func leftNetworkCriteria(asOf time.Time) models.TerminationCriteria {
+	from, to := config.TerminatedAnalysisTimeRange(asOf)
+	return models.TerminationCriteria{AsOf: asOf, From: from, To: to, Reasons: []string{config.LeftNetworkReason}}
+}
+
+func newFixtureJSONRepository() *JSONRepository {
+	now := fixtureAsOf
+	open := time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+	joined := time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	return newJSONRepositoryFrom(&jsonSnapshot{
+		providers: []models.Provider{
+			{ProviderID: "P1", NPI: "1001", ProviderType: "Primary Care", Status: "Active", County: "Sedgwick"},
+			{ProviderID: "P2", NPI: "1002", ProviderType: "Cardiology", Status: "Active", County: "Sedgwick"},
+			{ProviderID: "P3", NPI: "1003", ProviderType: "Primary Care", Status: "Terminated", County: "Sedgwick"},
+			{ProviderID: "P4", NPI: "1004", ProviderType: "Primary Care", Status: "Active", County: "Johnson"},
+		},
+		providerNetwork: []models.ProviderNetwork{
+			{ProviderID: "P1", NetworkID: "Commercial", EffectiveDate: joined, TerminationDate: open},
+			{ProviderID: "P2", NetworkID: "Commercial", EffectiveDate: joined, TerminationDate: now.AddDate(-3, 0, 0), TerminationReason: "Left Network"},
+			{ProviderID: "P3", NetworkID: "Commercial", EffectiveDate: joined, TerminationDate: now.AddDate(-4, 0, 0), TerminationReason: "Left Network"},
+			{ProviderID: "P4", NetworkID: "Commercial", EffectiveDate: joined, TerminationDate: open},
+			{ProviderID: "P4", NetworkID: "Medicare", EffectiveDate: joined, TerminationDate: open},
+		},
+		providerServiceLocations: []models.ProviderServiceLocation{
+			{ProviderID: "P1", EffectiveDate: joined, TerminationDate: open, City: "Wichita", County: "Sedgwick", Latitude: 37.6872, Longitude: -97.3301},
+			{ProviderID: "P2", EffectiveDate: joined, TerminationDate: open, City: "Derby", County: "Sedgwick", Latitude: 37.5447, Longitude: -97.2689},
+			{ProviderID: "P3", EffectiveDate: joined, TerminationDate: now.AddDate(-4, 0, 0), City: "Wichita", County: "Sedgwick", Latitude: 37.69, Longitude: -97.33},
+		},
+		countyClaims: []models.CountyClaims{
+			{County: "Johnson", ClaimsCount: 4000, AvgClaimAmount: 300},
+			{County: "Sedgwick", ClaimsCount: 5000, AvgClaimAmount: 250.5},
+		},
+		countyAreas: []models.CountyArea{
+			{County: "Johnson", AreaSqMiles: 480.0, Latitude: 38.884, Longitude: -94.823},
+			{County: "Sedgwick", AreaSqMiles: 1009.8, Latitude: 37.684, Longitude: -97.461},
+		},
+		specialtyDensityStandards: map[string]float64{"Primary Care": 2.5, "Cardiology": 0.6},
+	})
+}
+
+func newSQLiteTestRepository(t *testing.T, src *JSONRepository) *SQLRepository {
+	db, err := OpenDatabase(DialectSQLite, ":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	repo, err := NewSQLRepository(db, DialectSQLite)
+	require.NoError(t, err)
+	require.NoError(t, repo.SeedFromJSON(src))
+	return repo
+}
+
+func TestSQLRepositoryMigrationsAreIdempotent(t *testing.T) {
+	db, err := OpenDatabase(DialectSQLite, ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = NewSQLRepository(db, DialectSQLite)
+	require.NoError(t, err)
+	_, err = NewSQLRepository(db, DialectSQLite)
+	require.NoError(t, err)
+
+	var version int
+	require.NoError(t, db.QueryRow(`SELECT MAX(version) FROM schema_migrations`).Scan(&version))
+	assert.Equal(t, migrations[len(migrations)-1].version, version)
+}
+
+func TestRebind(t *testing.T) {
+	query := `SELECT * FROM providers WHERE county = ? AND status = ?`
+	assert.Equal(t, query, rebind(DialectSQLite, query))
+	assert.Equal(t, `SELECT * FROM providers WHERE county = $1 AND status = $2`, rebind(DialectPostgres, query))
+}
+
+func TestSQLRepositoryMatchesJSONRepository(t *testing.T) {
+	jsonRepo := newFixtureJSONRepository()
+	sqlRepo := newSQLiteTestRepository(t, jsonRepo)
+
+	// Before anyone joined, inside the window and after the terminations aged out of it
+	for _, asOf := range []time.Time{time.Date(2014, 6, 30, 0, 0, 0, 0, time.UTC), fixtureAsOf, fixtureAsOf.AddDate(3, 0, 0)} {
+		expectedStats, _ := jsonRepo.GetCountyStats(asOf)
+		stats, err := sqlRepo.GetCountyStats(asOf)
+		assert.NoError(t, err)
+		assert.Equal(t, expectedStats, stats)
+
+		expectedCounty, _ := jsonRepo.GetCountyStatsByName("Sedgwick", asOf)
+		county, err := sqlRepo.GetCountyStatsByName("Sedgwick", asOf)
+		assert.NoError(t, err)
+		assert.Equal(t, expectedCounty, county)
+
+		missing, err := sqlRepo.GetCountyStatsByName("Nowhere", asOf)
+		assert.NoError(t, err)
+		assert.Nil(t, missing)
+
+		filter := models.FilterRequest{Specialty: "All", Network: "Commercial"}
+		expectedFiltered, _ := jsonRepo.GetFilteredProviders(filter, asOf)
+		filtered, err := sqlRepo.GetFilteredProviders(filter, asOf)
+		assert.NoError(t, err)
+		assert.Equal(t, expectedFiltered, filtered)
+
+		for _, criteria := range []models.TerminationCriteria{
+			leftNetworkCriteria(asOf),
+			{AsOf: asOf, From: asOf.AddDate(-10, 0, 0), To: asOf, Reasons: []string{"left network", "Retired"}},
+			{AsOf: asOf, From: asOf.AddDate(-10, 0, 0), To: asOf},
+		} {
+			expectedTerminated, _ := jsonRepo.GetTerminatedNetworkBreakdown("Commercial", criteria)
+			terminated, err := sqlRepo.GetTerminatedNetworkBreakdown("Commercial", criteria)
+			assert.NoError(t, err)
+			assert.Equal(t, expectedTerminated, terminated)
+
+			expectedLocations, _ := jsonRepo.GetTerminatedServiceLocationCount("Commercial", criteria)
+			locations, err := sqlRepo.GetTerminatedServiceLocationCount("Commercial", criteria)
+			assert.NoError(t, err)
+			assert.Equal(t, expectedLocations, locations)
+
+			for _, county := range []string{"", "Sedgwick"} {
+				for _, denominator := range []string{config.DenominatorInWindow, config.DenominatorAtWindowStart} {
+					criteria.Denominator = denominator
+					expectedNumerator, expectedDenominator, _ := jsonRepo.GetTerminationRate(county, "Commercial", criteria)
+					numerator, denominator, err := sqlRepo.GetTerminationRate(county, "Commercial", criteria)
+					assert.NoError(t, err)
+					assert.Equal(t, expectedNumerator, numerator)
+					assert.Equal(t, expectedDenominator, denominator)
+				}
+			}
+
+			expectedTotal, expectedTerm, _ := jsonRepo.GetCountyTerminatedNetworkBreakdown("Sedgwick", "Commercial", criteria)
+			total, term, err := sqlRepo.GetCountyTerminatedNetworkBreakdown("Sedgwick", "Commercial", criteria)
+			assert.NoError(t, err)
+			assert.Equal(t, expectedTotal, total)
+			assert.Equal(t, expectedTerm, term)
+		}
+
+		for _, county := range []string{"", "Sedgwick", "Johnson"} {
+			expectedProviders, _ := jsonRepo.GetActiveLocationProviders(county, asOf)
+			providers, err := sqlRepo.GetActiveLocationProviders(county, asOf)
+			assert.NoError(t, err)
+			assert.Equal(t, expectedProviders, providers)
+		}
+
+		for _, center := range []*models.Coordinate{nil, {Latitude: 37.55, Longitude: -97.27}} {
+			expectedRadius, _ := jsonRepo.GetRadiusAnalysis("Sedgwick", center, 25, "Commercial", asOf, false)
+			radius, err := sqlRepo.GetRadiusAnalysis("Sedgwick", center, 25, "Commercial", asOf, false)
+			assert.NoError(t, err)
+			assert.Equal(t, expectedRadius, radius)
+		}
+	}
+
+	expectedActive, _ := jsonRepo.GetActiveProviderCount()
+	active, err := sqlRepo.GetActiveProviderCount()
+	assert.NoError(t, err)
+	assert.Equal(t, expectedActive, active)
+
+	assert.Equal(t, jsonRepo.GetCountyArea("Sedgwick"), sqlRepo.GetCountyArea("Sedgwick"))
+	assert.Equal(t, defaultCountyAreaSqMiles, sqlRepo.GetCountyArea("Nowhere"))
+	assert.Equal(t, jsonRepo.GetSpecialtyDensityStandards(), sqlRepo.GetSpecialtyDensityStandards())
+}
+
+func TestSQLRepositoryRoundTripsRows(t *testing.T) {
+	jsonRepo := newFixtureJSONRepository()
+	sqlRepo := newSQLiteTestRepository(t, jsonRepo)
+
+	networks, err := sqlRepo.GetProviderNetworks()
+	assert.NoError(t, err)
+	assert.Len(t, networks, len(jsonRepo.snapshot().providerNetwork))
+	for _, n := range networks {
+		assert.False(t, n.EffectiveDate.IsZero())
+		assert.False(t, n.TerminationDate.IsZero())
+	}
+
+	locations, err := sqlRepo.GetProviderServiceLocations()
+	assert.NoError(t, err)
+	assert.Len(t, locations, len(jsonRepo.snapshot().providerServiceLocations))
+	assert.Equal(t, 9999, locations[0].TerminationDate.Year())
+
+	inCounty, err := sqlRepo.GetProvidersInCounty("Sedgwick")
+	assert.NoError(t, err)
+	assert.Len(t, inCounty, 3)
+}
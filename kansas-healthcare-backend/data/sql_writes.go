@@ -0,0 +1,304 @@
+package data
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"kansas-healthcare-api/models"
+	"time"
+
+	"github.com/lib/pq"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// serviceLocationColumns lists provider_service_locations columns in the order scanServiceLocation reads them
+const serviceLocationColumns = `location_id, provider_id, effective_date, termination_date, address1, address2, city, zip_code, county, latitude, longitude, coordinate_source`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanServiceLocation(row rowScanner) (*models.ProviderServiceLocation, error) {
+	var l models.ProviderServiceLocation
+	if err := row.Scan(&l.LocationID, &l.ProviderID, &l.EffectiveDate, &l.TerminationDate, &l.Address1, &l.Address2,
+		&l.City, &l.ZipCode, &l.County, &l.Latitude, &l.Longitude, &l.CoordinateSource); err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+// inTx runs fn in a transaction, committing only if it succeeds
+func (r *SQLRepository) inTx(fn func(tx *sql.Tx) error) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// querier is satisfied by both *sql.DB and *sql.Tx
+type querier interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// selectQuery rebinds a single-row SELECT, optionally locking the row until the transaction ends
+// so a version check and the write that follows it cannot interleave with another writer. SQLite
+// has no row locks but serializes writers on its single connection, so the clause is PostgreSQL only.
+func (r *SQLRepository) selectQuery(query string, lock bool) string {
+	if lock && r.dialect == DialectPostgres {
+		query += ` FOR UPDATE`
+	}
+	return rebind(r.dialect, query)
+}
+
+func (r *SQLRepository) exec(tx *sql.Tx, query string, args ...interface{}) (sql.Result, error) {
+	return tx.Exec(rebind(r.dialect, query), args...)
+}
+
+// notFound converts sql.ErrNoRows into ErrNotFound for the record described by key
+func notFound(err error, key string) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%s %w", key, ErrNotFound)
+	}
+	return err
+}
+
+// alreadyExists converts a primary key or unique constraint violation into ErrAlreadyExists for
+// the record described by key. Creates check for an existing row first, but a row that does not
+// exist yet cannot be locked, so a concurrent create of the same key only fails on insert.
+func alreadyExists(err error, key string) error {
+	var pqErr *pq.Error
+	var sqliteErr *sqlite.Error
+	switch {
+	case errors.As(err, &pqErr) && pqErr.Code == "23505",
+		errors.As(err, &sqliteErr) && (sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY || sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE):
+		return fmt.Errorf("%s %w", key, ErrAlreadyExists)
+	}
+	return err
+}
+
+func (r *SQLRepository) selectProvider(q querier, providerId string, lock bool) (*models.Provider, error) {
+	var p models.Provider
+	err := q.QueryRow(r.selectQuery(`SELECT provider_id, npi, provider_type, status, county FROM providers WHERE provider_id = ?`, lock), providerId).
+		Scan(&p.ProviderID, &p.NPI, &p.ProviderType, &p.Status, &p.County)
+	if err != nil {
+		return nil, notFound(err, "provider "+providerId)
+	}
+	return &p, nil
+}
+
+func (r *SQLRepository) selectProviderNetwork(q querier, providerId, networkId string, effectiveDate time.Time, lock bool) (*models.ProviderNetwork, error) {
+	var n models.ProviderNetwork
+	err := q.QueryRow(r.selectQuery(`SELECT provider_id, network_id, effective_date, termination_date, termination_reason
+		FROM provider_networks WHERE provider_id = ? AND network_id = ? AND effective_date = ?`, lock), providerId, networkId, effectiveDate.UTC()).
+		Scan(&n.ProviderID, &n.NetworkID, &n.EffectiveDate, &n.TerminationDate, &n.TerminationReason)
+	if err != nil {
+		return nil, notFound(err, affiliationKey(providerId, networkId, effectiveDate))
+	}
+	return &n, nil
+}
+
+func (r *SQLRepository) selectServiceLocation(q querier, locationId string, lock bool) (*models.ProviderServiceLocation, error) {
+	location, err := scanServiceLocation(q.QueryRow(r.selectQuery(`SELECT `+serviceLocationColumns+`
+		FROM provider_service_locations WHERE location_id = ?`, lock), locationId))
+	if err != nil {
+		return nil, notFound(err, "service location "+locationId)
+	}
+	return location, nil
+}
+
+func (r *SQLRepository) GetProvider(providerId string) (*models.Provider, error) {
+	return r.selectProvider(r.db, providerId, false)
+}
+
+func (r *SQLRepository) CreateProvider(provider models.Provider) error {
+	return alreadyExists(r.inTx(func(tx *sql.Tx) error {
+		if _, err := r.selectProvider(tx, provider.ProviderID, true); err == nil {
+			return fmt.Errorf("provider %s %w", provider.ProviderID, ErrAlreadyExists)
+		} else if !errors.Is(err, ErrNotFound) {
+			return err
+		}
+		_, err := r.exec(tx, `INSERT INTO providers (provider_id, npi, provider_type, status, county) VALUES (?, ?, ?, ?, ?)`,
+			provider.ProviderID, provider.NPI, provider.ProviderType, provider.Status, provider.County)
+		return err
+	}), "provider "+provider.ProviderID)
+}
+
+func (r *SQLRepository) UpdateProvider(provider models.Provider, version string) error {
+	return r.inTx(func(tx *sql.Tx) error {
+		current, err := r.selectProvider(tx, provider.ProviderID, true)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion("provider "+provider.ProviderID, current, version); err != nil {
+			return err
+		}
+		_, err = r.exec(tx, `UPDATE providers SET npi = ?, provider_type = ?, status = ?, county = ? WHERE provider_id = ?`,
+			provider.NPI, provider.ProviderType, provider.Status, provider.County, provider.ProviderID)
+		return err
+	})
+}
+
+// DeleteProvider removes a provider that no longer has network affiliations or service locations
+func (r *SQLRepository) DeleteProvider(providerId, version string) error {
+	return r.inTx(func(tx *sql.Tx) error {
+		current, err := r.selectProvider(tx, providerId, true)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion("provider "+providerId, current, version); err != nil {
+			return err
+		}
+		var references int
+		if err := tx.QueryRow(rebind(r.dialect, `SELECT
+			(SELECT COUNT(*) FROM provider_networks WHERE provider_id = ?) +
+			(SELECT COUNT(*) FROM provider_service_locations WHERE provider_id = ?)`), providerId, providerId).Scan(&references); err != nil {
+			return err
+		}
+		if references > 0 {
+			return fmt.Errorf("provider %s %w by network affiliations or service locations", providerId, ErrInUse)
+		}
+		_, err = r.exec(tx, `DELETE FROM providers WHERE provider_id = ?`, providerId)
+		return err
+	})
+}
+
+func (r *SQLRepository) GetProviderNetwork(providerId, networkId string, effectiveDate time.Time) (*models.ProviderNetwork, error) {
+	return r.selectProviderNetwork(r.db, providerId, networkId, effectiveDate, false)
+}
+
+func (r *SQLRepository) CreateProviderNetwork(network models.ProviderNetwork) error {
+	return alreadyExists(r.inTx(func(tx *sql.Tx) error {
+		if _, err := r.selectProvider(tx, network.ProviderID, true); err != nil {
+			return err
+		}
+		if _, err := r.selectProviderNetwork(tx, network.ProviderID, network.NetworkID, network.EffectiveDate, true); err == nil {
+			return fmt.Errorf("%s %w", affiliationKey(network.ProviderID, network.NetworkID, network.EffectiveDate), ErrAlreadyExists)
+		} else if !errors.Is(err, ErrNotFound) {
+			return err
+		}
+		_, err := r.exec(tx, `INSERT INTO provider_networks (provider_id, network_id, effective_date, termination_date, termination_reason) VALUES (?, ?, ?, ?, ?)`,
+			network.ProviderID, network.NetworkID, network.EffectiveDate.UTC(), network.TerminationDate.UTC(), network.TerminationReason)
+		return err
+	}), affiliationKey(network.ProviderID, network.NetworkID, network.EffectiveDate))
+}
+
+func (r *SQLRepository) UpdateProviderNetwork(effectiveDate time.Time, network models.ProviderNetwork, version string) error {
+	return alreadyExists(r.inTx(func(tx *sql.Tx) error {
+		current, err := r.selectProviderNetwork(tx, network.ProviderID, network.NetworkID, effectiveDate, true)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion(affiliationKey(network.ProviderID, network.NetworkID, effectiveDate), current, version); err != nil {
+			return err
+		}
+		if !network.EffectiveDate.Equal(effectiveDate) {
+			if _, err := r.selectProviderNetwork(tx, network.ProviderID, network.NetworkID, network.EffectiveDate, true); err == nil {
+				return fmt.Errorf("%s %w", affiliationKey(network.ProviderID, network.NetworkID, network.EffectiveDate), ErrAlreadyExists)
+			} else if !errors.Is(err, ErrNotFound) {
+				return err
+			}
+		}
+		_, err = r.exec(tx, `UPDATE provider_networks SET effective_date = ?, termination_date = ?, termination_reason = ?
+			WHERE provider_id = ? AND network_id = ? AND effective_date = ?`,
+			network.EffectiveDate.UTC(), network.TerminationDate.UTC(), network.TerminationReason, network.ProviderID, network.NetworkID, effectiveDate.UTC())
+		return err
+	}), affiliationKey(network.ProviderID, network.NetworkID, network.EffectiveDate))
+}
+
+func (r *SQLRepository) DeleteProviderNetwork(providerId, networkId string, effectiveDate time.Time, version string) error {
+	return r.inTx(func(tx *sql.Tx) error {
+		current, err := r.selectProviderNetwork(tx, providerId, networkId, effectiveDate, true)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion(affiliationKey(providerId, networkId, effectiveDate), current, version); err != nil {
+			return err
+		}
+		_, err = r.exec(tx, `DELETE FROM provider_networks WHERE provider_id = ? AND network_id = ? AND effective_date = ?`,
+			providerId, networkId, effectiveDate.UTC())
+		return err
+	})
+}
+
+func (r *SQLRepository) GetServiceLocation(locationId string) (*models.ProviderServiceLocation, error) {
+	return r.selectServiceLocation(r.db, locationId, false)
+}
+
+// CreateServiceLocation adds a service location, assigning it the next free location ID of its provider
+func (r *SQLRepository) CreateServiceLocation(location models.ProviderServiceLocation) (*models.ProviderServiceLocation, error) {
+	err := r.inTx(func(tx *sql.Tx) error {
+		if _, err := r.selectProvider(tx, location.ProviderID, true); err != nil {
+			return err
+		}
+
+		rows, err := tx.Query(rebind(r.dialect, `SELECT location_id FROM provider_service_locations WHERE provider_id = ?`), location.ProviderID)
+		if err != nil {
+			return err
+		}
+		taken := make(map[string]bool)
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return err
+			}
+			taken[id] = true
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		location.LocationID = nextLocationID(location.ProviderID, func(id string) bool { return taken[id] })
+		_, err = r.exec(tx, `INSERT INTO provider_service_locations (`+serviceLocationColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			location.LocationID, location.ProviderID, location.EffectiveDate.UTC(), location.TerminationDate.UTC(), location.Address1,
+			location.Address2, location.City, location.ZipCode, location.County, location.Latitude, location.Longitude, location.CoordinateSource)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &location, nil
+}
+
+func (r *SQLRepository) UpdateServiceLocation(location models.ProviderServiceLocation, version string) error {
+	return r.inTx(func(tx *sql.Tx) error {
+		current, err := r.selectServiceLocation(tx, location.LocationID, true)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion("service location "+location.LocationID, current, version); err != nil {
+			return err
+		}
+		if _, err := r.selectProvider(tx, location.ProviderID, true); err != nil {
+			return err
+		}
+		_, err = r.exec(tx, `UPDATE provider_service_locations SET provider_id = ?, effective_date = ?, termination_date = ?,
+			address1 = ?, address2 = ?, city = ?, zip_code = ?, county = ?, latitude = ?, longitude = ?, coordinate_source = ? WHERE location_id = ?`,
+			location.ProviderID, location.EffectiveDate.UTC(), location.TerminationDate.UTC(), location.Address1, location.Address2,
+			location.City, location.ZipCode, location.County, location.Latitude, location.Longitude, location.CoordinateSource, location.LocationID)
+		return err
+	})
+}
+
+func (r *SQLRepository) DeleteServiceLocation(locationId, version string) error {
+	return r.inTx(func(tx *sql.Tx) error {
+		current, err := r.selectServiceLocation(tx, locationId, true)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion("service location "+locationId, current, version); err != nil {
+			return err
+		}
+		_, err = r.exec(tx, `DELETE FROM provider_service_locations WHERE location_id = ?`, locationId)
+		return err
+	})
+}
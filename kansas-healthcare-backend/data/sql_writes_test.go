@@ -0,0 +1,101 @@
+package data
+
+import (
+	"errors"
+	"kansas-healthcare-api/models"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLRosterWrites(t *testing.T) {
+	repo := newSQLiteTestRepository(t, newFixtureJSONRepository())
+
+	provider := models.Provider{ProviderID: "P9", NPI: "1000000009", ProviderType: "Cardiology", Status: "Active", County: "Butler"}
+	require.NoError(t, repo.CreateProvider(provider))
+	assert.ErrorIs(t, repo.CreateProvider(provider), ErrAlreadyExists)
+
+	stored, err := repo.GetProvider("P9")
+	require.NoError(t, err)
+	assert.Equal(t, provider, *stored)
+
+	updated := provider
+	updated.Status = "Terminated"
+	require.NoError(t, repo.UpdateProvider(updated, RecordVersion(provider)))
+	assert.ErrorIs(t, repo.UpdateProvider(updated, RecordVersion(provider)), ErrVersionMismatch)
+
+	network := models.ProviderNetwork{ProviderID: "P9", NetworkID: "Medicare",
+		EffectiveDate: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), TerminationDate: time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)}
+	require.NoError(t, repo.CreateProviderNetwork(network))
+	storedNetwork, err := repo.GetProviderNetwork("P9", "Medicare", network.EffectiveDate)
+	require.NoError(t, err)
+	assert.Equal(t, RecordVersion(network), RecordVersion(storedNetwork), "versions match across repositories")
+
+	location, err := repo.CreateServiceLocation(models.ProviderServiceLocation{ProviderID: "P9", County: "Butler",
+		EffectiveDate: network.EffectiveDate, TerminationDate: network.TerminationDate, Latitude: 37.8, Longitude: -96.9})
+	require.NoError(t, err)
+	assert.Equal(t, "P9-1", location.LocationID)
+
+	assert.ErrorIs(t, repo.DeleteProvider("P9", RecordVersion(updated)), ErrInUse)
+	require.NoError(t, repo.DeleteServiceLocation(location.LocationID, RecordVersion(location)))
+	require.NoError(t, repo.DeleteProviderNetwork("P9", "Medicare", network.EffectiveDate, RecordVersion(network)))
+	require.NoError(t, repo.DeleteProvider("P9", RecordVersion(updated)))
+
+	_, err = repo.GetProvider("P9")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = repo.GetServiceLocation(location.LocationID)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestSQLConstraintViolationsAreAlreadyExists(t *testing.T) {
+	repo := newSQLiteTestRepository(t, newFixtureJSONRepository())
+
+	// The row a concurrent create inserted after this one checked for it
+	_, err := repo.db.Exec(`INSERT INTO providers (provider_id, npi, provider_type, status, county) VALUES ('P1', '1', 'Cardiology', 'Active', 'Butler')`)
+	require.Error(t, err)
+	assert.ErrorIs(t, alreadyExists(err, "provider P1"), ErrAlreadyExists)
+	assert.ErrorIs(t, alreadyExists(&pq.Error{Code: "23505"}, "provider P1"), ErrAlreadyExists)
+
+	other := errors.New("connection reset")
+	assert.Equal(t, other, alreadyExists(other, "provider P1"))
+	assert.Nil(t, alreadyExists(nil, "provider P1"))
+}
+
+func TestSQLProviderNetworkSpells(t *testing.T) {
+	assertProviderNetworkSpells(t, newSQLiteTestRepository(t, newFixtureJSONRepository()))
+}
+
+func TestServiceLocationIDMigrationBackfillsExistingRows(t *testing.T) {
+	db, err := OpenDatabase(DialectSQLite, ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	// Start from a database at schema version 1 that already holds locations
+	require.NoError(t, applyMigrations(db, DialectSQLite, migrations[:1]))
+	insert := rebind(DialectSQLite, `INSERT INTO provider_service_locations (provider_id, effective_date, termination_date, county) VALUES (?, ?, ?, ?)`)
+	open := time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+	for _, row := range []struct {
+		provider  string
+		effective time.Time
+	}{
+		{"P1", time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"P1", time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"P2", time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+	} {
+		_, err := db.Exec(insert, row.provider, row.effective, open, "Sedgwick")
+		require.NoError(t, err)
+	}
+
+	repo, err := NewSQLRepository(db, DialectSQLite)
+	require.NoError(t, err)
+	locations, err := repo.GetProviderServiceLocations()
+	require.NoError(t, err)
+	require.Len(t, locations, 3)
+	assert.Equal(t, "P1-1", locations[0].LocationID)
+	assert.Equal(t, 2020, locations[0].EffectiveDate.Year())
+	assert.Equal(t, "P1-2", locations[1].LocationID)
+	assert.Equal(t, "P2-1", locations[2].LocationID)
+}
@@ -0,0 +1,91 @@
+package data
+
+import (
+	"kansas-healthcare-api/config"
+	"kansas-healthcare-api/models"
+	"strings"
+	"time"
+)
+
+// matchesTermination reports whether an affiliation ended inside the criteria's window with one of
+// its reasons
+func matchesTermination(network models.ProviderNetwork, criteria models.TerminationCriteria) bool {
+	if !network.TerminationDate.After(criteria.From) || !network.TerminationDate.Before(criteria.To) {
+		return false
+	}
+	if len(criteria.Reasons) == 0 {
+		return true
+	}
+	for _, reason := range criteria.Reasons {
+		if strings.EqualFold(reason, network.TerminationReason) {
+			return true
+		}
+	}
+	return false
+}
+
+// inDenominator reports whether an affiliation places its provider among those a termination
+// percentage is taken over
+func inDenominator(network models.ProviderNetwork, criteria models.TerminationCriteria) bool {
+	if criteria.Denominator == config.DenominatorAtWindowStart {
+		return activeAt(network.EffectiveDate, network.TerminationDate, criteria.From)
+	}
+	return network.EffectiveDate.Before(criteria.To) && network.TerminationDate.After(criteria.From)
+}
+
+// terminationRate counts distinct providers in the denominator and, among them, those whose
+// affiliation matches the criteria
+func terminationRate(networks []models.ProviderNetwork, criteria models.TerminationCriteria) (int, int) {
+	denominator := make(map[string]bool)
+	numerator := make(map[string]bool)
+	for _, network := range networks {
+		if !inDenominator(network, criteria) {
+			continue
+		}
+		denominator[network.ProviderID] = true
+		if matchesTermination(network, criteria) {
+			numerator[network.ProviderID] = true
+		}
+	}
+	return len(numerator), len(denominator)
+}
+
+// terminationBreakdown breaks down the providers terminationRate counts in its numerator, each
+// under the first of its affiliations that places it in the denominator and matches the criteria
+func terminationBreakdown(networks []models.ProviderNetwork, specialty func(providerId string) string, criteria models.TerminationCriteria) *models.TerminationBreakdown {
+	breakdown := newTerminationBreakdown()
+	counted := make(map[string]bool)
+	for _, network := range networks {
+		if counted[network.ProviderID] || !inDenominator(network, criteria) || !matchesTermination(network, criteria) {
+			continue
+		}
+		counted[network.ProviderID] = true
+		addTermination(breakdown, network.TerminationReason, specialty(network.ProviderID), network.TerminationDate)
+	}
+	return breakdown
+}
+
+func newTerminationBreakdown() *models.TerminationBreakdown {
+	return &models.TerminationBreakdown{
+		ByReason:    make(map[string]int),
+		BySpecialty: make(map[string]int),
+		ByYear:      make(map[int]int),
+	}
+}
+
+// addTermination counts one terminated provider. A zero date, as for providers with no affiliation
+// at all, is left out of the per-year counts.
+func addTermination(b *models.TerminationBreakdown, reason, specialty string, date time.Time) {
+	if reason == "" {
+		reason = config.UnspecifiedReason
+	}
+	if specialty == "" {
+		specialty = "Unknown"
+	}
+	b.Total++
+	b.ByReason[reason]++
+	b.BySpecialty[specialty]++
+	if !date.IsZero() {
+		b.ByYear[date.Year()]++
+	}
+}
@@ -0,0 +1,97 @@
+package data
+
+import (
+	"kansas-healthcare-api/config"
+	"kansas-healthcare-api/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTerminationBreakdown(t *testing.T) {
+	joined := time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+	open := time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+	repo := newJSONRepositoryFrom(&jsonSnapshot{
+		providers: []models.Provider{
+			{ProviderID: "P1", ProviderType: "Primary Care", Status: "Active", County: "Sedgwick"},
+			{ProviderID: "P2", ProviderType: "Cardiology", Status: "Active", County: "Sedgwick"},
+			{ProviderID: "P3", ProviderType: "Primary Care", Status: "Active", County: "Sedgwick"},
+			{ProviderID: "P4", ProviderType: "Pediatrics", Status: "Active", County: "Sedgwick"},
+		},
+		providerNetwork: []models.ProviderNetwork{
+			{ProviderID: "P1", NetworkID: "Commercial", EffectiveDate: joined, TerminationDate: time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC), TerminationReason: "Left Network"},
+			{ProviderID: "P2", NetworkID: "Commercial", EffectiveDate: joined, TerminationDate: time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC), TerminationReason: "Retired"},
+			{ProviderID: "P3", NetworkID: "Commercial", EffectiveDate: joined, TerminationDate: time.Date(2021, 8, 1, 0, 0, 0, 0, time.UTC)},
+		},
+		providerServiceLocations: []models.ProviderServiceLocation{
+			{ProviderID: "P1", EffectiveDate: joined, TerminationDate: open, County: "Sedgwick"},
+			{ProviderID: "P4", EffectiveDate: joined, TerminationDate: open, County: "Sedgwick"},
+		},
+	})
+	asOf := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+
+	// Any reason, over the default window
+	criteria := leftNetworkCriteria(asOf)
+	criteria.Reasons = nil
+	breakdown, err := repo.GetTerminatedNetworkBreakdown("Commercial", criteria)
+	require.NoError(t, err)
+	assert.Equal(t, 3, breakdown.Total)
+	assert.Equal(t, map[string]int{"Left Network": 1, "Retired": 1, "Unspecified": 1}, breakdown.ByReason)
+	assert.Equal(t, map[string]int{"Primary Care": 2, "Cardiology": 1}, breakdown.BySpecialty)
+	assert.Equal(t, map[int]int{2020: 1, 2021: 2}, breakdown.ByYear)
+
+	// Reasons match case-insensitively and the window narrows by date
+	criteria = models.TerminationCriteria{AsOf: asOf, From: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), To: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), Reasons: []string{"retired", "left network"}}
+	breakdown, err = repo.GetTerminatedNetworkBreakdown("Commercial", criteria)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"Retired": 1}, breakdown.ByReason)
+
+	// The county analysis counts the providers in the percentage's numerator
+	criteria = leftNetworkCriteria(asOf)
+	total, county, err := repo.GetCountyTerminatedNetworkBreakdown("Sedgwick", "Commercial", criteria)
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	assert.Equal(t, map[string]int{"Left Network": 1}, county.ByReason)
+	numerator, _, err := repo.GetTerminationRate("Sedgwick", "Commercial", criteria)
+	require.NoError(t, err)
+	assert.Equal(t, numerator, county.Total)
+
+	// Under the legacy percentage a provider with no affiliation at all counts as terminated
+	criteria.LegacyPercentage = true
+	_, county, err = repo.GetCountyTerminatedNetworkBreakdown("Sedgwick", "Commercial", criteria)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"Left Network": 1, "No Affiliation": 1}, county.ByReason)
+	assert.Equal(t, map[int]int{2020: 1}, county.ByYear)
+}
+
+func TestTerminationRateDenominators(t *testing.T) {
+	open := time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+	criteria := models.TerminationCriteria{
+		From:    time.Date(2019, 6, 30, 0, 0, 0, 0, time.UTC),
+		To:      time.Date(2022, 6, 30, 0, 0, 0, 0, time.UTC),
+		Reasons: []string{"Left Network"},
+	}
+	networks := []models.ProviderNetwork{
+		// In the network throughout the window
+		{ProviderID: "P1", EffectiveDate: time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC), TerminationDate: open},
+		// A member at the start who left during the window
+		{ProviderID: "P2", EffectiveDate: time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC), TerminationDate: time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC), TerminationReason: "Left Network"},
+		// Joined and left inside the window
+		{ProviderID: "P3", EffectiveDate: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), TerminationDate: time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC), TerminationReason: "Left Network"},
+		// Joined after the window closed
+		{ProviderID: "P4", EffectiveDate: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), TerminationDate: open},
+		// Left before the window opened
+		{ProviderID: "P5", EffectiveDate: time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC), TerminationDate: time.Date(2018, 3, 1, 0, 0, 0, 0, time.UTC), TerminationReason: "Left Network"},
+	}
+
+	numerator, denominator := terminationRate(networks, criteria)
+	assert.Equal(t, 2, numerator)
+	assert.Equal(t, 3, denominator, "providers in the network at any time during the window")
+
+	criteria.Denominator = config.DenominatorAtWindowStart
+	numerator, denominator = terminationRate(networks, criteria)
+	assert.Equal(t, 1, numerator, "only members at the start count as having left")
+	assert.Equal(t, 2, denominator)
+}
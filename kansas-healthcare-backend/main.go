@@ -69,11 +69,58 @@ func main() {
 
 	// Initialize data repository
 	var repo data.Repository
+	var jsonRepo *data.JSONRepository
+	dataOpts := data.LoadOptions{DataDir: cfg.DataDir, Files: cfg.DataFiles}
 	if cfg.DataSource == "json" {
-		repo = data.NewJSONRepository()
+		var err error
+		jsonOpts := dataOpts
+		jsonOpts.AllowDegraded = cfg.AllowDegradedData
+		jsonRepo, err = data.NewJSONRepository(jsonOpts)
+		if err != nil {
+			log.Fatalf("Failed to load data files: %v", err)
+		}
+		if status := jsonRepo.ReloadStatus(); len(status.Degraded) > 0 {
+			log.Printf("[WARN] Starting in degraded mode without optional datasets: %v", status.Degraded)
+		}
+		repo = jsonRepo
 	} else {
-		// Future: repo = data.NewDBRepository()
-		log.Fatal("Database repository not implemented yet")
+		db, err := data.OpenDatabase(cfg.DBDriver, cfg.DatabaseDSN())
+		if err != nil {
+			log.Fatalf("Failed to connect to %s database: %v", cfg.DBDriver, err)
+		}
+		sqlRepo, err := data.NewSQLRepository(db, cfg.DBDriver)
+		if err != nil {
+			log.Fatalf("Failed to migrate %s database: %v", cfg.DBDriver, err)
+		}
+		defer sqlRepo.Close()
+
+		// Seed an empty database from the bundled JSON dataset for local demos
+		if cfg.DBSeed {
+			empty, err := sqlRepo.IsEmpty()
+			if err != nil {
+				log.Fatalf("Failed to inspect database: %v", err)
+			}
+			if empty {
+				log.Printf("Seeding %s database from JSON dataset", cfg.DBDriver)
+				seed, err := data.NewJSONRepository(dataOpts)
+				if err != nil {
+					log.Fatalf("Failed to load seed data files: %v", err)
+				}
+				if err := sqlRepo.SeedFromJSON(seed); err != nil {
+					log.Fatalf("Failed to seed database: %v", err)
+				}
+			}
+		}
+		repo = sqlRepo
+	}
+
+	// Poll the data files so roster refreshes are served without a restart
+	watchCtx, stopWatching := context.WithCancel(context.Background())
+	defer stopWatching()
+	if jsonRepo != nil && cfg.DataReloadInterval > 0 {
+		go jsonRepo.Watch(watchCtx, cfg.DataReloadInterval, func(err error) {
+			log.Printf("[ERROR] Data reload failed, still serving previous snapshot: %v", err)
+		})
 	}
 
 	// Initialize services
@@ -93,18 +140,23 @@ func main() {
 	corsConfig := cors.DefaultConfig()
 	corsConfig.AllowOrigins = []string{"http://localhost:5173", "http://localhost:4192"}
 	corsConfig.AllowMethods = []string{"GET", "POST", "PUT", "DELETE"}
-	corsConfig.AllowHeaders = []string{"Origin", "Content-Type", "Authorization"}
+	corsConfig.AllowHeaders = []string{"Origin", "Content-Type", "Authorization", "If-Match"}
+	corsConfig.ExposeHeaders = []string{"ETag", "Location"}
 	r.Use(cors.New(corsConfig))
 
 	// Health check endpoint for Kubernetes liveness/readiness probes
 	// Critical for zero-downtime healthcare service deployments
 	healthHandler := func(c *gin.Context) {
 		log.Printf("Health check accessed via %s", c.Request.Method)
-		c.JSON(http.StatusOK, gin.H{
+		response := gin.H{
 			"status": "healthy",
 			"timestamp": time.Now().UTC(),
 			"service": "kansas-healthcare-api",
-		})
+		}
+		if jsonRepo != nil {
+			response["data"] = jsonRepo.ReloadStatus()
+		}
+		c.JSON(http.StatusOK, response)
 	}
 	r.GET("/health", healthHandler)
 	r.HEAD("/health", healthHandler)
@@ -125,6 +177,33 @@ func main() {
 		api.GET("/terminated-analysis/:county", analyticsController.GetCountyTerminatedNetworkAnalysis)
 		api.GET("/specialty-density/:county", analyticsController.GetSpecialtyDensityAnalysis)
 		api.GET("/radius-analysis/:county", analyticsController.GetRadiusAnalysis)
+		api.GET("/nearest-providers", providerController.GetNearestProviders)
+		api.GET("/network-adequacy", analyticsController.GetNetworkAdequacy)
+		api.GET("/county-boundaries/:county", analyticsController.GetCountyBoundary)
+		api.GET("/geojson/service-locations", providerController.GetServiceLocationGeoJSON)
+		api.GET("/geojson/county-data", analyticsController.GetCountyDataGeoJSON)
+		api.GET("/coverage-gaps", analyticsController.GetCoverageGaps)
+		api.GET("/accessibility", analyticsController.GetAccessibility)
+		api.GET("/recruitment-sites", analyticsController.GetRecruitmentSites)
+		api.GET("/catchments", analyticsController.GetCatchments)
+		api.GET("/networks/:network/timeseries", analyticsController.GetNetworkTimeSeries)
+		api.GET("/networks/:network/cohorts", analyticsController.GetNetworkCohorts)
+		api.GET("/retention", analyticsController.GetRetention)
+		api.GET("/data-quality", providerController.GetDataQuality)
+
+		// Roster maintenance; updates and deletes require If-Match with the record's ETag
+		api.POST("/providers", providerController.CreateProvider)
+		api.GET("/providers/:id", providerController.GetProvider)
+		api.PUT("/providers/:id", providerController.UpdateProvider)
+		api.DELETE("/providers/:id", providerController.DeleteProvider)
+		api.POST("/providers/:id/networks", providerController.CreateProviderNetworkAffiliation)
+		api.GET("/providers/:id/networks/:networkId/:effectiveDate", providerController.GetProviderNetworkAffiliation)
+		api.PUT("/providers/:id/networks/:networkId/:effectiveDate", providerController.UpdateProviderNetworkAffiliation)
+		api.DELETE("/providers/:id/networks/:networkId/:effectiveDate", providerController.DeleteProviderNetworkAffiliation)
+		api.POST("/providers/:id/service-locations", providerController.CreateServiceLocation)
+		api.GET("/service-locations/:locationId", providerController.GetServiceLocation)
+		api.PUT("/service-locations/:locationId", providerController.UpdateServiceLocation)
+		api.DELETE("/service-locations/:locationId", providerController.DeleteServiceLocation)
 	}
 
 	port := cfg.Port
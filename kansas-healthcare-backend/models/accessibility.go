@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// CountyPopulation is a county's resident population, the demand accessibility is measured against
+type CountyPopulation struct {
+	County     string `json:"county"`
+	Population int    `json:"population"`
+}
+
+// LocationDistance is a service location within reach of a point and its distance in miles
+type LocationDistance struct {
+	LocationID    string
+	DistanceMiles float64
+}
+
+// AccessibilityQuery asks for the two-step floating catchment area score of each county for a
+// specialty ("All" for any). Empty County and Network cover every county and network.
+type AccessibilityQuery struct {
+	County         string
+	Specialty      string
+	Network        string
+	CatchmentMiles float64
+	Decay          string
+	AsOf           time.Time
+}
+
+// AccessibilityResult is one county's accessibility to a network's providers: the in-reach
+// service locations per 100,000 residents after weighing each by distance and by the other
+// populations competing for it. RelativeToState compares the score with the population-weighted
+// state average, so 1 is average access.
+type AccessibilityResult struct {
+	County           string  `json:"county"`
+	Network          string  `json:"network"`
+	Population       int     `json:"population"`
+	ProvidersPer100k float64 `json:"providers_per_100k"`
+	RelativeToState  float64 `json:"relative_to_state"`
+}
+
+// AccessibilityReport holds the accessibility of every county and network evaluated. Counties
+// without a population or centroid carry no demand and are listed in UnevaluatedCounties.
+type AccessibilityReport struct {
+	AsOf                string                `json:"as_of"`
+	Specialty           string                `json:"specialty"`
+	CatchmentMiles      float64               `json:"catchment_miles"`
+	Decay               string                `json:"decay"`
+	Results             []AccessibilityResult `json:"results"`
+	UnevaluatedCounties []string              `json:"unevaluated_counties,omitempty"`
+}
@@ -0,0 +1,60 @@
+package models
+
+import "time"
+
+// AdequacyStandard is the furthest a member may have to travel to reach a provider of a specialty
+// in a county of the given designation
+type AdequacyStandard struct {
+	Specialty   string  `json:"specialty"`
+	Designation string  `json:"designation"`
+	MaxMinutes  int     `json:"max_minutes"`
+	MaxMiles    float64 `json:"max_miles"`
+}
+
+// CountyDesignation classifies a county by population and density (Large Metro, Metro, Micro,
+// Rural or CEAC, counties with extreme access considerations)
+type CountyDesignation struct {
+	County      string `json:"county"`
+	Designation string `json:"designation"`
+}
+
+// AdequacyFilter narrows a network adequacy evaluation. Empty fields cover every county,
+// specialty with a standard and network. IncludeOutOfState lets providers in neighboring states
+// meet the standard for the border counties they are close to.
+type AdequacyFilter struct {
+	County            string
+	Specialty         string
+	Network           string
+	AsOf              time.Time
+	IncludeOutOfState bool
+}
+
+// AdequacyResult is the evaluation of one county, specialty and network against its standard
+type AdequacyResult struct {
+	County                 string  `json:"county"`
+	Designation            string  `json:"designation"`
+	Specialty              string  `json:"specialty"`
+	Network                string  `json:"network"`
+	MaxMinutes             int     `json:"max_minutes"`
+	MaxMiles               float64 `json:"max_miles"`
+	SampledPoints          int     `json:"sampled_points"`
+	PointsMeetingStandard  int     `json:"points_meeting_standard"`
+	PercentMeetingStandard float64 `json:"percent_meeting_standard"`
+	// PointsMetOutOfState meet the standard only through providers in neighboring states
+	PointsMetOutOfState int  `json:"points_met_out_of_state,omitempty"`
+	Pass                bool `json:"pass"`
+}
+
+// AdequacyReport holds the results of a network adequacy evaluation. Only the distance standard
+// is checked; travel times are reported for reference.
+type AdequacyReport struct {
+	AsOf              string           `json:"as_of"`
+	MinPercentage     float64          `json:"min_percentage"`
+	IncludeOutOfState bool             `json:"include_out_of_state"`
+	Evaluated         int              `json:"evaluated"`
+	Passed            int              `json:"passed"`
+	Failed            int              `json:"failed"`
+	Results           []AdequacyResult `json:"results"`
+	// UnevaluatedCounties have a designation but no centroid to sample points around
+	UnevaluatedCounties []string `json:"unevaluated_counties,omitempty"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// CatchmentPart is the piece of a catchment that lies in one county. County is empty when no
+// county boundaries are loaded and the catchment is clipped to the state's bounding box instead.
+type CatchmentPart struct {
+	County      string
+	AreaSqMiles float64
+	Polygons    []Polygon
+}
+
+// Catchment is the area closer to one site than to any other: the Voronoi cell around the service
+// locations at one position, clipped to the county boundaries. Locations sharing a position, such
+// as several providers in one clinic, form a single site.
+type Catchment struct {
+	Site        Coordinate
+	City        string
+	County      string
+	LocationIDs []string
+	ProviderIDs []string
+	Parts       []CatchmentPart
+}
+
+// CatchmentQuery asks for the catchments of the in-force locations of active providers with the
+// specialty ("All" for any) in the network
+type CatchmentQuery struct {
+	Network   string
+	Specialty string
+	AsOf      time.Time
+}
+
+// CatchmentCounty is the share of one county a catchment covers and the residents living in it
+type CatchmentCounty struct {
+	County      string  `json:"county"`
+	AreaSqMiles float64 `json:"area_sq_miles"`
+	Population  int     `json:"population"`
+}
+
+// CatchmentReport is a GeoJSON FeatureCollection of catchments, each a MultiPolygon carrying its
+// site, area_sq_miles and population_served, with the query as foreign members GIS tools ignore
+type CatchmentReport struct {
+	FeatureCollection
+	Network             string   `json:"network"`
+	Specialty           string   `json:"specialty"`
+	AsOf                string   `json:"as_of"`
+	Sites               int      `json:"sites"`
+	UnevaluatedCounties []string `json:"unevaluated_counties,omitempty"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// CohortQuery asks for the retention of a network's providers by the year they joined, observed at
+// AsOf
+type CohortQuery struct {
+	Network string
+	AsOf    time.Time
+}
+
+// Cohort is the providers who first joined a network in JoinYear. Active[k] counts those with an
+// affiliation in force on the k+1st anniversary of their joining, and Percent[k] is that count as
+// a percentage of Providers. Anniversaries some of the cohort had not yet reached at the report's
+// as-of date are left off the end.
+type Cohort struct {
+	JoinYear  int       `json:"join_year"`
+	Providers int       `json:"providers"`
+	Active    []int     `json:"active"`
+	Percent   []float64 `json:"percent"`
+}
+
+// CohortReport is a network's cohort retention matrix, one row per join year in order. Years is
+// the length of the longest row.
+type CohortReport struct {
+	Network string   `json:"network"`
+	AsOf    string   `json:"as_of"`
+	Years   int      `json:"years"`
+	Cohorts []Cohort `json:"cohorts"`
+}
@@ -1,6 +1,16 @@
 package models
 
+// CountyArea holds a county's land area and an approximate centroid. Counties without a centroid
+// leave both coordinates zero.
 type CountyArea struct {
 	County      string  `json:"county"`
 	AreaSqMiles float64 `json:"area_sq_miles"`
-}
\ No newline at end of file
+	Latitude    float64 `json:"latitude,omitempty"`
+	Longitude   float64 `json:"longitude,omitempty"`
+}
+
+// Coordinate is a point in decimal degrees
+type Coordinate struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
@@ -0,0 +1,13 @@
+package models
+
+// Polygon is a GeoJSON polygon: linear rings of [longitude, latitude] positions, the outer
+// boundary first and any holes after it. Each ring ends where it starts.
+type Polygon [][][2]float64
+
+// CountyBoundary is a county's outline and centroid. Counties made of several separate parts,
+// such as a river island, carry one polygon per part.
+type CountyBoundary struct {
+	County   string     `json:"county"`
+	Centroid Coordinate `json:"centroid"`
+	Polygons []Polygon  `json:"polygons"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// GridCell is one cell of a grid laid over the state, with the county its centre lies in
+type GridCell struct {
+	County  string
+	Center  Coordinate
+	Polygon Polygon
+}
+
+// CoverageGapQuery asks which grid cells of CellMiles a side lie further than ThresholdMiles from
+// the nearest in-network provider of the specialty ("All" for any)
+type CoverageGapQuery struct {
+	Network        string
+	Specialty      string
+	CellMiles      float64
+	ThresholdMiles float64
+	AsOf           time.Time
+}
+
+// CountyCoverage summarizes the grid cells of one county. MaxDistanceMiles is nil when some cell
+// has no provider to reach at all.
+type CountyCoverage struct {
+	County           string   `json:"county"`
+	Cells            int      `json:"cells"`
+	GapCells         int      `json:"gap_cells"`
+	GapPercent       float64  `json:"gap_percent"`
+	MaxDistanceMiles *float64 `json:"max_distance_miles"`
+}
+
+// CoverageGapReport is a GeoJSON FeatureCollection of the gap cells, each carrying its county and
+// distance_miles, with the query and per-county summaries as foreign members GIS tools ignore
+type CoverageGapReport struct {
+	FeatureCollection
+	Network        string           `json:"network"`
+	Specialty      string           `json:"specialty"`
+	AsOf           string           `json:"as_of"`
+	CellMiles      float64          `json:"cell_miles"`
+	ThresholdMiles float64          `json:"threshold_miles"`
+	TotalCells     int              `json:"total_cells"`
+	GapCells       int              `json:"gap_cells"`
+	Counties       []CountyCoverage `json:"counties"`
+}
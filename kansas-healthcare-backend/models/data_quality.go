@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// DataQualityReport summarizes the referential integrity and plausibility checks run over the
+// loaded datasets
+type DataQualityReport struct {
+	GeneratedAt time.Time          `json:"generated_at"`
+	TotalIssues int                `json:"total_issues"`
+	Checks      []DataQualityCheck `json:"checks"`
+}
+
+// DataQualityCheck is the outcome of one check: how many records failed it and a sample of them
+type DataQualityCheck struct {
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	File        string        `json:"file"`
+	Count       int           `json:"count"`
+	Samples     []interface{} `json:"samples"`
+}
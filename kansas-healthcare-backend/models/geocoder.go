@@ -0,0 +1,19 @@
+package models
+
+// ZipCentroid is the centre of a ZIP code's area and the city and county it is addressed to
+type ZipCentroid struct {
+	ZipCode   string  `json:"zip_code"`
+	City      string  `json:"city"`
+	County    string  `json:"county"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// CityCentroid is the centre of a city, used to place service locations whose ZIP code is not
+// in the ZIP table
+type CityCentroid struct {
+	City      string  `json:"city"`
+	County    string  `json:"county"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
@@ -0,0 +1,21 @@
+package models
+
+// FeatureCollection is a GeoJSON (RFC 7946) feature collection
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+// Feature is a GeoJSON feature. Geometry is nil, encoded as null, for a feature with no known location.
+type Feature struct {
+	Type       string      `json:"type"`
+	Geometry   *Geometry   `json:"geometry"`
+	Properties interface{} `json:"properties"`
+}
+
+// Geometry is a GeoJSON geometry: a Point's coordinates are one [longitude, latitude] position,
+// a Polygon's a Polygon and a MultiPolygon's a []Polygon
+type Geometry struct {
+	Type        string      `json:"type"`
+	Coordinates interface{} `json:"coordinates"`
+}
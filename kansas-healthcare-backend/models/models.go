@@ -19,6 +19,7 @@ type ProviderNetwork struct {
 }
 
 type ProviderServiceLocation struct {
+	LocationID      string    `json:"location_id,omitempty"`
 	ProviderID      string    `json:"provider_id"`
 	EffectiveDate   time.Time `json:"effective_date"`
 	TerminationDate time.Time `json:"termination_date"`
@@ -29,6 +30,9 @@ type ProviderServiceLocation struct {
 	County          string    `json:"county"`
 	Latitude        float64   `json:"latitude"`
 	Longitude       float64   `json:"longitude"`
+	// CoordinateSource says where approximate coordinates came from: empty when they were
+	// supplied with the location, otherwise the geocoding table that filled them in
+	CoordinateSource string `json:"coordinate_source,omitempty"`
 }
 
 type CountyStats struct {
@@ -63,9 +67,26 @@ type FilterRequest struct {
 	Network   string `json:"network"`
 }
 
+// TerminatedAnalysisResult reports the providers who left a network. TermNetworkCount is the same
+// count as Numerator, except under the legacy basis, where it keeps the original count: every
+// matching affiliation statewide, and in a county also the providers with no affiliation.
 type TerminatedAnalysisResult struct {
 	TermNetworkCount     int     `json:"term_network_count"`
 	ServiceLocationCount int     `json:"service_location_count"`
 	PercentageTerminated float64 `json:"percentage_terminated"`
 	TotalActiveProviders int     `json:"total_active_providers"`
+
+	// PercentageTerminated is Numerator / Denominator * 100 over the providers DenominatorBasis
+	// names, except under the legacy basis, which reports the divisor each original formula used
+	Numerator        int    `json:"numerator"`
+	Denominator      int    `json:"denominator"`
+	DenominatorBasis string `json:"denominator_basis"`
+
+	// The window and reasons the terminations were selected by, and how TermNetworkCount splits
+	From        string         `json:"from"`
+	To          string         `json:"to"`
+	Reasons     []string       `json:"reasons,omitempty"`
+	ByReason    map[string]int `json:"by_reason"`
+	BySpecialty map[string]int `json:"by_specialty"`
+	ByYear      map[int]int    `json:"by_year"`
 }
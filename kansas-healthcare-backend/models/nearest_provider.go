@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// NearestProviderQuery selects the service locations closest to a point. Specialty "All" matches
+// every provider type. IncludeOutOfState also considers locations in neighboring states.
+type NearestProviderQuery struct {
+	Center            Coordinate
+	Specialty         string
+	Network           string
+	Limit             int
+	AsOf              time.Time
+	IncludeOutOfState bool
+}
+
+// ProviderLocation is a service location with its provider's NPI and specialty. State is set only
+// for locations outside Kansas, whose County is in that state.
+type ProviderLocation struct {
+	ProviderID string  `json:"provider_id"`
+	NPI        string  `json:"npi"`
+	Specialty  string  `json:"specialty"`
+	LocationID string  `json:"location_id"`
+	Address1   string  `json:"address1"`
+	Address2   string  `json:"address2"`
+	City       string  `json:"city"`
+	ZipCode    string  `json:"zip_code"`
+	County     string  `json:"county"`
+	State      string  `json:"state,omitempty"`
+	Latitude   float64 `json:"latitude"`
+	Longitude  float64 `json:"longitude"`
+}
+
+// NearestProvider is a service location found by a nearest-provider lookup
+type NearestProvider struct {
+	ProviderLocation
+	DistanceMiles float64 `json:"distance_miles"`
+}
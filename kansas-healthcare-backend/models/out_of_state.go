@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// OutOfStateLocation is a service location of a provider practicing in a neighboring state, such
+// as Kansas City, Missouri or Joplin. The Kansas roster is keyed by Kansas county, so these are
+// kept apart from it and only join the radius, nearest-provider and adequacy lookups that ask for
+// them. County is the location's county within State.
+type OutOfStateLocation struct {
+	LocationID      string              `json:"location_id"`
+	ProviderID      string              `json:"provider_id"`
+	NPI             string              `json:"npi"`
+	ProviderType    string              `json:"provider_type"`
+	EffectiveDate   time.Time           `json:"effective_date"`
+	TerminationDate time.Time           `json:"termination_date"`
+	Address1        string              `json:"address1"`
+	Address2        string              `json:"address2"`
+	City            string              `json:"city"`
+	State           string              `json:"state"`
+	ZipCode         string              `json:"zip_code"`
+	County          string              `json:"county"`
+	Latitude        float64             `json:"latitude"`
+	Longitude       float64             `json:"longitude"`
+	Networks        []OutOfStateNetwork `json:"networks"`
+}
+
+// OutOfStateNetwork is a network an out-of-state location takes members of, and for how long
+type OutOfStateNetwork struct {
+	NetworkID       string    `json:"network_id"`
+	EffectiveDate   time.Time `json:"effective_date"`
+	TerminationDate time.Time `json:"termination_date"`
+}
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// RecruitmentQuery asks for up to Sites candidate sites that bring the most residents within
+// MaxMiles of a network provider of the specialty ("All" for any). Candidates is "providers" to
+// recruit out-of-network providers at their service locations, or "zips" to open at ZIP centroids.
+type RecruitmentQuery struct {
+	Specialty  string
+	Network    string
+	MaxMiles   float64
+	Sites      int
+	Candidates string
+	AsOf       time.Time
+}
+
+// RecruitmentSite is a chosen site in the order it was picked. A provider site covers residents
+// near any of its LocationIDs, located at the first of them. MarginalPopulation is the number of
+// residents it brings within reach that no existing or earlier site covers, and CoveredPopulation
+// the total covered once it is added.
+type RecruitmentSite struct {
+	Rank               int      `json:"rank"`
+	Kind               string   `json:"kind"`
+	ID                 string   `json:"id"`
+	Specialty          string   `json:"specialty,omitempty"`
+	LocationIDs        []string `json:"location_ids,omitempty"`
+	City               string   `json:"city"`
+	County             string   `json:"county"`
+	Latitude           float64  `json:"latitude"`
+	Longitude          float64  `json:"longitude"`
+	MarginalPopulation int      `json:"marginal_population"`
+	CoveredPopulation  int      `json:"covered_population"`
+	CoveredPercent     float64  `json:"covered_percent"`
+}
+
+// RecruitmentPlan is the result of a maximal covering location search: the population the
+// network already covers and the sites that add the most to it. Fewer sites than asked for are
+// returned when no remaining candidate covers anyone new.
+type RecruitmentPlan struct {
+	AsOf                string            `json:"as_of"`
+	Specialty           string            `json:"specialty"`
+	Network             string            `json:"network"`
+	MaxMiles            float64           `json:"max_miles"`
+	Candidates          string            `json:"candidates"`
+	CandidatesEvaluated int               `json:"candidates_evaluated"`
+	TotalPopulation     int               `json:"total_population"`
+	CoveredPopulation   int               `json:"covered_population"`
+	CoveredPercent      float64           `json:"covered_percent"`
+	Sites               []RecruitmentSite `json:"sites"`
+	UnevaluatedCounties []string          `json:"unevaluated_counties,omitempty"`
+}
@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+// RetentionQuery asks for provider retention curves, one per network, county or specialty
+// (StratifyBy), for one network or every network when Network is empty. AsOf is the date tenures
+// are observed at.
+type RetentionQuery struct {
+	Network    string
+	StratifyBy string
+	AsOf       time.Time
+}
+
+// RetentionPoint is a step of a Kaplan-Meier retention curve: at TenureYears, AtRisk memberships
+// were still running, Terminations of them ended and Censored were still running when last
+// observed. Retention is the estimated share of memberships lasting beyond TenureYears, between
+// Lower and Upper at the report's confidence level.
+type RetentionPoint struct {
+	TenureYears  float64 `json:"tenure_years"`
+	AtRisk       int     `json:"at_risk"`
+	Terminations int     `json:"terminations"`
+	Censored     int     `json:"censored"`
+	Retention    float64 `json:"retention"`
+	Lower        float64 `json:"lower"`
+	Upper        float64 `json:"upper"`
+}
+
+// RetentionMilestone is the estimated share of memberships lasting beyond a whole number of years
+type RetentionMilestone struct {
+	Years     int     `json:"years"`
+	Retention float64 `json:"retention"`
+	Lower     float64 `json:"lower"`
+	Upper     float64 `json:"upper"`
+}
+
+// RetentionStratum is the retention curve of one network, county or specialty. Median tenure and
+// its confidence limits are absent when the curve, or the limit's curve, never falls to one half,
+// and milestones beyond the longest observed membership are left out.
+type RetentionStratum struct {
+	Group             string               `json:"group"`
+	Memberships       int                  `json:"memberships"`
+	Terminations      int                  `json:"terminations"`
+	Censored          int                  `json:"censored"`
+	MedianTenureYears *float64             `json:"median_tenure_years,omitempty"`
+	MedianLowerYears  *float64             `json:"median_lower_years,omitempty"`
+	MedianUpperYears  *float64             `json:"median_upper_years,omitempty"`
+	Milestones        []RetentionMilestone `json:"milestones"`
+	Curve             []RetentionPoint     `json:"curve"`
+}
+
+// RetentionReport holds a retention curve per stratum, in name order
+type RetentionReport struct {
+	AsOf            string             `json:"as_of"`
+	Network         string             `json:"network,omitempty"`
+	StratifyBy      string             `json:"stratify_by"`
+	ConfidenceLevel float64            `json:"confidence_level"`
+	Strata          []RetentionStratum `json:"strata"`
+}
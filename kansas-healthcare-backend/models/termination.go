@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// TerminationCriteria selects the network affiliations counted as terminations: those whose
+// termination date falls strictly between From and To with one of the listed reasons. An empty
+// Reasons list accepts any reason. AsOf is the date provider activity is judged at.
+//
+// Denominator picks the providers a termination percentage is taken over (config.DenominatorInWindow
+// or config.DenominatorAtWindowStart); LegacyPercentage restores the original formulas instead.
+type TerminationCriteria struct {
+	AsOf    time.Time
+	From    time.Time
+	To      time.Time
+	Reasons []string
+
+	Denominator      string
+	LegacyPercentage bool
+}
+
+// TerminationBreakdown counts terminations in total and by reason, provider specialty and year of
+// termination
+type TerminationBreakdown struct {
+	Total       int            `json:"total"`
+	ByReason    map[string]int `json:"by_reason"`
+	BySpecialty map[string]int `json:"by_specialty"`
+	ByYear      map[int]int    `json:"by_year"`
+}
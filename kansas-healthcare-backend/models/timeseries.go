@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// TimeSeriesQuery asks for a network's size over the periods of Interval ("month", "quarter" or
+// "year") from the one containing From to the one containing To. GroupBy ("county" or
+// "specialty") splits the series by the provider's county or specialty; empty gives one series.
+type TimeSeriesQuery struct {
+	Network  string
+	From     time.Time
+	To       time.Time
+	Interval string
+	GroupBy  string
+}
+
+// TimeSeriesPoint is a network's membership over one period. Active counts the providers with an
+// affiliation in force on the period's last day; Joins and Terminations count the times a
+// provider joined or left during the period, so Active moves by Joins less Terminations.
+type TimeSeriesPoint struct {
+	PeriodStart  string `json:"period_start"`
+	PeriodEnd    string `json:"period_end"`
+	Active       int    `json:"active"`
+	Joins        int    `json:"joins"`
+	Terminations int    `json:"terminations"`
+}
+
+// TimeSeries is the membership of one county or specialty, or of the whole network when the
+// series is not split
+type TimeSeries struct {
+	Group  string            `json:"group,omitempty"`
+	Points []TimeSeriesPoint `json:"points"`
+}
+
+// TimeSeriesReport holds a network's membership per period, one series per group
+type TimeSeriesReport struct {
+	Network  string       `json:"network"`
+	From     string       `json:"from"`
+	To       string       `json:"to"`
+	Interval string       `json:"interval"`
+	GroupBy  string       `json:"group_by,omitempty"`
+	Series   []TimeSeries `json:"series"`
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"fmt"
+	"kansas-healthcare-api/config"
+	"kansas-healthcare-api/models"
+	"math"
+	"sort"
+)
+
+// distanceDecays weigh a distance within the catchment between 1 at zero and, except for the step
+// function, 0 at the catchment's edge
+var distanceDecays = map[string]func(distance, catchment float64) float64{
+	config.DecayStep: func(distance, catchment float64) float64 { return 1 },
+	config.DecayLinear: func(distance, catchment float64) float64 {
+		return 1 - distance/catchment
+	},
+	config.DecayGaussian: func(distance, catchment float64) float64 {
+		edge := math.Exp(-0.5)
+		return (math.Exp(-0.5*(distance/catchment)*(distance/catchment)) - edge) / (1 - edge)
+	},
+}
+
+// GetAccessibility scores each county's access to each network's providers of a specialty with
+// the enhanced two-step floating catchment area (E2SFCA) method. Every service location is one
+// unit of supply, and each county's population is spread evenly over a grid of points across it.
+//
+// Step one gives each location a supply-to-demand ratio: one over the decay-weighted population
+// of every point within the catchment. Step two sums, for each point, the decay-weighted ratios
+// of the locations within its catchment. A county scores the population-weighted mean of its
+// points, so residents near a border are credited with the providers across it.
+func (s *AnalyticsService) GetAccessibility(query models.AccessibilityQuery) (*models.AccessibilityReport, error) {
+	decay, ok := distanceDecays[query.Decay]
+	if !ok {
+		return nil, &ValidationError{Field: "decay", Message: fmt.Sprintf("must be %s, %s or %s", config.DecayStep, config.DecayLinear, config.DecayGaussian)}
+	}
+	// Demand comes from every county, including those outside a county filter, since they compete
+	// for the same providers
+	demand, err := s.populationDemand()
+	if err != nil {
+		return nil, err
+	}
+	if query.County != "" && !demand.known[query.County] {
+		return nil, fmt.Errorf("population for county %s %w", query.County, ErrNotFound)
+	}
+
+	report := &models.AccessibilityReport{
+		AsOf:                query.AsOf.Format(config.AsOfDateFormat),
+		Specialty:           query.Specialty,
+		CatchmentMiles:      query.CatchmentMiles,
+		Decay:               query.Decay,
+		Results:             []models.AccessibilityResult{},
+		UnevaluatedCounties: demand.unevaluated,
+	}
+
+	networks, err := s.evaluatedNetworks(query.Network)
+	if err != nil {
+		return nil, err
+	}
+	for _, network := range networks {
+		reachable, err := s.repo.GetProviderLocationsWithin(demand.points, query.CatchmentMiles, query.Specialty, network, query.AsOf)
+		if err != nil {
+			return nil, err
+		}
+
+		// Step one: each location's ratio of supply to the weighted demand within its catchment
+		weightedDemand := make(map[string]float64)
+		for i, locations := range reachable {
+			for _, location := range locations {
+				weightedDemand[location.LocationID] += demand.shares[i].population * decay(location.DistanceMiles, query.CatchmentMiles)
+			}
+		}
+
+		// Step two: each point's weighted sum of the ratios of the locations it reaches
+		access := make(map[string]float64)
+		var total float64
+		for i, locations := range reachable {
+			var score float64
+			for _, location := range locations {
+				if weightedDemand[location.LocationID] > 0 {
+					score += decay(location.DistanceMiles, query.CatchmentMiles) / weightedDemand[location.LocationID]
+				}
+			}
+			access[demand.shares[i].county] += demand.shares[i].population * score
+			total += demand.shares[i].population * score
+		}
+
+		stateAverage := total / float64(demand.total)
+		for county, n := range demand.residents {
+			if query.County != "" && county != query.County {
+				continue
+			}
+			result := models.AccessibilityResult{
+				County:           county,
+				Network:          network,
+				Population:       n,
+				ProvidersPer100k: math.Round(access[county]/float64(n)*100000*1000) / 1000,
+			}
+			if stateAverage > 0 {
+				result.RelativeToState = math.Round(access[county]/float64(n)/stateAverage*1000) / 1000
+			}
+			report.Results = append(report.Results, result)
+		}
+	}
+
+	sort.Slice(report.Results, func(i, j int) bool {
+		a, b := report.Results[i], report.Results[j]
+		if a.County != b.County {
+			return a.County < b.County
+		}
+		return a.Network < b.Network
+	})
+	return report, nil
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"kansas-healthcare-api/config"
+	"kansas-healthcare-api/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAccessibility(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewAnalyticsService(mockRepo)
+
+	butler := []models.Coordinate{{Latitude: 37.78, Longitude: -96.84}}
+	sedgwick := []models.Coordinate{{Latitude: 37.68, Longitude: -97.46}, {Latitude: 37.7, Longitude: -97.3}}
+	mockRepo.On("GetCountyPopulation").Return([]models.CountyPopulation{
+		{County: "Butler", Population: 1000},
+		{County: "Sedgwick", Population: 3000},
+		{County: "Wyandotte", Population: 169245},
+		{County: "Nowhere", Population: 0},
+	}, nil)
+	mockRepo.On("GetCountySamplePoints", "Butler", config.PopulationDemandGridSize).Return(butler, nil)
+	mockRepo.On("GetCountySamplePoints", "Sedgwick", config.PopulationDemandGridSize).Return(sedgwick, nil)
+	mockRepo.On("GetCountySamplePoints", "Wyandotte", config.PopulationDemandGridSize).Return([]models.Coordinate(nil), fmt.Errorf("centroid for county Wyandotte %w", ErrNotFound))
+	mockRepo.On("GetProviderNetworks").Return([]models.ProviderNetwork{{ProviderID: "P1", NetworkID: "Commercial"}}, nil)
+
+	// L1 in El Dorado serves Butler and the eastern Sedgwick point; L2 only Sedgwick
+	points := append(append([]models.Coordinate{}, butler...), sedgwick...)
+	mockRepo.On("GetProviderLocationsWithin", points, 30.0, "Cardiology", "Commercial", testAsOf).Return([][]models.LocationDistance{
+		{{LocationID: "L1", DistanceMiles: 0}},
+		{{LocationID: "L2", DistanceMiles: 5}},
+		{{LocationID: "L1", DistanceMiles: 25}, {LocationID: "L2", DistanceMiles: 10}},
+	}, nil)
+
+	query := models.AccessibilityQuery{Specialty: "Cardiology", CatchmentMiles: 30, Decay: config.DecayStep, AsOf: testAsOf}
+	report, err := service.GetAccessibility(query)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Wyandotte"}, report.UnevaluatedCounties)
+
+	// L1 is shared by 1000 Butler and 1500 Sedgwick residents, L2 by all 3000 in Sedgwick. Butler
+	// reaches 1/2500 of a location per resident; Sedgwick's points reach 1/3000 and 1/2500 + 1/3000.
+	require.Len(t, report.Results, 2)
+	assert.Equal(t, models.AccessibilityResult{County: "Butler", Network: "Commercial", Population: 1000, ProvidersPer100k: 40, RelativeToState: 0.8}, report.Results[0])
+	assert.Equal(t, models.AccessibilityResult{County: "Sedgwick", Network: "Commercial", Population: 3000, ProvidersPer100k: 53.333, RelativeToState: 1.067}, report.Results[1])
+
+	// Distance decay lowers the weight of the far location, shifting access towards Butler
+	query.Decay = config.DecayLinear
+	report, err = service.GetAccessibility(query)
+	require.NoError(t, err)
+	assert.Greater(t, report.Results[0].RelativeToState, 0.8)
+
+	// A county filter narrows the results but not the competing demand
+	query.County = "Butler"
+	report, err = service.GetAccessibility(query)
+	require.NoError(t, err)
+	require.Len(t, report.Results, 1)
+	assert.Equal(t, "Butler", report.Results[0].County)
+
+	query.County = "Atlantis"
+	_, err = service.GetAccessibility(query)
+	assert.True(t, errors.Is(err, ErrNotFound))
+
+	query.County, query.Decay = "", "cubic"
+	_, err = service.GetAccessibility(query)
+	var validationErr *ValidationError
+	assert.True(t, errors.As(err, &validationErr))
+}
+
+func TestDistanceDecays(t *testing.T) {
+	for decay, weigh := range distanceDecays {
+		assert.Equal(t, 1.0, weigh(0, 30), decay)
+	}
+	assert.Equal(t, 1.0, distanceDecays[config.DecayStep](30, 30))
+	assert.Equal(t, 0.5, distanceDecays[config.DecayLinear](15, 30))
+	assert.InDelta(t, 0, distanceDecays[config.DecayGaussian](30, 30), 1e-12)
+	// The gaussian keeps more weight than the linear decay near the centre
+	assert.Greater(t, distanceDecays[config.DecayGaussian](10, 30), distanceDecays[config.DecayLinear](10, 30))
+}
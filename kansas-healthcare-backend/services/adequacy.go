@@ -0,0 +1,184 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"kansas-healthcare-api/config"
+	"kansas-healthcare-api/models"
+	"sort"
+)
+
+// adequacyCounty is a county being evaluated and the points its access is measured from
+type adequacyCounty struct {
+	county      string
+	designation string
+	points      []models.Coordinate
+}
+
+// GetNetworkAdequacy judges each county, specialty and network against the CMS-style distance
+// standard for the county's designation. A combination passes when at least
+// config.AdequacyMinPercentage of the county's sample points have an in-network provider of the
+// specialty within the standard's maximum miles. With filter.IncludeOutOfState, providers in
+// neighboring states count too, and each result notes the points only they bring within reach.
+func (s *AnalyticsService) GetNetworkAdequacy(filter models.AdequacyFilter) (*models.AdequacyReport, error) {
+	designations, err := s.repo.GetCountyDesignations()
+	if err != nil {
+		return nil, err
+	}
+	standards, err := s.repo.GetAdequacyStandards()
+	if err != nil {
+		return nil, err
+	}
+
+	// Standards by specialty, then by designation
+	bySpecialty := make(map[string]map[string]models.AdequacyStandard)
+	for _, standard := range standards {
+		if filter.Specialty != "" && standard.Specialty != filter.Specialty {
+			continue
+		}
+		if bySpecialty[standard.Specialty] == nil {
+			bySpecialty[standard.Specialty] = make(map[string]models.AdequacyStandard)
+		}
+		bySpecialty[standard.Specialty][standard.Designation] = standard
+	}
+	if filter.Specialty != "" && len(bySpecialty) == 0 {
+		return nil, fmt.Errorf("adequacy standard for specialty %s %w", filter.Specialty, ErrNotFound)
+	}
+	specialties := make([]string, 0, len(bySpecialty))
+	for specialty := range bySpecialty {
+		specialties = append(specialties, specialty)
+	}
+	sort.Strings(specialties)
+
+	networks, err := s.evaluatedNetworks(filter.Network)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.AdequacyReport{
+		AsOf:              filter.AsOf.Format(config.AsOfDateFormat),
+		MinPercentage:     config.AdequacyMinPercentage,
+		IncludeOutOfState: filter.IncludeOutOfState,
+		Results:           []models.AdequacyResult{},
+	}
+
+	var counties []adequacyCounty
+	for _, designation := range designations {
+		if filter.County != "" && designation.County != filter.County {
+			continue
+		}
+		points, err := s.repo.GetCountySamplePoints(designation.County, config.AdequacySampleGridSize)
+		if errors.Is(err, ErrNotFound) {
+			report.UnevaluatedCounties = append(report.UnevaluatedCounties, designation.County)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		counties = append(counties, adequacyCounty{county: designation.County, designation: designation.Designation, points: points})
+	}
+	if filter.County != "" && len(counties) == 0 && len(report.UnevaluatedCounties) == 0 {
+		return nil, fmt.Errorf("designation for county %s %w", filter.County, ErrNotFound)
+	}
+	sort.Slice(counties, func(i, j int) bool { return counties[i].county < counties[j].county })
+	sort.Strings(report.UnevaluatedCounties)
+
+	for _, specialty := range specialties {
+		// Measure every county with a standard for this specialty in one lookup per network
+		var evaluated []adequacyCounty
+		var points []models.Coordinate
+		for _, c := range counties {
+			if _, ok := bySpecialty[specialty][c.designation]; ok {
+				evaluated = append(evaluated, c)
+				points = append(points, c.points...)
+			}
+		}
+		if len(evaluated) == 0 {
+			continue
+		}
+
+		for _, network := range networks {
+			distances, err := s.repo.GetNearestProviderDistances(points, specialty, network, filter.AsOf, filter.IncludeOutOfState)
+			if err != nil {
+				return nil, err
+			}
+			var inState []float64
+			if filter.IncludeOutOfState {
+				if inState, err = s.repo.GetNearestProviderDistances(points, specialty, network, filter.AsOf, false); err != nil {
+					return nil, err
+				}
+			}
+			for _, c := range evaluated {
+				standard := bySpecialty[specialty][c.designation]
+				met, metOutOfState := 0, 0
+				for i, distance := range distances[:len(c.points)] {
+					if distance <= standard.MaxMiles {
+						met++
+						if filter.IncludeOutOfState && inState[i] > standard.MaxMiles {
+							metOutOfState++
+						}
+					}
+				}
+				distances = distances[len(c.points):]
+				if filter.IncludeOutOfState {
+					inState = inState[len(c.points):]
+				}
+
+				result := models.AdequacyResult{
+					County:                 c.county,
+					Designation:            c.designation,
+					Specialty:              specialty,
+					Network:                network,
+					MaxMinutes:             standard.MaxMinutes,
+					MaxMiles:               standard.MaxMiles,
+					SampledPoints:          len(c.points),
+					PointsMeetingStandard:  met,
+					PercentMeetingStandard: percentage(met, len(c.points)),
+					PointsMetOutOfState:    metOutOfState,
+				}
+				result.Pass = result.PercentMeetingStandard >= config.AdequacyMinPercentage
+				report.Results = append(report.Results, result)
+			}
+		}
+	}
+
+	sort.SliceStable(report.Results, func(i, j int) bool {
+		a, b := report.Results[i], report.Results[j]
+		if a.County != b.County {
+			return a.County < b.County
+		}
+		if a.Specialty != b.Specialty {
+			return a.Specialty < b.Specialty
+		}
+		return a.Network < b.Network
+	})
+	report.Evaluated = len(report.Results)
+	for _, result := range report.Results {
+		if result.Pass {
+			report.Passed++
+		}
+	}
+	report.Failed = report.Evaluated - report.Passed
+	return report, nil
+}
+
+// evaluatedNetworks returns the network to evaluate, or every network with an affiliation on file
+func (s *AnalyticsService) evaluatedNetworks(networkId string) ([]string, error) {
+	if networkId != "" {
+		return []string{networkId}, nil
+	}
+	affiliations, err := s.repo.GetProviderNetworks()
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	var networks []string
+	for _, affiliation := range affiliations {
+		if !seen[affiliation.NetworkID] {
+			seen[affiliation.NetworkID] = true
+			networks = append(networks, affiliation.NetworkID)
+		}
+	}
+	sort.Strings(networks)
+	return networks, nil
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"kansas-healthcare-api/config"
+	"kansas-healthcare-api/models"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetNetworkAdequacy(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewAnalyticsService(mockRepo)
+
+	sedgwick := make([]models.Coordinate, 10)
+	for i := range sedgwick {
+		sedgwick[i] = models.Coordinate{Latitude: 37.6 + float64(i)/100, Longitude: -97.4}
+	}
+	greeley := []models.Coordinate{{Latitude: 38.48, Longitude: -101.8}, {Latitude: 38.5, Longitude: -101.8}}
+
+	mockRepo.On("GetCountyDesignations").Return([]models.CountyDesignation{
+		{County: "Sedgwick", Designation: config.DesignationMetro},
+		{County: "Greeley", Designation: config.DesignationCEAC},
+		{County: "Wyandotte", Designation: config.DesignationMetro},
+	}, nil)
+	mockRepo.On("GetAdequacyStandards").Return([]models.AdequacyStandard{
+		{Specialty: "Cardiology", Designation: config.DesignationMetro, MaxMinutes: 30, MaxMiles: 20},
+		{Specialty: "Cardiology", Designation: config.DesignationCEAC, MaxMinutes: 95, MaxMiles: 85},
+	}, nil)
+	mockRepo.On("GetCountySamplePoints", "Sedgwick", config.AdequacySampleGridSize).Return(sedgwick, nil)
+	mockRepo.On("GetCountySamplePoints", "Greeley", config.AdequacySampleGridSize).Return(greeley, nil)
+	mockRepo.On("GetCountySamplePoints", "Wyandotte", config.AdequacySampleGridSize).Return([]models.Coordinate(nil), fmt.Errorf("centroid for county Wyandotte %w", ErrNotFound))
+
+	// Counties are measured in name order in one lookup: Greeley's two points, then Sedgwick's ten
+	points := append(append([]models.Coordinate{}, greeley...), sedgwick...)
+	mockRepo.On("GetNearestProviderDistances", points, "Cardiology", "Commercial", testAsOf, false).
+		Return([]float64{80, math.Inf(1), 1, 2, 3, 4, 5, 6, 7, 8, 9, 25}, nil)
+
+	report, err := service.GetNetworkAdequacy(models.AdequacyFilter{Network: "Commercial", AsOf: testAsOf})
+	require.NoError(t, err)
+	assert.Equal(t, "2024-06-30", report.AsOf)
+	assert.Equal(t, []string{"Wyandotte"}, report.UnevaluatedCounties)
+	assert.Equal(t, 2, report.Evaluated)
+	assert.Equal(t, 1, report.Passed)
+	assert.Equal(t, 1, report.Failed)
+	assert.Equal(t, []models.AdequacyResult{
+		{County: "Greeley", Designation: config.DesignationCEAC, Specialty: "Cardiology", Network: "Commercial", MaxMinutes: 95, MaxMiles: 85,
+			SampledPoints: 2, PointsMeetingStandard: 1, PercentMeetingStandard: 50},
+		{County: "Sedgwick", Designation: config.DesignationMetro, Specialty: "Cardiology", Network: "Commercial", MaxMinutes: 30, MaxMiles: 20,
+			SampledPoints: 10, PointsMeetingStandard: 9, PercentMeetingStandard: 90, Pass: true},
+	}, report.Results)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetNetworkAdequacyUnknownFilters(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewAnalyticsService(mockRepo)
+
+	mockRepo.On("GetCountyDesignations").Return([]models.CountyDesignation{{County: "Sedgwick", Designation: config.DesignationMetro}}, nil)
+	mockRepo.On("GetAdequacyStandards").Return([]models.AdequacyStandard{
+		{Specialty: "Cardiology", Designation: config.DesignationMetro, MaxMinutes: 30, MaxMiles: 20},
+	}, nil)
+
+	_, err := service.GetNetworkAdequacy(models.AdequacyFilter{Specialty: "Radiology", Network: "Commercial", AsOf: testAsOf})
+	assert.True(t, errors.Is(err, ErrNotFound))
+
+	_, err = service.GetNetworkAdequacy(models.AdequacyFilter{County: "Atlantis", Network: "Commercial", AsOf: testAsOf})
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
+
+func TestGetNetworkAdequacyDefaultsToEveryNetwork(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewAnalyticsService(mockRepo)
+	points := []models.Coordinate{{Latitude: 37.684, Longitude: -97.461}}
+
+	mockRepo.On("GetCountyDesignations").Return([]models.CountyDesignation{{County: "Sedgwick", Designation: config.DesignationMetro}}, nil)
+	mockRepo.On("GetAdequacyStandards").Return([]models.AdequacyStandard{
+		{Specialty: "Cardiology", Designation: config.DesignationMetro, MaxMinutes: 30, MaxMiles: 20},
+	}, nil)
+	mockRepo.On("GetProviderNetworks").Return([]models.ProviderNetwork{
+		{ProviderID: "P1", NetworkID: "Medicare"}, {ProviderID: "P2", NetworkID: "Commercial"}, {ProviderID: "P3", NetworkID: "Medicare"},
+	}, nil)
+	mockRepo.On("GetCountySamplePoints", "Sedgwick", config.AdequacySampleGridSize).Return(points, nil)
+	mockRepo.On("GetNearestProviderDistances", points, "Cardiology", "Commercial", testAsOf, false).Return([]float64{5}, nil)
+	mockRepo.On("GetNearestProviderDistances", points, "Cardiology", "Medicare", testAsOf, false).Return([]float64{50}, nil)
+
+	report, err := service.GetNetworkAdequacy(models.AdequacyFilter{AsOf: testAsOf})
+	require.NoError(t, err)
+	require.Len(t, report.Results, 2)
+	assert.Equal(t, "Commercial", report.Results[0].Network)
+	assert.True(t, report.Results[0].Pass)
+	assert.Equal(t, "Medicare", report.Results[1].Network)
+	assert.False(t, report.Results[1].Pass)
+}
+
+func TestGetNetworkAdequacyWithOutOfStateProviders(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewAnalyticsService(mockRepo)
+	// Cherokee County's sample points, nearest the Missouri and Oklahoma lines
+	cherokee := []models.Coordinate{{Latitude: 37.02, Longitude: -94.73}, {Latitude: 37.1, Longitude: -94.8}, {Latitude: 37.2, Longitude: -95}}
+
+	mockRepo.On("GetCountyDesignations").Return([]models.CountyDesignation{{County: "Cherokee", Designation: config.DesignationRural}}, nil)
+	mockRepo.On("GetAdequacyStandards").Return([]models.AdequacyStandard{
+		{Specialty: "Cardiology", Designation: config.DesignationRural, MaxMinutes: 75, MaxMiles: 60},
+	}, nil)
+	mockRepo.On("GetCountySamplePoints", "Cherokee", config.AdequacySampleGridSize).Return(cherokee, nil)
+	mockRepo.On("GetNearestProviderDistances", cherokee, "Cardiology", "Commercial", testAsOf, true).Return([]float64{12, 18, 30}, nil)
+	mockRepo.On("GetNearestProviderDistances", cherokee, "Cardiology", "Commercial", testAsOf, false).Return([]float64{95, 88, 30}, nil)
+
+	// Only the Kansas providers are too far away; Joplin is in reach of every point
+	report, err := service.GetNetworkAdequacy(models.AdequacyFilter{Network: "Commercial", AsOf: testAsOf})
+	require.NoError(t, err)
+	assert.False(t, report.IncludeOutOfState)
+	require.Len(t, report.Results, 1)
+	assert.Equal(t, 1, report.Results[0].PointsMeetingStandard)
+	assert.Zero(t, report.Results[0].PointsMetOutOfState)
+	assert.False(t, report.Results[0].Pass)
+
+	report, err = service.GetNetworkAdequacy(models.AdequacyFilter{Network: "Commercial", AsOf: testAsOf, IncludeOutOfState: true})
+	require.NoError(t, err)
+	assert.True(t, report.IncludeOutOfState)
+	require.Len(t, report.Results, 1)
+	assert.Equal(t, 3, report.Results[0].PointsMeetingStandard)
+	assert.Equal(t, 2, report.Results[0].PointsMetOutOfState)
+	assert.True(t, report.Results[0].Pass)
+}
@@ -2,8 +2,10 @@ package services
 
 import (
 	"fmt"
+	"kansas-healthcare-api/config"
 	"kansas-healthcare-api/data"
 	"kansas-healthcare-api/models"
+	"time"
 )
 
 type AnalyticsService struct {
@@ -14,17 +16,17 @@ func NewAnalyticsService(repo data.Repository) *AnalyticsService {
 	return &AnalyticsService{repo: repo}
 }
 
-func (s *AnalyticsService) GetAllCountyData() ([]models.CountyStats, error) {
-	return s.repo.GetCountyStats()
+func (s *AnalyticsService) GetAllCountyData(asOf time.Time) ([]models.CountyStats, error) {
+	return s.repo.GetCountyStats(asOf)
 }
 
-func (s *AnalyticsService) GetCountyData(county string) (*models.CountyStats, error) {
-	return s.repo.GetCountyStatsByName(county)
+func (s *AnalyticsService) GetCountyData(county string, asOf time.Time) (*models.CountyStats, error) {
+	return s.repo.GetCountyStatsByName(county, asOf)
 }
 
-func (s *AnalyticsService) GetRecommendations(county string) []models.Recommendation {
+func (s *AnalyticsService) GetRecommendations(county string, asOf time.Time) []models.Recommendation {
 	// Generate dynamic recommendations based on county data
-	recommendations := s.generateDynamicRecommendations(county)
+	recommendations := s.generateDynamicRecommendations(county, asOf)
 	
 	// Set county for all recommendations
 	for i := range recommendations {
@@ -34,26 +36,31 @@ func (s *AnalyticsService) GetRecommendations(county string) []models.Recommenda
 	return recommendations
 }
 
-func (s *AnalyticsService) generateDynamicRecommendations(county string) []models.Recommendation {
+func (s *AnalyticsService) generateDynamicRecommendations(county string, asOf time.Time) []models.Recommendation {
 	var recommendations []models.Recommendation
 	idCounter := 1
 	
 	// Get county stats
-	countyStats, err := s.repo.GetCountyStatsByName(county)
+	countyStats, err := s.repo.GetCountyStatsByName(county, asOf)
 	if err != nil || countyStats == nil {
 		return recommendations
 	}
 	
-	// Get providers in county for detailed analysis
-	providers, _ := s.repo.GetProvidersInCounty(county)
-	terminatedCount := 0
+	// Providers with a service location in force in the county, and those on the county roster without one
+	activeProviders, _ := s.repo.GetActiveLocationProviders(county, asOf)
+	active := make(map[string]bool)
 	specialtyMap := make(map[string]int)
+	for _, provider := range activeProviders {
+		active[provider.ProviderID] = true
+		specialtyMap[provider.ProviderType]++
+	}
 	
+	providers, _ := s.repo.GetProvidersInCounty(county)
+	terminatedCount := 0
 	for _, provider := range providers {
-		if provider.Status == "Terminated" {
+		if !active[provider.ProviderID] {
 			terminatedCount++
 		}
-		specialtyMap[provider.ProviderType]++
 	}
 	
 	claimsPerProvider := float64(countyStats.ClaimsCount) / float64(countyStats.ProviderCount)
@@ -153,66 +160,120 @@ func (s *AnalyticsService) generateDynamicRecommendations(county string) []model
 	return recommendations
 }
 
-func (s *AnalyticsService) GetActiveProviderCount() (int, error) {
-	return s.repo.GetActiveProviderCount()
+func (s *AnalyticsService) GetActiveProviderCount(asOf time.Time) (int, error) {
+	providers, err := s.repo.GetActiveLocationProviders("", asOf)
+	if err != nil {
+		return 0, err
+	}
+	return len(providers), nil
+}
+
+// terminatedAnalysisResult reports a breakdown together with the criteria it was selected by
+func terminatedAnalysisResult(breakdown *models.TerminationBreakdown, criteria models.TerminationCriteria) *models.TerminatedAnalysisResult {
+	return &models.TerminatedAnalysisResult{
+		TermNetworkCount: breakdown.Total,
+		From:             criteria.From.Format(config.AsOfDateFormat),
+		To:               criteria.To.Format(config.AsOfDateFormat),
+		Reasons:          criteria.Reasons,
+		ByReason:         breakdown.ByReason,
+		BySpecialty:      breakdown.BySpecialty,
+		ByYear:           breakdown.ByYear,
+	}
+}
+
+// percentage returns numerator as a percentage of denominator, or 0 for an empty denominator
+func percentage(numerator, denominator int) float64 {
+	if denominator == 0 {
+		return 0
+	}
+	return float64(numerator) / float64(denominator) * 100
+}
+
+// setTerminationRate fills in the termination percentage over the denominator the criteria ask for
+func (s *AnalyticsService) setTerminationRate(result *models.TerminatedAnalysisResult, county, networkId string, criteria models.TerminationCriteria) error {
+	numerator, denominator, err := s.repo.GetTerminationRate(county, networkId, criteria)
+	if err != nil {
+		return err
+	}
+	result.Numerator = numerator
+	result.Denominator = denominator
+	result.DenominatorBasis = criteria.Denominator
+	if result.DenominatorBasis == "" {
+		result.DenominatorBasis = config.DenominatorInWindow
+	}
+	result.PercentageTerminated = percentage(numerator, denominator)
+	return nil
 }
 
-func (s *AnalyticsService) GetTerminatedNetworkAnalysis(networkId string) (*models.TerminatedAnalysisResult, error) {
+func (s *AnalyticsService) GetTerminatedNetworkAnalysis(networkId string, criteria models.TerminationCriteria) (*models.TerminatedAnalysisResult, error) {
 	totalActive, err := s.repo.GetActiveProviderCount()
 	if err != nil {
 		return nil, err
 	}
 
-	termCount, err := s.repo.GetTerminatedNetworkCount(networkId)
+	breakdown, err := s.repo.GetTerminatedNetworkBreakdown(networkId, criteria)
 	if err != nil {
 		return nil, err
 	}
 
-	locationCount, err := s.repo.GetTerminatedServiceLocationCount(networkId)
+	locationCount, err := s.repo.GetTerminatedServiceLocationCount(networkId, criteria)
 	if err != nil {
 		return nil, err
 	}
 
-	percentage := (float64(termCount) / float64(totalActive)) * 100
+	result := terminatedAnalysisResult(breakdown, criteria)
+	result.ServiceLocationCount = locationCount
+	result.TotalActiveProviders = totalActive
 
-	return &models.TerminatedAnalysisResult{
-		TermNetworkCount:     termCount,
-		ServiceLocationCount: locationCount,
-		PercentageTerminated: percentage,
-		TotalActiveProviders: totalActive,
-	}, nil
+	if criteria.LegacyPercentage {
+		// Terminations from this network over active providers in every network
+		result.Numerator = breakdown.Total
+		result.Denominator = totalActive
+		result.DenominatorBasis = config.DenominatorLegacy
+		result.PercentageTerminated = percentage(breakdown.Total, totalActive)
+		return result, nil
+	}
+	if err := s.setTerminationRate(result, "", networkId, criteria); err != nil {
+		return nil, err
+	}
+	return result, nil
 }
 
-func (s *AnalyticsService) GetCountyTerminatedNetworkAnalysis(county, networkId string) (*models.TerminatedAnalysisResult, error) {
-	totProvbyCounty, termNetworkCount, err := s.repo.GetCountyTerminatedNetworkCount(county, networkId)
+func (s *AnalyticsService) GetCountyTerminatedNetworkAnalysis(county, networkId string, criteria models.TerminationCriteria) (*models.TerminatedAnalysisResult, error) {
+	totProvbyCounty, breakdown, err := s.repo.GetCountyTerminatedNetworkBreakdown(county, networkId, criteria)
 	if err != nil {
 		return nil, err
 	}
 
-	// Calculate percentage: (TotProvbyCounty - (TotProvbyCounty - TermNetworkCount))/100
-	// This simplifies to: TermNetworkCount/100
-	percentage := float64(termNetworkCount) / 100.0
+	result := terminatedAnalysisResult(breakdown, criteria)
+	result.ServiceLocationCount = 0 // Not used in county analysis
+	result.TotalActiveProviders = totProvbyCounty
 
-	return &models.TerminatedAnalysisResult{
-		TermNetworkCount:     termNetworkCount,
-		ServiceLocationCount: 0, // Not used in county analysis
-		PercentageTerminated: percentage,
-		TotalActiveProviders: totProvbyCounty,
-	}, nil
+	if criteria.LegacyPercentage {
+		// Calculate percentage: (TotProvbyCounty - (TotProvbyCounty - TermNetworkCount))/100
+		// This simplifies to: TermNetworkCount/100
+		result.Numerator = breakdown.Total
+		result.Denominator = 100
+		result.DenominatorBasis = config.DenominatorLegacy
+		result.PercentageTerminated = float64(breakdown.Total) / 100.0
+		return result, nil
+	}
+	if err := s.setTerminationRate(result, county, networkId, criteria); err != nil {
+		return nil, err
+	}
+	return result, nil
 }
 
-func (s *AnalyticsService) GetSpecialtyDensityAnalysis(county string) (map[string]interface{}, error) {
-	providers, err := s.repo.GetProvidersInCounty(county)
+func (s *AnalyticsService) GetSpecialtyDensityAnalysis(county string, asOf time.Time) (map[string]interface{}, error) {
+	providers, err := s.repo.GetActiveLocationProviders(county, asOf)
 	if err != nil {
 		return nil, err
 	}
 
-	// Count providers by specialty
+	// Count providers with a service location in force in the county by specialty
 	specialtyCounts := make(map[string]int)
 	for _, provider := range providers {
-		if provider.Status == "Active" {
-			specialtyCounts[provider.ProviderType]++
-		}
+		specialtyCounts[provider.ProviderType]++
 	}
 
 	// Get county area for density calculation
@@ -257,6 +318,10 @@ func (s *AnalyticsService) GetSpecialtyDensityAnalysis(county string) (map[strin
 	}, nil
 }
 
-func (s *AnalyticsService) GetRadiusAnalysis(county string, radius int, networkId string) (map[string]interface{}, error) {
-	return s.repo.GetRadiusAnalysis(county, radius, networkId)
+func (s *AnalyticsService) GetRadiusAnalysis(county string, center *models.Coordinate, radius int, networkId string, asOf time.Time, includeOutOfState bool) (map[string]interface{}, error) {
+	return s.repo.GetRadiusAnalysis(county, center, radius, networkId, asOf, includeOutOfState)
+}
+
+func (s *AnalyticsService) GetCountyBoundary(county string) (*models.CountyBoundary, error) {
+	return s.repo.GetCountyBoundary(county)
 }
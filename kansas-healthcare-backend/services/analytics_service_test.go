@@ -1,24 +1,39 @@
 package services
 
 import (
+	"kansas-healthcare-api/data"
 	"kansas-healthcare-api/models"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
+// testAsOf is the fixed as-of date analytics tests run at
+var testAsOf = time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+
+func testTerminationCriteria() models.TerminationCriteria {
+	return models.TerminationCriteria{
+		AsOf:        testAsOf,
+		From:        time.Date(2019, 6, 30, 0, 0, 0, 0, time.UTC),
+		To:          time.Date(2022, 6, 30, 0, 0, 0, 0, time.UTC),
+		Reasons:     []string{"Left Network"},
+		Denominator: "in_window",
+	}
+}
+
 type MockRepository struct {
 	mock.Mock
 }
 
-func (m *MockRepository) GetCountyStats() ([]models.CountyStats, error) {
-	args := m.Called()
+func (m *MockRepository) GetCountyStats(asOf time.Time) ([]models.CountyStats, error) {
+	args := m.Called(asOf)
 	return args.Get(0).([]models.CountyStats), args.Error(1)
 }
 
-func (m *MockRepository) GetCountyStatsByName(county string) (*models.CountyStats, error) {
-	args := m.Called(county)
+func (m *MockRepository) GetCountyStatsByName(county string, asOf time.Time) (*models.CountyStats, error) {
+	args := m.Called(county, asOf)
 	return args.Get(0).(*models.CountyStats), args.Error(1)
 }
 
@@ -27,13 +42,18 @@ func (m *MockRepository) GetActiveProviderCount() (int, error) {
 	return args.Int(0), args.Error(1)
 }
 
-func (m *MockRepository) GetTerminatedNetworkCount(networkId string) (int, error) {
-	args := m.Called(networkId)
-	return args.Int(0), args.Error(1)
+func (m *MockRepository) GetActiveLocationProviders(county string, asOf time.Time) ([]models.Provider, error) {
+	args := m.Called(county, asOf)
+	return args.Get(0).([]models.Provider), args.Error(1)
+}
+
+func (m *MockRepository) GetTerminatedNetworkBreakdown(networkId string, criteria models.TerminationCriteria) (*models.TerminationBreakdown, error) {
+	args := m.Called(networkId, criteria)
+	return args.Get(0).(*models.TerminationBreakdown), args.Error(1)
 }
 
-func (m *MockRepository) GetTerminatedServiceLocationCount(networkId string) (int, error) {
-	args := m.Called(networkId)
+func (m *MockRepository) GetTerminatedServiceLocationCount(networkId string, criteria models.TerminationCriteria) (int, error) {
+	args := m.Called(networkId, criteria)
 	return args.Int(0), args.Error(1)
 }
 
@@ -42,13 +62,23 @@ func (m *MockRepository) GetProvidersInCounty(county string) ([]models.Provider,
 	return args.Get(0).([]models.Provider), args.Error(1)
 }
 
-func (m *MockRepository) GetRadiusAnalysis(county string, radius int, networkId string) (map[string]interface{}, error) {
-	args := m.Called(county, radius, networkId)
+func (m *MockRepository) GetRadiusAnalysis(county string, center *models.Coordinate, radius int, networkId string, asOf time.Time, includeOutOfState bool) (map[string]interface{}, error) {
+	args := m.Called(county, center, radius, networkId, asOf, includeOutOfState)
 	return args.Get(0).(map[string]interface{}), args.Error(1)
 }
 
-func (m *MockRepository) GetFilteredProviders(filter models.FilterRequest) ([]models.Provider, error) {
-	args := m.Called(filter)
+func (m *MockRepository) GetNearestProviders(query models.NearestProviderQuery) ([]models.NearestProvider, error) {
+	args := m.Called(query)
+	return args.Get(0).([]models.NearestProvider), args.Error(1)
+}
+
+func (m *MockRepository) GetNetworkProviderLocations(networkId, specialty string, asOf time.Time) ([]models.ProviderLocation, error) {
+	args := m.Called(networkId, specialty, asOf)
+	return args.Get(0).([]models.ProviderLocation), args.Error(1)
+}
+
+func (m *MockRepository) GetFilteredProviders(filter models.FilterRequest, asOf time.Time) ([]models.Provider, error) {
+	args := m.Called(filter, asOf)
 	return args.Get(0).([]models.Provider), args.Error(1)
 }
 
@@ -67,8 +97,13 @@ func (m *MockRepository) GetProviderServiceLocations() ([]models.ProviderService
 	return args.Get(0).([]models.ProviderServiceLocation), args.Error(1)
 }
 
-func (m *MockRepository) GetCountyTerminatedNetworkCount(county, networkId string) (int, int, error) {
-	args := m.Called(county, networkId)
+func (m *MockRepository) GetCountyTerminatedNetworkBreakdown(county, networkId string, criteria models.TerminationCriteria) (int, *models.TerminationBreakdown, error) {
+	args := m.Called(county, networkId, criteria)
+	return args.Int(0), args.Get(1).(*models.TerminationBreakdown), args.Error(2)
+}
+
+func (m *MockRepository) GetTerminationRate(county, networkId string, criteria models.TerminationCriteria) (int, int, error) {
+	args := m.Called(county, networkId, criteria)
 	return args.Int(0), args.Int(1), args.Error(2)
 }
 
@@ -82,6 +117,123 @@ func (m *MockRepository) GetSpecialtyDensityStandards() map[string]float64 {
 	return args.Get(0).(map[string]float64)
 }
 
+func (m *MockRepository) GetCountyBoundaries() ([]models.CountyBoundary, error) {
+	args := m.Called()
+	return args.Get(0).([]models.CountyBoundary), args.Error(1)
+}
+
+func (m *MockRepository) GetCountyBoundary(county string) (*models.CountyBoundary, error) {
+	args := m.Called(county)
+	return args.Get(0).(*models.CountyBoundary), args.Error(1)
+}
+
+func (m *MockRepository) GetAdequacyStandards() ([]models.AdequacyStandard, error) {
+	args := m.Called()
+	return args.Get(0).([]models.AdequacyStandard), args.Error(1)
+}
+
+func (m *MockRepository) GetCountyDesignations() ([]models.CountyDesignation, error) {
+	args := m.Called()
+	return args.Get(0).([]models.CountyDesignation), args.Error(1)
+}
+
+func (m *MockRepository) GetCountySamplePoints(county string, gridSize int) ([]models.Coordinate, error) {
+	args := m.Called(county, gridSize)
+	return args.Get(0).([]models.Coordinate), args.Error(1)
+}
+
+func (m *MockRepository) GetNearestProviderDistances(points []models.Coordinate, specialty, networkId string, asOf time.Time, includeOutOfState bool) ([]float64, error) {
+	args := m.Called(points, specialty, networkId, asOf, includeOutOfState)
+	return args.Get(0).([]float64), args.Error(1)
+}
+
+func (m *MockRepository) GetCoverageGrid(cellMiles float64) ([]models.GridCell, error) {
+	args := m.Called(cellMiles)
+	return args.Get(0).([]models.GridCell), args.Error(1)
+}
+
+func (m *MockRepository) GetCatchments(networkId, specialty string, asOf time.Time) ([]models.Catchment, error) {
+	args := m.Called(networkId, specialty, asOf)
+	return args.Get(0).([]models.Catchment), args.Error(1)
+}
+
+func (m *MockRepository) GetProviderLocationsWithin(points []models.Coordinate, radiusMiles float64, specialty, networkId string, asOf time.Time) ([][]models.LocationDistance, error) {
+	args := m.Called(points, radiusMiles, specialty, networkId, asOf)
+	return args.Get(0).([][]models.LocationDistance), args.Error(1)
+}
+
+func (m *MockRepository) GetCountyPopulation() ([]models.CountyPopulation, error) {
+	args := m.Called()
+	return args.Get(0).([]models.CountyPopulation), args.Error(1)
+}
+
+func (m *MockRepository) GetZipCentroids() ([]models.ZipCentroid, error) {
+	args := m.Called()
+	return args.Get(0).([]models.ZipCentroid), args.Error(1)
+}
+
+func (m *MockRepository) GetGeocoder() (*data.Geocoder, error) {
+	args := m.Called()
+	return args.Get(0).(*data.Geocoder), args.Error(1)
+}
+
+func (m *MockRepository) GetDataQualityReport() (*models.DataQualityReport, error) {
+	args := m.Called()
+	return args.Get(0).(*models.DataQualityReport), args.Error(1)
+}
+
+func (m *MockRepository) GetProvider(providerId string) (*models.Provider, error) {
+	args := m.Called(providerId)
+	return args.Get(0).(*models.Provider), args.Error(1)
+}
+
+func (m *MockRepository) CreateProvider(provider models.Provider) error {
+	return m.Called(provider).Error(0)
+}
+
+func (m *MockRepository) UpdateProvider(provider models.Provider, version string) error {
+	return m.Called(provider, version).Error(0)
+}
+
+func (m *MockRepository) DeleteProvider(providerId, version string) error {
+	return m.Called(providerId, version).Error(0)
+}
+
+func (m *MockRepository) GetProviderNetwork(providerId, networkId string, effectiveDate time.Time) (*models.ProviderNetwork, error) {
+	args := m.Called(providerId, networkId, effectiveDate)
+	return args.Get(0).(*models.ProviderNetwork), args.Error(1)
+}
+
+func (m *MockRepository) CreateProviderNetwork(network models.ProviderNetwork) error {
+	return m.Called(network).Error(0)
+}
+
+func (m *MockRepository) UpdateProviderNetwork(effectiveDate time.Time, network models.ProviderNetwork, version string) error {
+	return m.Called(effectiveDate, network, version).Error(0)
+}
+
+func (m *MockRepository) DeleteProviderNetwork(providerId, networkId string, effectiveDate time.Time, version string) error {
+	return m.Called(providerId, networkId, effectiveDate, version).Error(0)
+}
+
+func (m *MockRepository) GetServiceLocation(locationId string) (*models.ProviderServiceLocation, error) {
+	args := m.Called(locationId)
+	return args.Get(0).(*models.ProviderServiceLocation), args.Error(1)
+}
+
+func (m *MockRepository) CreateServiceLocation(location models.ProviderServiceLocation) (*models.ProviderServiceLocation, error) {
+	args := m.Called(location)
+	return args.Get(0).(*models.ProviderServiceLocation), args.Error(1)
+}
+
+func (m *MockRepository) UpdateServiceLocation(location models.ProviderServiceLocation, version string) error {
+	return m.Called(location, version).Error(0)
+}
+
+func (m *MockRepository) DeleteServiceLocation(locationId, version string) error {
+	return m.Called(locationId, version).Error(0)
+}
+
 func TestGetAllCountyData(t *testing.T) {
 	mockRepo := new(MockRepository)
 	service := NewAnalyticsService(mockRepo)
@@ -91,9 +243,9 @@ func TestGetAllCountyData(t *testing.T) {
 		{County: "Johnson", ProviderCount: 80, ClaimsCount: 4000, AvgClaimAmount: 300.00, Density: "medium"},
 	}
 	
-	mockRepo.On("GetCountyStats").Return(expectedData, nil)
+	mockRepo.On("GetCountyStats", testAsOf).Return(expectedData, nil)
 	
-	result, err := service.GetAllCountyData()
+	result, err := service.GetAllCountyData(testAsOf)
 	
 	assert.NoError(t, err)
 	assert.Equal(t, expectedData, result)
@@ -112,26 +264,52 @@ func TestGetCountyData(t *testing.T) {
 		Density: "high",
 	}
 	
-	mockRepo.On("GetCountyStatsByName", "Sedgwick").Return(expectedData, nil)
+	mockRepo.On("GetCountyStatsByName", "Sedgwick", testAsOf).Return(expectedData, nil)
 	
-	result, err := service.GetCountyData("Sedgwick")
+	result, err := service.GetCountyData("Sedgwick", testAsOf)
 	
 	assert.NoError(t, err)
 	assert.Equal(t, expectedData, result)
 	mockRepo.AssertExpectations(t)
 }
 
+func TestGetRecommendationsCountsFormerProvidersAsOf(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewAnalyticsService(mockRepo)
+	
+	stats := &models.CountyStats{County: "Sedgwick", ProviderCount: 1, ClaimsCount: 10, AvgClaimAmount: 100}
+	roster := []models.Provider{
+		{ProviderID: "1", ProviderType: "Primary Care", Status: "Active", County: "Sedgwick"},
+		{ProviderID: "2", ProviderType: "Cardiology", Status: "Active", County: "Sedgwick"},
+	}
+	mockRepo.On("GetCountyStatsByName", "Sedgwick", testAsOf).Return(stats, nil)
+	mockRepo.On("GetActiveLocationProviders", "Sedgwick", testAsOf).Return(roster[:1], nil)
+	mockRepo.On("GetProvidersInCounty", "Sedgwick").Return(roster, nil)
+	
+	recommendations := service.GetRecommendations("Sedgwick", testAsOf)
+	
+	var descriptions []string
+	for _, recommendation := range recommendations {
+		descriptions = append(descriptions, recommendation.Description)
+	}
+	assert.Contains(t, descriptions, "1 providers left network - consider re-engagement")
+	mockRepo.AssertExpectations(t)
+}
+
 func TestGetActiveProviderCount(t *testing.T) {
 	mockRepo := new(MockRepository)
 	service := NewAnalyticsService(mockRepo)
 	
-	expectedCount := 1000
-	mockRepo.On("GetActiveProviderCount").Return(expectedCount, nil)
+	providers := []models.Provider{
+		{ProviderID: "1", ProviderType: "Primary Care", Status: "Active"},
+		{ProviderID: "2", ProviderType: "Cardiology", Status: "Active"},
+	}
+	mockRepo.On("GetActiveLocationProviders", "", testAsOf).Return(providers, nil)
 	
-	result, err := service.GetActiveProviderCount()
+	result, err := service.GetActiveProviderCount(testAsOf)
 	
 	assert.NoError(t, err)
-	assert.Equal(t, expectedCount, result)
+	assert.Equal(t, 2, result)
 	mockRepo.AssertExpectations(t)
 }
 
@@ -143,7 +321,6 @@ func TestGetSpecialtyDensityAnalysis(t *testing.T) {
 		{ProviderID: "1", ProviderType: "Primary Care", Status: "Active"},
 		{ProviderID: "2", ProviderType: "Primary Care", Status: "Active"},
 		{ProviderID: "3", ProviderType: "Cardiology", Status: "Active"},
-		{ProviderID: "4", ProviderType: "Cardiology", Status: "Terminated"},
 	}
 	
 	standards := map[string]float64{
@@ -151,11 +328,11 @@ func TestGetSpecialtyDensityAnalysis(t *testing.T) {
 		"Cardiology": 0.6,
 	}
 	
-	mockRepo.On("GetProvidersInCounty", "Sedgwick").Return(providers, nil)
+	mockRepo.On("GetActiveLocationProviders", "Sedgwick", testAsOf).Return(providers, nil)
 	mockRepo.On("GetCountyArea", "Sedgwick").Return(700.0)
 	mockRepo.On("GetSpecialtyDensityStandards").Return(standards)
 	
-	result, err := service.GetSpecialtyDensityAnalysis("Sedgwick")
+	result, err := service.GetSpecialtyDensityAnalysis("Sedgwick", testAsOf)
 	
 	assert.NoError(t, err)
 	assert.Contains(t, result, "specialty_densities")
@@ -167,17 +344,74 @@ func TestGetTerminatedNetworkAnalysis(t *testing.T) {
 	mockRepo := new(MockRepository)
 	service := NewAnalyticsService(mockRepo)
 	
+	criteria := testTerminationCriteria()
+	breakdown := &models.TerminationBreakdown{
+		Total:       50,
+		ByReason:    map[string]int{"Left Network": 50},
+		BySpecialty: map[string]int{"Primary Care": 30, "Cardiology": 20},
+		ByYear:      map[int]int{2020: 20, 2021: 30},
+	}
 	mockRepo.On("GetActiveProviderCount").Return(1000, nil)
-	mockRepo.On("GetTerminatedNetworkCount", "Commercial").Return(50, nil)
-	mockRepo.On("GetTerminatedServiceLocationCount", "Commercial").Return(25, nil)
+	mockRepo.On("GetTerminatedNetworkBreakdown", "Commercial", criteria).Return(breakdown, nil)
+	mockRepo.On("GetTerminatedServiceLocationCount", "Commercial", criteria).Return(25, nil)
+	mockRepo.On("GetTerminationRate", "", "Commercial", criteria).Return(50, 400, nil)
 	
-	result, err := service.GetTerminatedNetworkAnalysis("Commercial")
+	result, err := service.GetTerminatedNetworkAnalysis("Commercial", criteria)
 	
 	assert.NoError(t, err)
 	assert.Equal(t, 50, result.TermNetworkCount)
 	assert.Equal(t, 25, result.ServiceLocationCount)
-	assert.Equal(t, 5.0, result.PercentageTerminated)
+	assert.Equal(t, 12.5, result.PercentageTerminated)
+	assert.Equal(t, 50, result.Numerator)
+	assert.Equal(t, 400, result.Denominator)
+	assert.Equal(t, "in_window", result.DenominatorBasis)
 	assert.Equal(t, 1000, result.TotalActiveProviders)
+	assert.Equal(t, "2019-06-30", result.From)
+	assert.Equal(t, "2022-06-30", result.To)
+	assert.Equal(t, breakdown.BySpecialty, result.BySpecialty)
+	assert.Equal(t, breakdown.ByYear, result.ByYear)
 	
 	mockRepo.AssertExpectations(t)
-}
\ No newline at end of file
+}
+
+func TestTerminatedPercentageHasNoEmptyDenominator(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewAnalyticsService(mockRepo)
+	
+	criteria := testTerminationCriteria()
+	empty := &models.TerminationBreakdown{ByReason: map[string]int{}, BySpecialty: map[string]int{}, ByYear: map[int]int{}}
+	mockRepo.On("GetCountyTerminatedNetworkBreakdown", "Greeley", "Tricare", criteria).Return(0, empty, nil)
+	mockRepo.On("GetTerminationRate", "Greeley", "Tricare", criteria).Return(0, 0, nil)
+	
+	result, err := service.GetCountyTerminatedNetworkAnalysis("Greeley", "Tricare", criteria)
+	
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, result.PercentageTerminated)
+	assert.Equal(t, 0, result.Denominator)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestLegacyTerminatedPercentages(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewAnalyticsService(mockRepo)
+	
+	criteria := testTerminationCriteria()
+	criteria.LegacyPercentage = true
+	breakdown := &models.TerminationBreakdown{Total: 50}
+	mockRepo.On("GetActiveProviderCount").Return(1000, nil)
+	mockRepo.On("GetTerminatedNetworkBreakdown", "Commercial", criteria).Return(breakdown, nil)
+	mockRepo.On("GetTerminatedServiceLocationCount", "Commercial", criteria).Return(25, nil)
+	mockRepo.On("GetCountyTerminatedNetworkBreakdown", "Sedgwick", "Commercial", criteria).Return(80, breakdown, nil)
+	
+	statewide, err := service.GetTerminatedNetworkAnalysis("Commercial", criteria)
+	assert.NoError(t, err)
+	assert.Equal(t, 5.0, statewide.PercentageTerminated)
+	assert.Equal(t, "legacy", statewide.DenominatorBasis)
+	
+	county, err := service.GetCountyTerminatedNetworkAnalysis("Sedgwick", "Commercial", criteria)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.5, county.PercentageTerminated)
+	assert.Equal(t, 80, county.TotalActiveProviders)
+	
+	mockRepo.AssertNotCalled(t, "GetTerminationRate", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"kansas-healthcare-api/config"
+	"kansas-healthcare-api/models"
+	"math"
+	"sort"
+)
+
+// GetCatchments partitions the state into the Voronoi catchments of the network's service
+// locations for the specialty and returns them as GeoJSON. The catchments tile every county, so
+// each part is credited with the county's population in proportion to the share of its area it
+// covers. Counties with a population but no boundary are listed as unevaluated.
+func (s *AnalyticsService) GetCatchments(query models.CatchmentQuery) (*models.CatchmentReport, error) {
+	catchments, err := s.repo.GetCatchments(query.Network, query.Specialty, query.AsOf)
+	if err != nil {
+		return nil, err
+	}
+	population, err := s.repo.GetCountyPopulation()
+	if err != nil {
+		return nil, err
+	}
+
+	countyArea := make(map[string]float64)
+	for _, catchment := range catchments {
+		for _, part := range catchment.Parts {
+			countyArea[part.County] += part.AreaSqMiles
+		}
+	}
+	residents := make(map[string]int, len(population))
+	for _, county := range population {
+		residents[county.County] = county.Population
+	}
+
+	report := &models.CatchmentReport{
+		FeatureCollection: *newFeatureCollection(),
+		Network:           query.Network,
+		Specialty:         query.Specialty,
+		AsOf:              query.AsOf.Format(config.AsOfDateFormat),
+		Sites:             len(catchments),
+	}
+	for _, catchment := range catchments {
+		area, served := 0.0, 0.0
+		counties := []models.CatchmentCounty{}
+		var polygons []models.Polygon
+		for _, part := range catchment.Parts {
+			share := 0.0
+			if part.County != "" {
+				share = float64(residents[part.County]) * part.AreaSqMiles / countyArea[part.County]
+				counties = append(counties, models.CatchmentCounty{
+					County:      part.County,
+					AreaSqMiles: roundedArea(part.AreaSqMiles),
+					Population:  int(math.Round(share)),
+				})
+			}
+			area += part.AreaSqMiles
+			served += share
+			polygons = append(polygons, part.Polygons...)
+		}
+
+		var geometry *models.Geometry
+		if len(polygons) > 0 {
+			geometry = &models.Geometry{Type: "MultiPolygon", Coordinates: polygons}
+		}
+		report.Features = append(report.Features, models.Feature{
+			Type:     "Feature",
+			Geometry: geometry,
+			Properties: map[string]interface{}{
+				"location_ids":      catchment.LocationIDs,
+				"provider_ids":      catchment.ProviderIDs,
+				"city":              catchment.City,
+				"county":            catchment.County,
+				"latitude":          catchment.Site.Latitude,
+				"longitude":         catchment.Site.Longitude,
+				"area_sq_miles":     roundedArea(area),
+				"population_served": int(math.Round(served)),
+				"counties":          counties,
+			},
+		})
+	}
+
+	if len(catchments) > 0 {
+		for _, county := range population {
+			if county.Population > 0 && countyArea[county.County] == 0 {
+				report.UnevaluatedCounties = append(report.UnevaluatedCounties, county.County)
+			}
+		}
+		sort.Strings(report.UnevaluatedCounties)
+	}
+	return report, nil
+}
+
+// roundedArea rounds an area to tenths of a square mile
+func roundedArea(sqMiles float64) float64 {
+	return math.Round(sqMiles*10) / 10
+}
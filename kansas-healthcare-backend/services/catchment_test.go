@@ -0,0 +1,104 @@
+package services
+
+import (
+	"errors"
+	"kansas-healthcare-api/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCatchments(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewAnalyticsService(mockRepo)
+
+	square := models.Polygon{{{-97.4, 37.6}, {-97.3, 37.6}, {-97.3, 37.7}, {-97.4, 37.6}}}
+	mockRepo.On("GetCatchments", "Commercial", "All", testAsOf).Return([]models.Catchment{
+		{
+			Site:        models.Coordinate{Latitude: 37.6872, Longitude: -97.3301},
+			City:        "Wichita",
+			County:      "Sedgwick",
+			LocationIDs: []string{"P1-1", "P2-1"},
+			ProviderIDs: []string{"P1", "P2"},
+			Parts: []models.CatchmentPart{
+				{County: "Butler", AreaSqMiles: 100, Polygons: []models.Polygon{square}},
+				{County: "Sedgwick", AreaSqMiles: 1000.04, Polygons: []models.Polygon{square}},
+			},
+		},
+		{
+			Site:        models.Coordinate{Latitude: 37.8172, Longitude: -96.8622},
+			City:        "El Dorado",
+			County:      "Butler",
+			LocationIDs: []string{"P3-1"},
+			ProviderIDs: []string{"P3"},
+			Parts:       []models.CatchmentPart{{County: "Butler", AreaSqMiles: 300, Polygons: []models.Polygon{square, square}}},
+		},
+	}, nil)
+	mockRepo.On("GetCatchments", "Medicare", "All", testAsOf).Return([]models.Catchment{}, nil)
+	mockRepo.On("GetCatchments", "Broken", "All", testAsOf).Return([]models.Catchment(nil), errors.New("database is locked"))
+	mockRepo.On("GetCountyPopulation").Return([]models.CountyPopulation{
+		{County: "Butler", Population: 4000},
+		{County: "Sedgwick", Population: 5000},
+		{County: "Wyandotte", Population: 169245},
+		{County: "Nowhere", Population: 0},
+	}, nil)
+
+	report, err := service.GetCatchments(models.CatchmentQuery{Network: "Commercial", Specialty: "All", AsOf: testAsOf})
+	require.NoError(t, err)
+	assert.Equal(t, "FeatureCollection", report.Type)
+	assert.Equal(t, "2024-06-30", report.AsOf)
+	assert.Equal(t, 2, report.Sites)
+	assert.Equal(t, []string{"Wyandotte"}, report.UnevaluatedCounties)
+	require.Len(t, report.Features, 2)
+
+	// Wichita covers a quarter of Butler's area, so it serves a quarter of Butler's residents
+	wichita := report.Features[0]
+	properties := catchmentProperties(t, wichita)
+	assert.Equal(t, &models.Geometry{Type: "MultiPolygon", Coordinates: []models.Polygon{square, square}}, wichita.Geometry)
+	assert.Equal(t, []string{"P1", "P2"}, properties["provider_ids"])
+	assert.Equal(t, 1100.0, properties["area_sq_miles"])
+	assert.Equal(t, 6000, properties["population_served"])
+	assert.Equal(t, []models.CatchmentCounty{
+		{County: "Butler", AreaSqMiles: 100, Population: 1000},
+		{County: "Sedgwick", AreaSqMiles: 1000, Population: 5000},
+	}, properties["counties"])
+	assert.Equal(t, 3000, catchmentProperties(t, report.Features[1])["population_served"])
+	assert.Equal(t, "El Dorado", catchmentProperties(t, report.Features[1])["city"])
+
+	// With no sites there is nothing to compare the counties against
+	report, err = service.GetCatchments(models.CatchmentQuery{Network: "Medicare", Specialty: "All", AsOf: testAsOf})
+	require.NoError(t, err)
+	assert.Zero(t, report.Sites)
+	assert.Empty(t, report.Features)
+	assert.Empty(t, report.UnevaluatedCounties)
+
+	_, err = service.GetCatchments(models.CatchmentQuery{Network: "Broken", Specialty: "All", AsOf: testAsOf})
+	assert.Error(t, err)
+}
+
+func TestGetCatchmentsWithoutBoundaries(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewAnalyticsService(mockRepo)
+
+	mockRepo.On("GetCatchments", "Commercial", "Cardiology", testAsOf).Return([]models.Catchment{
+		{City: "Wichita", LocationIDs: []string{"P1-1"}, ProviderIDs: []string{"P1"}, Parts: []models.CatchmentPart{{AreaSqMiles: 81234.56}}},
+	}, nil)
+	mockRepo.On("GetCountyPopulation").Return([]models.CountyPopulation{{County: "Sedgwick", Population: 5000}}, nil)
+
+	report, err := service.GetCatchments(models.CatchmentQuery{Network: "Commercial", Specialty: "Cardiology", AsOf: testAsOf})
+	require.NoError(t, err)
+	require.Len(t, report.Features, 1)
+	assert.Nil(t, report.Features[0].Geometry)
+	properties := catchmentProperties(t, report.Features[0])
+	assert.Equal(t, 81234.6, properties["area_sq_miles"])
+	assert.Equal(t, 0, properties["population_served"])
+	assert.Equal(t, []models.CatchmentCounty{}, properties["counties"])
+	assert.Equal(t, []string{"Sedgwick"}, report.UnevaluatedCounties)
+}
+
+func catchmentProperties(t *testing.T, feature models.Feature) map[string]interface{} {
+	properties, ok := feature.Properties.(map[string]interface{})
+	require.True(t, ok)
+	return properties
+}
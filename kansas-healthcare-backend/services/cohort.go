@@ -0,0 +1,121 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"kansas-healthcare-api/config"
+	"kansas-healthcare-api/models"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// CSVContentType is the media type of CSV responses (RFC 4180)
+const CSVContentType = "text/csv; charset=utf-8"
+
+// GetNetworkCohorts builds a network's cohort retention matrix. Each provider belongs to the
+// cohort of the year its first membership began, and counts as retained after k years when it had
+// an affiliation in force on the kth anniversary of that day, so providers who left and came back
+// count as retained again. Memberships beginning after query.AsOf are left out.
+func (s *AnalyticsService) GetNetworkCohorts(query models.CohortQuery) (*models.CohortReport, error) {
+	spells, _, err := s.networkMembership(query.Network)
+	if err != nil {
+		return nil, err
+	}
+
+	// Spells are ordered by provider and start, so each provider's first spell comes first
+	type member struct {
+		joined time.Time
+		spells []membershipSpell
+	}
+	var members []*member
+	byProvider := make(map[string]*member)
+	for _, spell := range spells {
+		if spell.start.After(query.AsOf) {
+			continue
+		}
+		m := byProvider[spell.providerID]
+		if m == nil {
+			m = &member{joined: spell.start}
+			byProvider[spell.providerID] = m
+			members = append(members, m)
+		}
+		m.spells = append(m.spells, spell)
+	}
+
+	cohorts := make(map[int]*models.Cohort)
+	latestJoin := make(map[int]time.Time)
+	var years []int
+	for _, m := range members {
+		year := m.joined.Year()
+		if cohorts[year] == nil {
+			cohorts[year] = &models.Cohort{JoinYear: year, Active: []int{}, Percent: []float64{}}
+			years = append(years, year)
+		}
+		cohorts[year].Providers++
+		if m.joined.After(latestJoin[year]) {
+			latestJoin[year] = m.joined
+		}
+	}
+	// A cohort is followed only to the anniversaries every member has reached
+	for year, cohort := range cohorts {
+		for k := 1; !latestJoin[year].AddDate(k, 0, 0).After(query.AsOf); k++ {
+			cohort.Active = append(cohort.Active, 0)
+		}
+	}
+	for _, m := range members {
+		cohort := cohorts[m.joined.Year()]
+		for k := range cohort.Active {
+			anniversary := m.joined.AddDate(k+1, 0, 0)
+			for _, spell := range m.spells {
+				if !spell.start.After(anniversary) && spell.end.After(anniversary) {
+					cohort.Active[k]++
+					break
+				}
+			}
+		}
+	}
+
+	report := &models.CohortReport{Network: query.Network, AsOf: query.AsOf.Format(config.AsOfDateFormat), Cohorts: []models.Cohort{}}
+	sort.Ints(years)
+	for _, year := range years {
+		cohort := cohorts[year]
+		for _, active := range cohort.Active {
+			cohort.Percent = append(cohort.Percent, math.Round(float64(active)/float64(cohort.Providers)*1000)/10)
+		}
+		if len(cohort.Active) > report.Years {
+			report.Years = len(cohort.Active)
+		}
+		report.Cohorts = append(report.Cohorts, *cohort)
+	}
+	return report, nil
+}
+
+// CohortCSV renders a cohort retention matrix as CSV: a header row, then one row per join year
+// with the cohort's size and the percentage retained after each year, blank where the cohort has
+// not reached that anniversary
+func CohortCSV(report *models.CohortReport) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	header := []string{"join_year", "providers"}
+	for k := 1; k <= report.Years; k++ {
+		header = append(header, "year_"+strconv.Itoa(k))
+	}
+	if err := w.Write(header); err != nil {
+		return nil, err
+	}
+	for _, cohort := range report.Cohorts {
+		row := make([]string, len(header))
+		row[0] = strconv.Itoa(cohort.JoinYear)
+		row[1] = strconv.Itoa(cohort.Providers)
+		for k, percent := range cohort.Percent {
+			row[k+2] = strconv.FormatFloat(percent, 'f', 1, 64)
+		}
+		if err := w.Write(row); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
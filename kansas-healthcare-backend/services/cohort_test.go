@@ -0,0 +1,62 @@
+package services
+
+import (
+	"errors"
+	"kansas-healthcare-api/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetNetworkCohorts(t *testing.T) {
+	service := NewAnalyticsService(newMembershipRepository())
+
+	report, err := service.GetNetworkCohorts(models.CohortQuery{Network: "Commercial", AsOf: testAsOf})
+	require.NoError(t, err)
+	assert.Equal(t, "2024-06-30", report.AsOf)
+	assert.Equal(t, 4, report.Years)
+	// P3's renewed contract is one membership from 2022, and P4, who joined in March 2024, has no
+	// anniversary yet
+	assert.Equal(t, []models.Cohort{
+		{JoinYear: 2020, Providers: 1, Active: []int{1, 1, 1, 1}, Percent: []float64{100, 100, 100, 100}},
+		{JoinYear: 2022, Providers: 1, Active: []int{1, 1}, Percent: []float64{100, 100}},
+		{JoinYear: 2023, Providers: 1, Active: []int{1}, Percent: []float64{100}},
+		{JoinYear: 2024, Providers: 1, Active: []int{}, Percent: []float64{}},
+	}, report.Cohorts)
+
+	csv, err := CohortCSV(report)
+	require.NoError(t, err)
+	assert.Equal(t, "join_year,providers,year_1,year_2,year_3,year_4\n"+
+		"2020,1,100.0,100.0,100.0,100.0\n"+
+		"2022,1,100.0,100.0,,\n"+
+		"2023,1,100.0,,,\n"+
+		"2024,1,,,,\n", string(csv))
+
+	_, err = service.GetNetworkCohorts(models.CohortQuery{Network: "Medicaid", AsOf: testAsOf})
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
+
+func TestGetNetworkCohortsCountsReturningProviders(t *testing.T) {
+	open := date(9999, time.December, 31)
+	mockRepo := new(MockRepository)
+	mockRepo.On("GetProviderNetworks").Return([]models.ProviderNetwork{
+		// Left for half a year and came back
+		{ProviderID: "Q1", NetworkID: "Commercial", EffectiveDate: date(2019, time.January, 1), TerminationDate: date(2020, time.June, 1)},
+		{ProviderID: "Q1", NetworkID: "Commercial", EffectiveDate: date(2021, time.January, 1), TerminationDate: open},
+		{ProviderID: "Q2", NetworkID: "Commercial", EffectiveDate: date(2019, time.July, 1), TerminationDate: date(2020, time.March, 1)},
+		{ProviderID: "Q3", NetworkID: "Commercial", EffectiveDate: date(2019, time.March, 1), TerminationDate: open},
+		{ProviderID: "Q4", NetworkID: "Medicare", EffectiveDate: date(2019, time.March, 1), TerminationDate: open},
+		// Joins after the as-of date
+		{ProviderID: "Q5", NetworkID: "Commercial", EffectiveDate: date(2023, time.January, 1), TerminationDate: open},
+	}, nil)
+	mockRepo.On("GetProviders").Return([]models.Provider{}, nil)
+	service := NewAnalyticsService(mockRepo)
+
+	report, err := service.GetNetworkCohorts(models.CohortQuery{Network: "Commercial", AsOf: date(2022, time.December, 31)})
+	require.NoError(t, err)
+	assert.Equal(t, []models.Cohort{
+		{JoinYear: 2019, Providers: 3, Active: []int{2, 2, 2}, Percent: []float64{66.7, 66.7, 66.7}},
+	}, report.Cohorts)
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"kansas-healthcare-api/config"
+	"kansas-healthcare-api/models"
+	"math"
+	"sort"
+)
+
+// GetCoverageGaps lays a grid over the state and returns, as GeoJSON, the cells whose centre is
+// further than query.ThresholdMiles from the nearest in-network provider of the specialty. Each
+// county with cells is summarized, the worst covered first.
+func (s *AnalyticsService) GetCoverageGaps(query models.CoverageGapQuery) (*models.CoverageGapReport, error) {
+	cells, err := s.repo.GetCoverageGrid(query.CellMiles)
+	if err != nil {
+		return nil, err
+	}
+	centers := make([]models.Coordinate, len(cells))
+	for i, cell := range cells {
+		centers[i] = cell.Center
+	}
+	distances, err := s.repo.GetNearestProviderDistances(centers, query.Specialty, query.Network, query.AsOf, false)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.CoverageGapReport{
+		FeatureCollection: *newFeatureCollection(),
+		Network:           query.Network,
+		Specialty:         query.Specialty,
+		AsOf:              query.AsOf.Format(config.AsOfDateFormat),
+		CellMiles:         query.CellMiles,
+		ThresholdMiles:    query.ThresholdMiles,
+		TotalCells:        len(cells),
+		Counties:          []models.CountyCoverage{},
+	}
+	byCounty := make(map[string]*models.CountyCoverage)
+	maxDistance := make(map[string]float64)
+	for i, cell := range cells {
+		distance := distances[i]
+		gap := distance > query.ThresholdMiles
+		if gap {
+			report.GapCells++
+			report.Features = append(report.Features, models.Feature{
+				Type:     "Feature",
+				Geometry: &models.Geometry{Type: "Polygon", Coordinates: cell.Polygon},
+				Properties: map[string]interface{}{
+					"county":         cell.County,
+					"distance_miles": roundedMiles(distance),
+				},
+			})
+		}
+
+		if cell.County == "" {
+			continue
+		}
+		coverage, ok := byCounty[cell.County]
+		if !ok {
+			coverage = &models.CountyCoverage{County: cell.County}
+			byCounty[cell.County] = coverage
+		}
+		coverage.Cells++
+		if gap {
+			coverage.GapCells++
+		}
+		maxDistance[cell.County] = math.Max(maxDistance[cell.County], distance)
+	}
+
+	for county, coverage := range byCounty {
+		coverage.GapPercent = percentage(coverage.GapCells, coverage.Cells)
+		coverage.MaxDistanceMiles = roundedMiles(maxDistance[county])
+		report.Counties = append(report.Counties, *coverage)
+	}
+	sort.Slice(report.Counties, func(i, j int) bool {
+		a, b := report.Counties[i], report.Counties[j]
+		if a.GapPercent != b.GapPercent {
+			return a.GapPercent > b.GapPercent
+		}
+		return a.County < b.County
+	})
+	return report, nil
+}
+
+// roundedMiles rounds a distance to hundredths of a mile, or returns nil for the infinite distance
+// of a point with no provider to reach
+func roundedMiles(miles float64) *float64 {
+	if math.IsInf(miles, 1) {
+		return nil
+	}
+	rounded := math.Round(miles*100) / 100
+	return &rounded
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"encoding/json"
+	"kansas-healthcare-api/models"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCoverageGaps(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewAnalyticsService(mockRepo)
+
+	cell := func(county string, lat, lng float64) models.GridCell {
+		return models.GridCell{
+			County:  county,
+			Center:  models.Coordinate{Latitude: lat, Longitude: lng},
+			Polygon: models.Polygon{{{lng - 0.1, lat - 0.1}, {lng + 0.1, lat - 0.1}, {lng + 0.1, lat + 0.1}, {lng - 0.1, lat + 0.1}, {lng - 0.1, lat - 0.1}}},
+		}
+	}
+	cells := []models.GridCell{
+		cell("Sedgwick", 37.6, -97.4),
+		cell("Sedgwick", 37.8, -97.4),
+		cell("Stevens", 37.2, -101.3),
+		cell("Stevens", 37.0, -101.3),
+	}
+	centers := []models.Coordinate{cells[0].Center, cells[1].Center, cells[2].Center, cells[3].Center}
+	mockRepo.On("GetCoverageGrid", 10.0).Return(cells, nil)
+	mockRepo.On("GetNearestProviderDistances", centers, "Cardiology", "Commercial", testAsOf, false).Return([]float64{2.5, 12.345, 55.555, math.Inf(1)}, nil)
+
+	report, err := service.GetCoverageGaps(models.CoverageGapQuery{Network: "Commercial", Specialty: "Cardiology", CellMiles: 10, ThresholdMiles: 30, AsOf: testAsOf})
+	require.NoError(t, err)
+	assert.Equal(t, 4, report.TotalCells)
+	assert.Equal(t, 2, report.GapCells)
+	assert.Equal(t, "2024-06-30", report.AsOf)
+
+	// Only the two Stevens cells are gaps, the unreachable one with a null distance
+	require.Len(t, report.Features, 2)
+	assert.Equal(t, &models.Geometry{Type: "Polygon", Coordinates: cells[2].Polygon}, report.Features[0].Geometry)
+	assert.Equal(t, 55.56, *report.Features[0].Properties.(map[string]interface{})["distance_miles"].(*float64))
+	assert.Nil(t, report.Features[1].Properties.(map[string]interface{})["distance_miles"])
+
+	// Stevens is the worse covered county and has a cell with no provider at all
+	require.Len(t, report.Counties, 2)
+	assert.Equal(t, models.CountyCoverage{County: "Stevens", Cells: 2, GapCells: 2, GapPercent: 100}, report.Counties[0])
+	sedgwick := report.Counties[1]
+	assert.Equal(t, "Sedgwick", sedgwick.County)
+	assert.Zero(t, sedgwick.GapCells)
+	require.NotNil(t, sedgwick.MaxDistanceMiles)
+	assert.Equal(t, 12.35, *sedgwick.MaxDistanceMiles)
+
+	// The report is a GeoJSON FeatureCollection with the summaries alongside
+	body, err := json.Marshal(report)
+	require.NoError(t, err)
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(body, &decoded))
+	assert.Equal(t, "FeatureCollection", decoded["type"])
+	assert.Len(t, decoded["features"], 2)
+	assert.Len(t, decoded["counties"], 2)
+
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"errors"
+	"kansas-healthcare-api/config"
+	"kansas-healthcare-api/models"
+	"sort"
+)
+
+// demandPoint is one share of a county's population, placed on the county's sample grid
+type demandPoint struct {
+	county     string
+	population float64
+}
+
+// populationDemand is the state's population spread over demand points. Counties without a
+// centroid cannot be placed and are listed as unevaluated.
+type populationDemand struct {
+	points      []models.Coordinate
+	shares      []demandPoint
+	residents   map[string]int
+	total       int
+	known       map[string]bool
+	unevaluated []string
+}
+
+// populationDemand spreads each county's population evenly over a grid of points across it
+func (s *AnalyticsService) populationDemand() (*populationDemand, error) {
+	population, err := s.repo.GetCountyPopulation()
+	if err != nil {
+		return nil, err
+	}
+	d := &populationDemand{residents: make(map[string]int), known: make(map[string]bool)}
+	for _, county := range population {
+		d.known[county.County] = true
+		if county.Population == 0 {
+			continue
+		}
+		samples, err := s.repo.GetCountySamplePoints(county.County, config.PopulationDemandGridSize)
+		if errors.Is(err, ErrNotFound) {
+			d.unevaluated = append(d.unevaluated, county.County)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		d.residents[county.County] = county.Population
+		d.total += county.Population
+		for _, sample := range samples {
+			d.points = append(d.points, sample)
+			d.shares = append(d.shares, demandPoint{county: county.County, population: float64(county.Population) / float64(len(samples))})
+		}
+	}
+	sort.Strings(d.unevaluated)
+	return d, nil
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"kansas-healthcare-api/models"
+	"time"
+)
+
+// GeoJSONContentType is the media type of GeoJSON responses (RFC 7946)
+const GeoJSONContentType = "application/geo+json"
+
+func newFeatureCollection() *models.FeatureCollection {
+	return &models.FeatureCollection{Type: "FeatureCollection", Features: []models.Feature{}}
+}
+
+// pointGeometry returns a GeoJSON Point, or nil for a location that was never geocoded
+func pointGeometry(latitude, longitude float64) *models.Geometry {
+	if latitude == 0 && longitude == 0 {
+		return nil
+	}
+	return &models.Geometry{Type: "Point", Coordinates: [2]float64{longitude, latitude}}
+}
+
+// boundaryGeometry returns a county boundary as a GeoJSON Polygon, or a MultiPolygon when the
+// county has several parts
+func boundaryGeometry(boundary models.CountyBoundary) *models.Geometry {
+	if len(boundary.Polygons) == 1 {
+		return &models.Geometry{Type: "Polygon", Coordinates: boundary.Polygons[0]}
+	}
+	return &models.Geometry{Type: "MultiPolygon", Coordinates: boundary.Polygons}
+}
+
+// GetServiceLocationFeatures returns the service locations GetNetworkProviderLocations selects as
+// Point features carrying the provider and address fields
+func (s *ProviderService) GetServiceLocationFeatures(networkId, specialty string, asOf time.Time) (*models.FeatureCollection, error) {
+	locations, err := s.repo.GetNetworkProviderLocations(networkId, specialty, asOf)
+	if err != nil {
+		return nil, err
+	}
+	collection := newFeatureCollection()
+	for _, location := range locations {
+		collection.Features = append(collection.Features, models.Feature{
+			Type:       "Feature",
+			Geometry:   pointGeometry(location.Latitude, location.Longitude),
+			Properties: location,
+		})
+	}
+	return collection, nil
+}
+
+// GetCountyFeatures returns the county statistics as features outlined by each county's
+// boundary. Counties without a boundary have a null geometry.
+func (s *AnalyticsService) GetCountyFeatures(asOf time.Time) (*models.FeatureCollection, error) {
+	stats, err := s.repo.GetCountyStats(asOf)
+	if err != nil {
+		return nil, err
+	}
+	boundaries, err := s.repo.GetCountyBoundaries()
+	if err != nil {
+		return nil, err
+	}
+	byCounty := make(map[string]models.CountyBoundary, len(boundaries))
+	for _, boundary := range boundaries {
+		byCounty[boundary.County] = boundary
+	}
+
+	collection := newFeatureCollection()
+	for _, county := range stats {
+		feature := models.Feature{Type: "Feature", Properties: county}
+		if boundary, ok := byCounty[county.County]; ok {
+			feature.Geometry = boundaryGeometry(boundary)
+		}
+		collection.Features = append(collection.Features, feature)
+	}
+	return collection, nil
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"encoding/json"
+	"kansas-healthcare-api/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetServiceLocationFeatures(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewProviderService(mockRepo)
+
+	mockRepo.On("GetNetworkProviderLocations", "Commercial", "Cardiology", testAsOf).Return([]models.ProviderLocation{
+		{ProviderID: "P0001", NPI: "1234567890", Specialty: "Cardiology", LocationID: "P0001-1", City: "Wichita", County: "Sedgwick", Latitude: 37.6872, Longitude: -97.3301},
+		// Never geocoded, so kept without a geometry
+		{ProviderID: "P0002", NPI: "1234567891", Specialty: "Cardiology", LocationID: "P0002-1", City: "Derby", County: "Sedgwick"},
+	}, nil)
+
+	collection, err := service.GetServiceLocationFeatures("Commercial", "Cardiology", testAsOf)
+	require.NoError(t, err)
+
+	body, err := json.Marshal(collection)
+	require.NoError(t, err)
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(body, &decoded))
+	assert.Equal(t, "FeatureCollection", decoded["type"])
+
+	features := decoded["features"].([]interface{})
+	require.Len(t, features, 2)
+	first := features[0].(map[string]interface{})
+	assert.Equal(t, "Feature", first["type"])
+	assert.Equal(t, map[string]interface{}{"type": "Point", "coordinates": []interface{}{-97.3301, 37.6872}}, first["geometry"])
+	assert.Equal(t, "P0001-1", first["properties"].(map[string]interface{})["location_id"])
+	assert.Equal(t, "Cardiology", first["properties"].(map[string]interface{})["specialty"])
+	assert.Nil(t, features[1].(map[string]interface{})["geometry"])
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetCountyFeatures(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewAnalyticsService(mockRepo)
+
+	ring := [][2]float64{{-97.81, 37.47}, {-97.15, 37.47}, {-97.15, 37.91}, {-97.81, 37.91}, {-97.81, 37.47}}
+	island := [][2]float64{{-95.0, 39.5}, {-94.9, 39.5}, {-94.9, 39.6}, {-95.0, 39.5}}
+	stats := []models.CountyStats{
+		{County: "Sedgwick", ProviderCount: 120, ClaimsCount: 5000, AvgClaimAmount: 250.5, Density: "High"},
+		{County: "Leavenworth", ProviderCount: 12, ClaimsCount: 800},
+		{County: "Atlantis", ProviderCount: 1},
+	}
+	mockRepo.On("GetCountyStats", testAsOf).Return(stats, nil)
+	mockRepo.On("GetCountyBoundaries").Return([]models.CountyBoundary{
+		{County: "Sedgwick", Polygons: []models.Polygon{{ring}}},
+		{County: "Leavenworth", Polygons: []models.Polygon{{ring}, {island}}},
+	}, nil)
+
+	collection, err := service.GetCountyFeatures(testAsOf)
+	require.NoError(t, err)
+	require.Len(t, collection.Features, 3)
+
+	assert.Equal(t, stats[0], collection.Features[0].Properties)
+	assert.Equal(t, &models.Geometry{Type: "Polygon", Coordinates: models.Polygon{ring}}, collection.Features[0].Geometry)
+	assert.Equal(t, "MultiPolygon", collection.Features[1].Geometry.Type)
+	assert.Nil(t, collection.Features[2].Geometry)
+
+	mockRepo.AssertExpectations(t)
+}
@@ -1,20 +1,54 @@
 package services
 
-import "kansas-healthcare-api/models"
+import (
+	"kansas-healthcare-api/models"
+	"time"
+)
 
+// AnalyticsServiceInterface methods that take asOf (or criteria.AsOf) report network and location
+// activity as it stood at that date. Active providers are counted by their service locations in force
+// at that date; provider status carries no dates, so it is always taken from the current roster.
 type AnalyticsServiceInterface interface {
-	GetAllCountyData() ([]models.CountyStats, error)
-	GetCountyData(county string) (*models.CountyStats, error)
-	GetRecommendations(county string) []models.Recommendation
-	GetActiveProviderCount() (int, error)
-	GetTerminatedNetworkAnalysis(networkId string) (*models.TerminatedAnalysisResult, error)
-	GetCountyTerminatedNetworkAnalysis(county, networkId string) (*models.TerminatedAnalysisResult, error)
-	GetSpecialtyDensityAnalysis(county string) (map[string]interface{}, error)
-	GetRadiusAnalysis(county string, radius int, networkId string) (map[string]interface{}, error)
+	GetAllCountyData(asOf time.Time) ([]models.CountyStats, error)
+	GetCountyData(county string, asOf time.Time) (*models.CountyStats, error)
+	GetRecommendations(county string, asOf time.Time) []models.Recommendation
+	GetActiveProviderCount(asOf time.Time) (int, error)
+	GetTerminatedNetworkAnalysis(networkId string, criteria models.TerminationCriteria) (*models.TerminatedAnalysisResult, error)
+	GetCountyTerminatedNetworkAnalysis(county, networkId string, criteria models.TerminationCriteria) (*models.TerminatedAnalysisResult, error)
+	GetSpecialtyDensityAnalysis(county string, asOf time.Time) (map[string]interface{}, error)
+	GetRadiusAnalysis(county string, center *models.Coordinate, radius int, networkId string, asOf time.Time, includeOutOfState bool) (map[string]interface{}, error)
+	GetNetworkAdequacy(filter models.AdequacyFilter) (*models.AdequacyReport, error)
+	GetCountyBoundary(county string) (*models.CountyBoundary, error)
+	GetCountyFeatures(asOf time.Time) (*models.FeatureCollection, error)
+	GetCoverageGaps(query models.CoverageGapQuery) (*models.CoverageGapReport, error)
+	GetAccessibility(query models.AccessibilityQuery) (*models.AccessibilityReport, error)
+	GetRecruitmentSites(query models.RecruitmentQuery) (*models.RecruitmentPlan, error)
+	GetCatchments(query models.CatchmentQuery) (*models.CatchmentReport, error)
+	GetNetworkTimeSeries(query models.TimeSeriesQuery) (*models.TimeSeriesReport, error)
+	GetRetention(query models.RetentionQuery) (*models.RetentionReport, error)
+	GetNetworkCohorts(query models.CohortQuery) (*models.CohortReport, error)
 }
 
 type ProviderServiceInterface interface {
 	GetAllProviders() ([]models.Provider, error)
 	GetProviderNetworks() ([]models.ProviderNetwork, error)
-	GetFilteredProviders(filter models.FilterRequest) ([]models.Provider, error)
+	GetFilteredProviders(filter models.FilterRequest, asOf time.Time) ([]models.Provider, error)
+	GetNearestProviders(query models.NearestProviderQuery) ([]models.NearestProvider, error)
+	GetServiceLocationFeatures(networkId, specialty string, asOf time.Time) (*models.FeatureCollection, error)
+	GetDataQualityReport() (*models.DataQualityReport, error)
+
+	// Roster writes return the stored record with its version, which clients echo back in
+	// If-Match to update or delete it
+	GetProvider(providerId string) (*models.Provider, string, error)
+	CreateProvider(provider models.Provider) (*models.Provider, string, error)
+	UpdateProvider(providerId string, provider models.Provider, version string) (*models.Provider, string, error)
+	DeleteProvider(providerId, version string) error
+	GetProviderNetwork(providerId, networkId string, effectiveDate time.Time) (*models.ProviderNetwork, string, error)
+	CreateProviderNetwork(providerId string, network models.ProviderNetwork) (*models.ProviderNetwork, string, error)
+	UpdateProviderNetwork(providerId, networkId string, effectiveDate time.Time, network models.ProviderNetwork, version string) (*models.ProviderNetwork, string, error)
+	DeleteProviderNetwork(providerId, networkId string, effectiveDate time.Time, version string) error
+	GetServiceLocation(locationId string) (*models.ProviderServiceLocation, string, error)
+	CreateServiceLocation(providerId string, location models.ProviderServiceLocation) (*models.ProviderServiceLocation, string, error)
+	UpdateServiceLocation(locationId string, location models.ProviderServiceLocation, version string) (*models.ProviderServiceLocation, string, error)
+	DeleteServiceLocation(locationId, version string) error
 }
\ No newline at end of file
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"fmt"
+	"kansas-healthcare-api/config"
+	"kansas-healthcare-api/models"
+	"sort"
+	"time"
+)
+
+// membershipSpell is one unbroken stretch of a provider's membership of a network, from the day
+// the provider joined up to the day it ended, the first day out of the network
+type membershipSpell struct {
+	providerID string
+	networkID  string
+	start      time.Time
+	end        time.Time
+}
+
+// networkMembership returns every provider's membership spells in a network, or in every network
+// when networkId is empty, ordered by network, provider and start, along with the roster keyed by
+// provider ID. Affiliations that overlap or follow one another without a gap, such as renewed
+// contracts, form one spell, and those ending on or before the day they began are ignored. A
+// network without any affiliation is not found.
+func (s *AnalyticsService) networkMembership(networkId string) ([]membershipSpell, map[string]models.Provider, error) {
+	affiliations, err := s.repo.GetProviderNetworks()
+	if err != nil {
+		return nil, nil, err
+	}
+	var inNetwork []models.ProviderNetwork
+	for _, affiliation := range affiliations {
+		if networkId == "" || affiliation.NetworkID == networkId {
+			inNetwork = append(inNetwork, affiliation)
+		}
+	}
+	if networkId != "" && len(inNetwork) == 0 {
+		return nil, nil, fmt.Errorf("network %s %w", networkId, ErrNotFound)
+	}
+
+	providers, err := s.repo.GetProviders()
+	if err != nil {
+		return nil, nil, err
+	}
+	roster := make(map[string]models.Provider, len(providers))
+	for _, provider := range providers {
+		roster[provider.ProviderID] = provider
+	}
+
+	sort.SliceStable(inNetwork, func(i, j int) bool {
+		if inNetwork[i].NetworkID != inNetwork[j].NetworkID {
+			return inNetwork[i].NetworkID < inNetwork[j].NetworkID
+		}
+		if inNetwork[i].ProviderID != inNetwork[j].ProviderID {
+			return inNetwork[i].ProviderID < inNetwork[j].ProviderID
+		}
+		return inNetwork[i].EffectiveDate.Before(inNetwork[j].EffectiveDate)
+	})
+	var spells []membershipSpell
+	for _, affiliation := range inNetwork {
+		if !affiliation.TerminationDate.After(affiliation.EffectiveDate) {
+			continue
+		}
+		if n := len(spells); n > 0 && spells[n-1].networkID == affiliation.NetworkID && spells[n-1].providerID == affiliation.ProviderID &&
+			!affiliation.EffectiveDate.After(spells[n-1].end) {
+			if affiliation.TerminationDate.After(spells[n-1].end) {
+				spells[n-1].end = affiliation.TerminationDate
+			}
+			continue
+		}
+		spells = append(spells, membershipSpell{
+			providerID: affiliation.ProviderID,
+			networkID:  affiliation.NetworkID,
+			start:      affiliation.EffectiveDate,
+			end:        affiliation.TerminationDate,
+		})
+	}
+	return spells, roster, nil
+}
+
+// membershipGroup returns the network, county or specialty a membership spell is reported under,
+// or "" when memberships are not split. Providers missing from the roster or without the
+// attribute are grouped as "Unknown".
+func membershipGroup(roster map[string]models.Provider, spell membershipSpell, groupBy string) string {
+	var group string
+	switch groupBy {
+	case "":
+		return ""
+	case config.GroupByNetwork:
+		group = spell.networkID
+	case config.GroupByCounty:
+		group = roster[spell.providerID].County
+	case config.GroupBySpecialty:
+		group = roster[spell.providerID].ProviderType
+	}
+	if group == "" {
+		return "Unknown"
+	}
+	return group
+}
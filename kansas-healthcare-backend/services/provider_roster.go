@@ -0,0 +1,263 @@
+package services
+
+import (
+	"fmt"
+	"kansas-healthcare-api/config"
+	"kansas-healthcare-api/data"
+	"kansas-healthcare-api/models"
+	"regexp"
+	"time"
+)
+
+// Roster write errors, re-exported so controllers can map them to status codes without depending
+// on the data layer
+var (
+	ErrNotFound        = data.ErrNotFound
+	ErrAlreadyExists   = data.ErrAlreadyExists
+	ErrVersionMismatch = data.ErrVersionMismatch
+	ErrInUse           = data.ErrInUse
+)
+
+// ValidationError reports a roster record that was rejected before reaching the repository
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s %s", e.Field, e.Message)
+}
+
+// openEndedDate is the termination date of affiliations and locations that are still active
+var openEndedDate = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+
+var (
+	recordIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+	npiPattern      = regexp.MustCompile(`^[0-9]{10}$`)
+)
+
+var providerStatuses = map[string]bool{"Active": true, "Terminated": true}
+
+// matchKey fills an empty key field from the URL and rejects a body that names a different record
+func matchKey(field string, value *string, key string) error {
+	if *value == "" {
+		*value = key
+	}
+	if *value != key {
+		return &ValidationError{Field: field, Message: fmt.Sprintf("must match the URL (%s)", key)}
+	}
+	return nil
+}
+
+func validateProvider(p models.Provider) error {
+	switch {
+	case !recordIDPattern.MatchString(p.ProviderID):
+		return &ValidationError{Field: "provider_id", Message: "is required and may only contain letters, digits, '-' and '_'"}
+	case !npiPattern.MatchString(p.NPI):
+		return &ValidationError{Field: "npi", Message: "must be 10 digits"}
+	case p.ProviderType == "":
+		return &ValidationError{Field: "provider_type", Message: "is required"}
+	case !providerStatuses[p.Status]:
+		return &ValidationError{Field: "status", Message: "must be Active or Terminated"}
+	case p.County == "":
+		return &ValidationError{Field: "county", Message: "is required"}
+	}
+	return nil
+}
+
+// normalizeDates defaults an open-ended termination date and checks the range is ordered
+func normalizeDates(effective, termination *time.Time) error {
+	if effective.IsZero() {
+		return &ValidationError{Field: "effective_date", Message: "is required"}
+	}
+	if termination.IsZero() {
+		*termination = openEndedDate
+	}
+	*effective = effective.UTC()
+	*termination = termination.UTC()
+	if termination.Before(*effective) {
+		return &ValidationError{Field: "termination_date", Message: "must not be before effective_date"}
+	}
+	return nil
+}
+
+func validateProviderNetwork(n *models.ProviderNetwork) error {
+	if !recordIDPattern.MatchString(n.NetworkID) {
+		return &ValidationError{Field: "network_id", Message: "is required and may only contain letters, digits, '-' and '_'"}
+	}
+	return normalizeDates(&n.EffectiveDate, &n.TerminationDate)
+}
+
+var coordinateSources = map[string]bool{"": true, config.CoordinateSourceZip: true, config.CoordinateSourceCity: true}
+
+// validateServiceLocation checks a location's city and county against its ZIP code and fills in
+// coordinates from the geocoder. Coordinates marked as approximate are recomputed, so they follow
+// a changed address; exact coordinates are sent with an empty coordinate_source.
+func validateServiceLocation(l *models.ProviderServiceLocation, geocoder *data.Geocoder) error {
+	if l.County == "" {
+		return &ValidationError{Field: "county", Message: "is required"}
+	}
+	if l.ZipCode != "" && !data.ValidZipCode(l.ZipCode) {
+		return &ValidationError{Field: "zip_code", Message: "must be 5 digits"}
+	}
+	if mismatch := geocoder.CheckAddress(*l); mismatch != nil {
+		return &ValidationError{Field: mismatch.Field, Message: fmt.Sprintf("%s does not match ZIP code %s, which is in %s",
+			mismatch.Actual, mismatch.ZipCode, mismatch.Expected)}
+	}
+	if !coordinateSources[l.CoordinateSource] {
+		return &ValidationError{Field: "coordinate_source", Message: fmt.Sprintf("must be %s, %s or empty",
+			config.CoordinateSourceZip, config.CoordinateSourceCity)}
+	}
+	if l.CoordinateSource != "" {
+		l.Latitude, l.Longitude, l.CoordinateSource = 0, 0, ""
+	}
+	if l.Latitude == 0 && l.Longitude == 0 && !geocoder.Geocode(l) {
+		return &ValidationError{Field: "latitude", Message: "and longitude are required when the ZIP code and city cannot be geocoded"}
+	}
+	if l.Latitude < config.KansasMinLatitude || l.Latitude > config.KansasMaxLatitude ||
+		l.Longitude < config.KansasMinLongitude || l.Longitude > config.KansasMaxLongitude {
+		return &ValidationError{Field: "latitude", Message: "and longitude must fall within Kansas"}
+	}
+	return normalizeDates(&l.EffectiveDate, &l.TerminationDate)
+}
+
+func (s *ProviderService) GetProvider(providerId string) (*models.Provider, string, error) {
+	provider, err := s.repo.GetProvider(providerId)
+	if err != nil {
+		return nil, "", err
+	}
+	return provider, data.RecordVersion(provider), nil
+}
+
+func (s *ProviderService) CreateProvider(provider models.Provider) (*models.Provider, string, error) {
+	if err := validateProvider(provider); err != nil {
+		return nil, "", err
+	}
+	if err := s.repo.CreateProvider(provider); err != nil {
+		return nil, "", err
+	}
+	return &provider, data.RecordVersion(provider), nil
+}
+
+func (s *ProviderService) UpdateProvider(providerId string, provider models.Provider, version string) (*models.Provider, string, error) {
+	if err := matchKey("provider_id", &provider.ProviderID, providerId); err != nil {
+		return nil, "", err
+	}
+	if err := validateProvider(provider); err != nil {
+		return nil, "", err
+	}
+	if err := s.repo.UpdateProvider(provider, version); err != nil {
+		return nil, "", err
+	}
+	return &provider, data.RecordVersion(provider), nil
+}
+
+func (s *ProviderService) DeleteProvider(providerId, version string) error {
+	return s.repo.DeleteProvider(providerId, version)
+}
+
+func (s *ProviderService) GetProviderNetwork(providerId, networkId string, effectiveDate time.Time) (*models.ProviderNetwork, string, error) {
+	network, err := s.repo.GetProviderNetwork(providerId, networkId, effectiveDate)
+	if err != nil {
+		return nil, "", err
+	}
+	return network, data.RecordVersion(network), nil
+}
+
+func (s *ProviderService) CreateProviderNetwork(providerId string, network models.ProviderNetwork) (*models.ProviderNetwork, string, error) {
+	if err := matchKey("provider_id", &network.ProviderID, providerId); err != nil {
+		return nil, "", err
+	}
+	if err := validateProviderNetwork(&network); err != nil {
+		return nil, "", err
+	}
+	if err := s.repo.CreateProviderNetwork(network); err != nil {
+		return nil, "", err
+	}
+	return &network, data.RecordVersion(network), nil
+}
+
+// UpdateProviderNetwork replaces the affiliation starting on effectiveDate. The effective date in
+// network may differ, to correct when the affiliation began.
+func (s *ProviderService) UpdateProviderNetwork(providerId, networkId string, effectiveDate time.Time, network models.ProviderNetwork, version string) (*models.ProviderNetwork, string, error) {
+	if err := matchKey("provider_id", &network.ProviderID, providerId); err != nil {
+		return nil, "", err
+	}
+	if err := matchKey("network_id", &network.NetworkID, networkId); err != nil {
+		return nil, "", err
+	}
+	if err := validateProviderNetwork(&network); err != nil {
+		return nil, "", err
+	}
+	if err := s.repo.UpdateProviderNetwork(effectiveDate, network, version); err != nil {
+		return nil, "", err
+	}
+	return &network, data.RecordVersion(network), nil
+}
+
+func (s *ProviderService) DeleteProviderNetwork(providerId, networkId string, effectiveDate time.Time, version string) error {
+	return s.repo.DeleteProviderNetwork(providerId, networkId, effectiveDate, version)
+}
+
+func (s *ProviderService) validateServiceLocation(location *models.ProviderServiceLocation) error {
+	geocoder, err := s.repo.GetGeocoder()
+	if err != nil {
+		return err
+	}
+	return validateServiceLocation(location, geocoder)
+}
+
+func (s *ProviderService) GetServiceLocation(locationId string) (*models.ProviderServiceLocation, string, error) {
+	location, err := s.repo.GetServiceLocation(locationId)
+	if err != nil {
+		return nil, "", err
+	}
+	return location, data.RecordVersion(location), nil
+}
+
+func (s *ProviderService) CreateServiceLocation(providerId string, location models.ProviderServiceLocation) (*models.ProviderServiceLocation, string, error) {
+	if err := matchKey("provider_id", &location.ProviderID, providerId); err != nil {
+		return nil, "", err
+	}
+	if location.LocationID != "" {
+		return nil, "", &ValidationError{Field: "location_id", Message: "is assigned by the server"}
+	}
+	if err := s.validateServiceLocation(&location); err != nil {
+		return nil, "", err
+	}
+	created, err := s.repo.CreateServiceLocation(location)
+	if err != nil {
+		return nil, "", err
+	}
+	return created, data.RecordVersion(created), nil
+}
+
+func (s *ProviderService) UpdateServiceLocation(locationId string, location models.ProviderServiceLocation, version string) (*models.ProviderServiceLocation, string, error) {
+	if err := matchKey("location_id", &location.LocationID, locationId); err != nil {
+		return nil, "", err
+	}
+	if location.ProviderID == "" {
+		return nil, "", &ValidationError{Field: "provider_id", Message: "is required"}
+	}
+	current, err := s.repo.GetServiceLocation(locationId)
+	if err != nil {
+		return nil, "", err
+	}
+	// Coordinates geocoded from the old address and sent back unchanged follow a new ZIP code or
+	// city; coordinates the client changed are taken as given
+	if current.CoordinateSource != "" && (location.ZipCode != current.ZipCode || location.City != current.City) &&
+		location.Latitude == current.Latitude && location.Longitude == current.Longitude {
+		location.CoordinateSource = current.CoordinateSource
+	}
+	if err := s.validateServiceLocation(&location); err != nil {
+		return nil, "", err
+	}
+	if err := s.repo.UpdateServiceLocation(location, version); err != nil {
+		return nil, "", err
+	}
+	return &location, data.RecordVersion(location), nil
+}
+
+func (s *ProviderService) DeleteServiceLocation(locationId, version string) error {
+	return s.repo.DeleteServiceLocation(locationId, version)
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"kansas-healthcare-api/config"
+	"kansas-healthcare-api/data"
+	"kansas-healthcare-api/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateProviderValidation(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewProviderService(mockRepo)
+
+	valid := models.Provider{ProviderID: "P9", NPI: "1000000009", ProviderType: "Cardiology", Status: "Active", County: "Butler"}
+	invalid := []struct {
+		field  string
+		mutate func(p *models.Provider)
+	}{
+		{"provider_id", func(p *models.Provider) { p.ProviderID = "P 9" }},
+		{"npi", func(p *models.Provider) { p.NPI = "123" }},
+		{"provider_type", func(p *models.Provider) { p.ProviderType = "" }},
+		{"status", func(p *models.Provider) { p.Status = "Pending" }},
+		{"county", func(p *models.Provider) { p.County = "" }},
+	}
+	for _, tt := range invalid {
+		provider := valid
+		tt.mutate(&provider)
+		_, _, err := service.CreateProvider(provider)
+		var validationErr *ValidationError
+		if assert.ErrorAs(t, err, &validationErr, tt.field) {
+			assert.Equal(t, tt.field, validationErr.Field)
+		}
+	}
+
+	mockRepo.On("CreateProvider", valid).Return(nil)
+	created, version, err := service.CreateProvider(valid)
+	assert.NoError(t, err)
+	assert.Equal(t, valid, *created)
+	assert.Equal(t, data.RecordVersion(valid), version)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateProviderRejectsMismatchedKey(t *testing.T) {
+	service := NewProviderService(new(MockRepository))
+
+	_, _, err := service.UpdateProvider("P1", models.Provider{ProviderID: "P2"}, "v1")
+	var validationErr *ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "provider_id", validationErr.Field)
+}
+
+func TestCreateProviderNetworkDefaultsOpenEndedTermination(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewProviderService(mockRepo)
+	mockRepo.On("CreateProviderNetwork", mock.Anything).Return(nil)
+
+	created, _, err := service.CreateProviderNetwork("P1", models.ProviderNetwork{
+		NetworkID:     "Medicare",
+		EffectiveDate: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "P1", created.ProviderID)
+	assert.Equal(t, 9999, created.TerminationDate.Year())
+
+	_, _, err = service.CreateProviderNetwork("P1", models.ProviderNetwork{
+		NetworkID:       "Medicare",
+		EffectiveDate:   time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		TerminationDate: time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC),
+	})
+	assert.ErrorContains(t, err, "termination_date")
+}
+
+func TestCreateServiceLocationRequiresKansasCoordinates(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewProviderService(mockRepo)
+	mockRepo.On("GetGeocoder").Return(data.NewGeocoder(nil, nil), nil)
+
+	_, _, err := service.CreateServiceLocation("P1", models.ProviderServiceLocation{
+		County:        "Sedgwick",
+		EffectiveDate: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		Latitude:      39.1, Longitude: -94.4,
+	})
+	var validationErr *ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "latitude", validationErr.Field)
+}
+
+func TestCreateServiceLocationGeocodesFromZipCode(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewProviderService(mockRepo)
+	mockRepo.On("GetGeocoder").Return(data.NewGeocoder([]models.ZipCentroid{
+		{ZipCode: "67202", City: "Wichita", County: "Sedgwick", Latitude: 37.6872, Longitude: -97.3301},
+	}, nil), nil)
+	geocoded := mock.MatchedBy(func(l models.ProviderServiceLocation) bool {
+		return l.Latitude == 37.6872 && l.Longitude == -97.3301 && l.CoordinateSource == config.CoordinateSourceZip
+	})
+	mockRepo.On("CreateServiceLocation", geocoded).Return(&models.ProviderServiceLocation{LocationID: "P1-1"}, nil).Twice()
+	effective := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	_, _, err := service.CreateServiceLocation("P1", models.ProviderServiceLocation{
+		ZipCode: "67202", City: "Wichita", County: "Sedgwick", EffectiveDate: effective,
+	})
+	assert.NoError(t, err)
+
+	// Approximate coordinates are recomputed rather than trusted
+	_, _, err = service.CreateServiceLocation("P1", models.ProviderServiceLocation{
+		ZipCode: "67202", City: "Wichita", County: "Sedgwick", EffectiveDate: effective,
+		Latitude: 38.5, Longitude: -98, CoordinateSource: config.CoordinateSourceCity,
+	})
+	assert.NoError(t, err)
+
+	var validationErr *ValidationError
+	_, _, err = service.CreateServiceLocation("P1", models.ProviderServiceLocation{
+		ZipCode: "67202", City: "Wichita", County: "Butler", EffectiveDate: effective,
+	})
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "county Butler does not match ZIP code 67202, which is in Sedgwick", err.Error())
+
+	_, _, err = service.CreateServiceLocation("P1", models.ProviderServiceLocation{
+		ZipCode: "67042", City: "El Dorado", County: "Butler", EffectiveDate: effective,
+	})
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "latitude", validationErr.Field)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateServiceLocationRegeocodesChangedAddress(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewProviderService(mockRepo)
+	mockRepo.On("GetGeocoder").Return(data.NewGeocoder([]models.ZipCentroid{
+		{ZipCode: "67202", City: "Wichita", County: "Sedgwick", Latitude: 37.6872, Longitude: -97.3301},
+		{ZipCode: "67208", City: "Wichita", County: "Sedgwick", Latitude: 37.7024, Longitude: -97.2795},
+	}, nil), nil)
+	stored := models.ProviderServiceLocation{LocationID: "P1-1", ProviderID: "P1", ZipCode: "67202", City: "Wichita", County: "Sedgwick",
+		EffectiveDate: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), Latitude: 37.6872, Longitude: -97.3301, CoordinateSource: config.CoordinateSourceZip}
+	mockRepo.On("GetServiceLocation", "P1-1").Return(&stored, nil)
+	mockRepo.On("UpdateServiceLocation", mock.Anything, "v1").Return(nil)
+
+	// The client moves the location to another ZIP code and sends the old coordinates back
+	moved := stored
+	moved.ZipCode, moved.CoordinateSource = "67208", ""
+	updated, _, err := service.UpdateServiceLocation("P1-1", moved, "v1")
+	require.NoError(t, err)
+	assert.Equal(t, []float64{37.7024, -97.2795}, []float64{updated.Latitude, updated.Longitude})
+	assert.Equal(t, config.CoordinateSourceZip, updated.CoordinateSource)
+
+	// Surveyed coordinates sent with the new address are kept
+	surveyed := moved
+	surveyed.Latitude, surveyed.Longitude = 37.7, -97.28
+	updated, _, err = service.UpdateServiceLocation("P1-1", surveyed, "v1")
+	require.NoError(t, err)
+	assert.Equal(t, []float64{37.7, -97.28}, []float64{updated.Latitude, updated.Longitude})
+	assert.Empty(t, updated.CoordinateSource)
+
+	// With the address unchanged the stored coordinates stand
+	updated, _, err = service.UpdateServiceLocation("P1-1", models.ProviderServiceLocation{ProviderID: "P1", ZipCode: "67202", City: "Wichita",
+		County: "Sedgwick", EffectiveDate: stored.EffectiveDate, Latitude: stored.Latitude, Longitude: stored.Longitude}, "v1")
+	require.NoError(t, err)
+	assert.Equal(t, []float64{37.6872, -97.3301}, []float64{updated.Latitude, updated.Longitude})
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"kansas-healthcare-api/data"
 	"kansas-healthcare-api/models"
+	"time"
 )
 
 type ProviderService struct {
@@ -21,6 +22,14 @@ func (s *ProviderService) GetProviderNetworks() ([]models.ProviderNetwork, error
 	return s.repo.GetProviderNetworks()
 }
 
-func (s *ProviderService) GetFilteredProviders(filter models.FilterRequest) ([]models.Provider, error) {
-	return s.repo.GetFilteredProviders(filter)
+func (s *ProviderService) GetFilteredProviders(filter models.FilterRequest, asOf time.Time) ([]models.Provider, error) {
+	return s.repo.GetFilteredProviders(filter, asOf)
+}
+
+func (s *ProviderService) GetNearestProviders(query models.NearestProviderQuery) ([]models.NearestProvider, error) {
+	return s.repo.GetNearestProviders(query)
+}
+
+func (s *ProviderService) GetDataQualityReport() (*models.DataQualityReport, error) {
+	return s.repo.GetDataQualityReport()
 }
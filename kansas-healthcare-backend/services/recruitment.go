@@ -0,0 +1,186 @@
+package services
+
+import (
+	"fmt"
+	"kansas-healthcare-api/config"
+	"kansas-healthcare-api/data"
+	"kansas-healthcare-api/models"
+	"math"
+	"sort"
+)
+
+// recruitmentCandidate is a site that could be added to the network and the places it would
+// serve residents from
+type recruitmentCandidate struct {
+	site        models.RecruitmentSite
+	coordinates []models.Coordinate
+	// reach holds the demand points within range that the network does not already cover
+	reach []int
+}
+
+// GetRecruitmentSites solves the maximal covering location problem greedily: starting from the
+// residents already within query.MaxMiles of a network provider of the specialty, it repeatedly
+// adds the candidate that brings the most uncovered residents within reach, up to query.Sites.
+// Greedy selection covers at least 63% (1 - 1/e) of what the best possible set of sites would.
+func (s *AnalyticsService) GetRecruitmentSites(query models.RecruitmentQuery) (*models.RecruitmentPlan, error) {
+	if query.Candidates != config.RecruitmentCandidateProviders && query.Candidates != config.RecruitmentCandidateZips {
+		return nil, &ValidationError{Field: "candidates", Message: fmt.Sprintf("must be %s or %s", config.RecruitmentCandidateProviders, config.RecruitmentCandidateZips)}
+	}
+	demand, err := s.populationDemand()
+	if err != nil {
+		return nil, err
+	}
+	distances, err := s.repo.GetNearestProviderDistances(demand.points, query.Specialty, query.Network, query.AsOf, false)
+	if err != nil {
+		return nil, err
+	}
+	var candidates []*recruitmentCandidate
+	if query.Candidates == config.RecruitmentCandidateProviders {
+		candidates, err = s.providerCandidates(query)
+	} else {
+		candidates, err = s.zipCandidates()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	covered := make([]bool, len(demand.points))
+	var coveredPopulation float64
+	for i, distance := range distances {
+		if distance <= query.MaxMiles {
+			covered[i] = true
+			coveredPopulation += demand.shares[i].population
+		}
+	}
+	for _, candidate := range candidates {
+		for i, point := range demand.points {
+			if covered[i] {
+				continue
+			}
+			for _, coordinate := range candidate.coordinates {
+				if data.DistanceMiles(point, coordinate) <= query.MaxMiles {
+					candidate.reach = append(candidate.reach, i)
+					break
+				}
+			}
+		}
+	}
+
+	plan := &models.RecruitmentPlan{
+		AsOf:                query.AsOf.Format(config.AsOfDateFormat),
+		Specialty:           query.Specialty,
+		Network:             query.Network,
+		MaxMiles:            query.MaxMiles,
+		Candidates:          query.Candidates,
+		CandidatesEvaluated: len(candidates),
+		TotalPopulation:     demand.total,
+		CoveredPopulation:   int(math.Round(coveredPopulation)),
+		CoveredPercent:      populationPercent(coveredPopulation, demand.total),
+		Sites:               []models.RecruitmentSite{},
+		UnevaluatedCounties: demand.unevaluated,
+	}
+
+	for len(plan.Sites) < query.Sites {
+		// Candidates are in ID order, so ties go to the lowest ID
+		var best *recruitmentCandidate
+		var bestGain float64
+		for _, candidate := range candidates {
+			var gain float64
+			for _, i := range candidate.reach {
+				if !covered[i] {
+					gain += demand.shares[i].population
+				}
+			}
+			if gain > bestGain {
+				best, bestGain = candidate, gain
+			}
+		}
+		if best == nil {
+			break
+		}
+		for _, i := range best.reach {
+			covered[i] = true
+		}
+		coveredPopulation += bestGain
+
+		site := best.site
+		site.Rank = len(plan.Sites) + 1
+		site.MarginalPopulation = int(math.Round(bestGain))
+		site.CoveredPopulation = int(math.Round(coveredPopulation))
+		site.CoveredPercent = populationPercent(coveredPopulation, demand.total)
+		plan.Sites = append(plan.Sites, site)
+		best.reach = nil
+	}
+	return plan, nil
+}
+
+// providerCandidates returns the geocoded providers of the specialty who are active but not in
+// the network, each located at its in-force service locations
+func (s *AnalyticsService) providerCandidates(query models.RecruitmentQuery) ([]*recruitmentCandidate, error) {
+	members, err := s.repo.GetNetworkProviderLocations(query.Network, query.Specialty, query.AsOf)
+	if err != nil {
+		return nil, err
+	}
+	inNetwork := make(map[string]bool, len(members))
+	for _, location := range members {
+		inNetwork[location.ProviderID] = true
+	}
+	locations, err := s.repo.GetNetworkProviderLocations("", query.Specialty, query.AsOf)
+	if err != nil {
+		return nil, err
+	}
+
+	byProvider := make(map[string]*recruitmentCandidate)
+	var candidates []*recruitmentCandidate
+	for _, location := range locations {
+		if inNetwork[location.ProviderID] || (location.Latitude == 0 && location.Longitude == 0) {
+			continue
+		}
+		candidate, ok := byProvider[location.ProviderID]
+		if !ok {
+			candidate = &recruitmentCandidate{site: models.RecruitmentSite{
+				Kind:      "provider",
+				ID:        location.ProviderID,
+				Specialty: location.Specialty,
+				City:      location.City,
+				County:    location.County,
+				Latitude:  location.Latitude,
+				Longitude: location.Longitude,
+			}}
+			byProvider[location.ProviderID] = candidate
+			candidates = append(candidates, candidate)
+		}
+		candidate.site.LocationIDs = append(candidate.site.LocationIDs, location.LocationID)
+		candidate.coordinates = append(candidate.coordinates, models.Coordinate{Latitude: location.Latitude, Longitude: location.Longitude})
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].site.ID < candidates[j].site.ID })
+	return candidates, nil
+}
+
+// zipCandidates returns a site at the centroid of each ZIP code in the geocoding table
+func (s *AnalyticsService) zipCandidates() ([]*recruitmentCandidate, error) {
+	zips, err := s.repo.GetZipCentroids()
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]*recruitmentCandidate, len(zips))
+	for i, zip := range zips {
+		candidates[i] = &recruitmentCandidate{
+			site: models.RecruitmentSite{
+				Kind: "zip", ID: zip.ZipCode, City: zip.City, County: zip.County, Latitude: zip.Latitude, Longitude: zip.Longitude,
+			},
+			coordinates: []models.Coordinate{{Latitude: zip.Latitude, Longitude: zip.Longitude}},
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].site.ID < candidates[j].site.ID })
+	return candidates, nil
+}
+
+// populationPercent returns the share of the total population, rounded to hundredths of a percent
+func populationPercent(population float64, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(population/float64(total)*10000) / 100
+}
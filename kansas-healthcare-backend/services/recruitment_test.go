@@ -0,0 +1,106 @@
+package services
+
+import (
+	"errors"
+	"kansas-healthcare-api/config"
+	"kansas-healthcare-api/models"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockRecruitmentDemand places one demand point in each of four counties: Wichita, already served
+// by the network, and El Dorado, Garden City and Dodge City, which are not
+func mockRecruitmentDemand(mockRepo *MockRepository) []models.Coordinate {
+	points := []models.Coordinate{
+		{Latitude: 37.6872, Longitude: -97.3301},
+		{Latitude: 37.8172, Longitude: -96.8622},
+		{Latitude: 37.9717, Longitude: -100.8727},
+		{Latitude: 37.7528, Longitude: -100.0171},
+	}
+	mockRepo.On("GetCountyPopulation").Return([]models.CountyPopulation{
+		{County: "Sedgwick", Population: 1000},
+		{County: "Butler", Population: 500},
+		{County: "Finney", Population: 400},
+		{County: "Ford", Population: 300},
+	}, nil)
+	for i, county := range []string{"Sedgwick", "Butler", "Finney", "Ford"} {
+		mockRepo.On("GetCountySamplePoints", county, config.PopulationDemandGridSize).Return(points[i:i+1], nil)
+	}
+	mockRepo.On("GetNearestProviderDistances", points, "Cardiology", "Commercial", testAsOf, false).Return([]float64{5, 45, math.Inf(1), math.Inf(1)}, nil)
+	return points
+}
+
+func TestGetRecruitmentSitesFromProviders(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewAnalyticsService(mockRepo)
+	mockRecruitmentDemand(mockRepo)
+
+	mockRepo.On("GetNetworkProviderLocations", "Commercial", "Cardiology", testAsOf).Return([]models.ProviderLocation{
+		{ProviderID: "P1", LocationID: "P1-1", Latitude: 37.6872, Longitude: -97.3301},
+	}, nil)
+	mockRepo.On("GetNetworkProviderLocations", "", "Cardiology", testAsOf).Return([]models.ProviderLocation{
+		{ProviderID: "P1", LocationID: "P1-1", Latitude: 37.6872, Longitude: -97.3301},
+		// P2 practises in both El Dorado and Garden City, so recruiting them covers both
+		{ProviderID: "P2", Specialty: "Cardiology", LocationID: "P2-1", City: "El Dorado", County: "Butler", Latitude: 37.8172, Longitude: -96.8622},
+		{ProviderID: "P2", Specialty: "Cardiology", LocationID: "P2-2", City: "Garden City", County: "Finney", Latitude: 37.9717, Longitude: -100.8727},
+		{ProviderID: "P3", Specialty: "Cardiology", LocationID: "P3-1", City: "Dodge City", County: "Ford", Latitude: 37.7528, Longitude: -100.0171},
+		// Hutchinson only reaches Wichita, which is already covered
+		{ProviderID: "P4", Specialty: "Cardiology", LocationID: "P4-1", City: "Hutchinson", County: "Reno", Latitude: 38.0608, Longitude: -97.9298},
+		// Never geocoded, so it cannot be placed
+		{ProviderID: "P5", Specialty: "Cardiology", LocationID: "P5-1", City: "Derby", County: "Sedgwick"},
+	}, nil)
+
+	plan, err := service.GetRecruitmentSites(models.RecruitmentQuery{
+		Specialty: "Cardiology", Network: "Commercial", MaxMiles: 30, Sites: 3, Candidates: config.RecruitmentCandidateProviders, AsOf: testAsOf,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, plan.CandidatesEvaluated)
+	assert.Equal(t, 2200, plan.TotalPopulation)
+	assert.Equal(t, 1000, plan.CoveredPopulation)
+	assert.Equal(t, 45.45, plan.CoveredPercent)
+
+	// Nothing is left to cover after two sites, so the third is not spent
+	require.Len(t, plan.Sites, 2)
+	assert.Equal(t, models.RecruitmentSite{
+		Rank: 1, Kind: "provider", ID: "P2", Specialty: "Cardiology", LocationIDs: []string{"P2-1", "P2-2"},
+		City: "El Dorado", County: "Butler", Latitude: 37.8172, Longitude: -96.8622,
+		MarginalPopulation: 900, CoveredPopulation: 1900, CoveredPercent: 86.36,
+	}, plan.Sites[0])
+	assert.Equal(t, "P3", plan.Sites[1].ID)
+	assert.Equal(t, 300, plan.Sites[1].MarginalPopulation)
+	assert.Equal(t, 100.0, plan.Sites[1].CoveredPercent)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetRecruitmentSitesFromZipCentroids(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewAnalyticsService(mockRepo)
+	mockRecruitmentDemand(mockRepo)
+
+	mockRepo.On("GetZipCentroids").Return([]models.ZipCentroid{
+		{ZipCode: "67846", City: "Garden City", County: "Finney", Latitude: 37.9717, Longitude: -100.8727},
+		{ZipCode: "67801", City: "Dodge City", County: "Ford", Latitude: 37.7528, Longitude: -100.0171},
+		{ZipCode: "67042", City: "El Dorado", County: "Butler", Latitude: 37.8172, Longitude: -96.8622},
+	}, nil)
+
+	plan, err := service.GetRecruitmentSites(models.RecruitmentQuery{
+		Specialty: "Cardiology", Network: "Commercial", MaxMiles: 30, Sites: 1, Candidates: config.RecruitmentCandidateZips, AsOf: testAsOf,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, plan.CandidatesEvaluated)
+	require.Len(t, plan.Sites, 1)
+	site := plan.Sites[0]
+	assert.Equal(t, "zip", site.Kind)
+	assert.Equal(t, "67042", site.ID)
+	assert.Equal(t, "El Dorado", site.City)
+	assert.Equal(t, 37.8172, site.Latitude)
+	assert.Equal(t, 500, site.MarginalPopulation)
+
+	_, err = service.GetRecruitmentSites(models.RecruitmentQuery{Network: "Commercial", Candidates: "hospitals"})
+	var validationErr *ValidationError
+	assert.True(t, errors.As(err, &validationErr))
+}
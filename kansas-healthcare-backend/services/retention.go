@@ -0,0 +1,186 @@
+package services
+
+import (
+	"fmt"
+	"kansas-healthcare-api/config"
+	"kansas-healthcare-api/models"
+	"math"
+	"sort"
+)
+
+// daysPerYear converts tenures in days to years
+const daysPerYear = 365.25
+
+// retentionMilestoneYears are the tenures at which each curve's retention is summarized
+var retentionMilestoneYears = []int{1, 2, 3, 5}
+
+// tenure is how many days a membership lasted, and whether it ended or was still running when
+// last observed (censored)
+type tenure struct {
+	days  int
+	ended bool
+}
+
+// survivalStep is the Kaplan-Meier estimate just after a day on which memberships ended or were
+// censored
+type survivalStep struct {
+	days, atRisk, ended, censored int
+	survival, lower, upper        float64
+}
+
+// kaplanMeier estimates the survival curve of the tenures, with a pointwise confidence interval
+// from Greenwood's variance on the log(-log) scale, which keeps the limits between 0 and 1. z is
+// the standard normal quantile of the confidence level. Memberships censored on the day others
+// ended are counted at risk of ending that day.
+func kaplanMeier(tenures []tenure, z float64) []survivalStep {
+	sorted := append([]tenure(nil), tenures...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].days < sorted[j].days })
+
+	var steps []survivalStep
+	survival, greenwood := 1.0, 0.0
+	for i := 0; i < len(sorted); {
+		step := survivalStep{days: sorted[i].days, atRisk: len(sorted) - i}
+		for ; i < len(sorted) && sorted[i].days == step.days; i++ {
+			if sorted[i].ended {
+				step.ended++
+			} else {
+				step.censored++
+			}
+		}
+		if step.ended > 0 {
+			survival *= float64(step.atRisk-step.ended) / float64(step.atRisk)
+			if step.ended < step.atRisk {
+				greenwood += float64(step.ended) / float64(step.atRisk*(step.atRisk-step.ended))
+			}
+		}
+		step.survival = survival
+		step.lower, step.upper = logLogLimits(survival, greenwood, z)
+		steps = append(steps, step)
+	}
+	return steps
+}
+
+// logLogLimits returns the confidence limits of a survival estimate with the given Greenwood sum
+func logLogLimits(survival, greenwood, z float64) (float64, float64) {
+	if survival >= 1 || survival <= 0 {
+		return survival, survival
+	}
+	se := math.Sqrt(greenwood) / math.Abs(math.Log(survival))
+	return math.Pow(survival, math.Exp(z*se)), math.Pow(survival, math.Exp(-z*se))
+}
+
+// firstTenureAtOrBelowHalf returns the tenure in years of the first step whose value is at most
+// one half, or nil when none is
+func firstTenureAtOrBelowHalf(steps []survivalStep, value func(survivalStep) float64) *float64 {
+	for _, step := range steps {
+		if value(step) <= 0.5 {
+			return tenureYears(step.days)
+		}
+	}
+	return nil
+}
+
+// tenureYears converts days to years rounded to thousandths, which keeps consecutive days apart
+func tenureYears(days int) *float64 {
+	years := math.Round(float64(days)/daysPerYear*1000) / 1000
+	return &years
+}
+
+// roundedRetention rounds a retention share to four decimal places
+func roundedRetention(share float64) float64 {
+	return math.Round(share*10000) / 10000
+}
+
+// GetRetention estimates how long providers stay in a network with Kaplan-Meier retention curves
+// per network, county or specialty. Each membership spell is one subject, measured from the day the
+// provider joined to the day it left; a renewal without a gap continues the same spell. Spells
+// still running at query.AsOf, including open-ended affiliations, are censored there, and spells
+// starting after it are left out. Every termination counts as leaving, whatever its reason.
+//
+// Unlike the terminated analysis, which counts the providers who left within a window, the curves
+// account for how long every member had been observed, so networks that grew recently are not
+// mistaken for networks that keep their providers.
+func (s *AnalyticsService) GetRetention(query models.RetentionQuery) (*models.RetentionReport, error) {
+	switch query.StratifyBy {
+	case config.GroupByNetwork, config.GroupByCounty, config.GroupBySpecialty:
+	default:
+		return nil, &ValidationError{Field: "stratify_by", Message: fmt.Sprintf("must be %s, %s or %s", config.GroupByNetwork, config.GroupByCounty, config.GroupBySpecialty)}
+	}
+	spells, roster, err := s.networkMembership(query.Network)
+	if err != nil {
+		return nil, err
+	}
+
+	strata := make(map[string][]tenure)
+	for _, spell := range spells {
+		if spell.start.After(query.AsOf) {
+			continue
+		}
+		end, ended := spell.end, true
+		if end.After(query.AsOf) {
+			end, ended = query.AsOf, false
+		}
+		group := membershipGroup(roster, spell, query.StratifyBy)
+		days := int(math.Round(end.Sub(spell.start).Hours() / 24))
+		strata[group] = append(strata[group], tenure{days: days, ended: ended})
+	}
+
+	report := &models.RetentionReport{
+		AsOf:            query.AsOf.Format(config.AsOfDateFormat),
+		Network:         query.Network,
+		StratifyBy:      query.StratifyBy,
+		ConfidenceLevel: config.RetentionConfidenceLevel,
+		Strata:          []models.RetentionStratum{},
+	}
+	names := make([]string, 0, len(strata))
+	for name := range strata {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		steps := kaplanMeier(strata[name], config.RetentionConfidenceZ)
+		stratum := models.RetentionStratum{
+			Group:             name,
+			Memberships:       len(strata[name]),
+			MedianTenureYears: firstTenureAtOrBelowHalf(steps, func(step survivalStep) float64 { return step.survival }),
+			MedianLowerYears:  firstTenureAtOrBelowHalf(steps, func(step survivalStep) float64 { return step.lower }),
+			MedianUpperYears:  firstTenureAtOrBelowHalf(steps, func(step survivalStep) float64 { return step.upper }),
+			Milestones:        []models.RetentionMilestone{},
+			Curve:             make([]models.RetentionPoint, len(steps)),
+		}
+		for i, step := range steps {
+			stratum.Terminations += step.ended
+			stratum.Censored += step.censored
+			stratum.Curve[i] = models.RetentionPoint{
+				TenureYears:  *tenureYears(step.days),
+				AtRisk:       step.atRisk,
+				Terminations: step.ended,
+				Censored:     step.censored,
+				Retention:    roundedRetention(step.survival),
+				Lower:        roundedRetention(step.lower),
+				Upper:        roundedRetention(step.upper),
+			}
+		}
+
+		// The estimate at a milestone is that of the last step on or before it
+		longest := steps[len(steps)-1].days
+		for _, years := range retentionMilestoneYears {
+			days := float64(years) * daysPerYear
+			if days > float64(longest) {
+				break
+			}
+			milestone := models.RetentionMilestone{Years: years, Retention: 1, Lower: 1, Upper: 1}
+			for _, step := range steps {
+				if float64(step.days) > days {
+					break
+				}
+				milestone.Retention = roundedRetention(step.survival)
+				milestone.Lower = roundedRetention(step.lower)
+				milestone.Upper = roundedRetention(step.upper)
+			}
+			stratum.Milestones = append(stratum.Milestones, milestone)
+		}
+		report.Strata = append(report.Strata, stratum)
+	}
+	return report, nil
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"errors"
+	"kansas-healthcare-api/config"
+	"kansas-healthcare-api/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKaplanMeier(t *testing.T) {
+	// The 6-MP arm of Gehan's leukaemia remission trial, in weeks, a textbook Kaplan-Meier example
+	var tenures []tenure
+	for _, weeks := range []int{6, 6, 6, 7, 10, 13, 16, 22, 23} {
+		tenures = append(tenures, tenure{days: weeks, ended: true})
+	}
+	for _, weeks := range []int{6, 9, 10, 11, 17, 19, 20, 25, 32, 32, 34, 35} {
+		tenures = append(tenures, tenure{days: weeks})
+	}
+
+	steps := kaplanMeier(tenures, config.RetentionConfidenceZ)
+	require.Len(t, steps, 16)
+	assert.Equal(t, survivalStep{days: 6, atRisk: 21, ended: 3, censored: 1}, survivalStep{days: steps[0].days, atRisk: steps[0].atRisk, ended: steps[0].ended, censored: steps[0].censored})
+	for _, expected := range []struct {
+		step                   int
+		survival, lower, upper float64
+	}{
+		{0, 0.857, 0.620, 0.952},
+		{1, 0.807, 0.563, 0.923},
+		{3, 0.753, 0.503, 0.889},
+		{5, 0.690, 0.432, 0.849},
+		{6, 0.627, 0.368, 0.805},
+		{10, 0.538, 0.268, 0.747},
+		{11, 0.448, 0.188, 0.680},
+	} {
+		assert.InDelta(t, expected.survival, steps[expected.step].survival, 0.001, "week %d", steps[expected.step].days)
+		assert.InDelta(t, expected.lower, steps[expected.step].lower, 0.001, "week %d", steps[expected.step].days)
+		assert.InDelta(t, expected.upper, steps[expected.step].upper, 0.001, "week %d", steps[expected.step].days)
+	}
+	// Censoring leaves the estimate where it was
+	assert.Equal(t, steps[11].survival, steps[15].survival)
+
+	// With everyone gone the limits collapse onto the estimate
+	steps = kaplanMeier([]tenure{{days: 3, ended: true}, {days: 5, ended: true}}, config.RetentionConfidenceZ)
+	assert.Equal(t, []float64{0.5, 0}, []float64{steps[0].survival, steps[1].survival})
+	assert.Zero(t, steps[1].lower)
+	assert.Zero(t, steps[1].upper)
+}
+
+func TestGetRetention(t *testing.T) {
+	mockRepo := newMembershipRepository()
+	service := NewAnalyticsService(mockRepo)
+
+	report, err := service.GetRetention(models.RetentionQuery{StratifyBy: config.GroupByNetwork, AsOf: testAsOf})
+	require.NoError(t, err)
+	assert.Equal(t, "2024-06-30", report.AsOf)
+	assert.Equal(t, 0.95, report.ConfidenceLevel)
+	require.Len(t, report.Strata, 2)
+
+	// P4 left after 10 days and P2 after four years and a month; P1 and P3, renewed without a
+	// gap, are still members
+	commercial := report.Strata[0]
+	assert.Equal(t, "Commercial", commercial.Group)
+	assert.Equal(t, 4, commercial.Memberships)
+	assert.Equal(t, 2, commercial.Terminations)
+	assert.Equal(t, 2, commercial.Censored)
+	require.Len(t, commercial.Curve, 4)
+	assert.Equal(t, models.RetentionPoint{TenureYears: 0.027, AtRisk: 4, Terminations: 1, Retention: 0.75, Lower: 0.1279, Upper: 0.9605}, commercial.Curve[0])
+	assert.Equal(t, 1, commercial.Curve[1].Censored)
+	assert.Equal(t, 2.494, commercial.Curve[2].TenureYears)
+	assert.Equal(t, models.RetentionPoint{TenureYears: 4.085, AtRisk: 1, Terminations: 1}, commercial.Curve[3])
+	require.NotNil(t, commercial.MedianTenureYears)
+	assert.Equal(t, 4.085, *commercial.MedianTenureYears)
+	assert.Equal(t, 0.027, *commercial.MedianLowerYears)
+	assert.Equal(t, 4.085, *commercial.MedianUpperYears)
+	assert.Equal(t, []models.RetentionMilestone{
+		{Years: 1, Retention: 0.75, Lower: 0.1279, Upper: 0.9605},
+		{Years: 2, Retention: 0.75, Lower: 0.1279, Upper: 0.9605},
+		{Years: 3, Retention: 0.75, Lower: 0.1279, Upper: 0.9605},
+	}, commercial.Milestones)
+
+	// P5 joined Medicare at the start of the year and is still a member
+	medicare := report.Strata[1]
+	assert.Equal(t, "Medicare", medicare.Group)
+	assert.Equal(t, []models.RetentionPoint{{TenureYears: 0.496, AtRisk: 1, Censored: 1, Retention: 1, Lower: 1, Upper: 1}}, medicare.Curve)
+	assert.Nil(t, medicare.MedianTenureYears)
+	assert.Empty(t, medicare.Milestones)
+
+	report, err = service.GetRetention(models.RetentionQuery{Network: "Commercial", StratifyBy: config.GroupBySpecialty, AsOf: testAsOf})
+	require.NoError(t, err)
+	var groups []string
+	for _, stratum := range report.Strata {
+		groups = append(groups, stratum.Group)
+	}
+	assert.Equal(t, []string{"Cardiology", "Primary Care", "Unknown"}, groups)
+	assert.Zero(t, report.Strata[0].Terminations)
+
+	// Before P4 joined and P2 left, every Commercial membership was still running
+	report, err = service.GetRetention(models.RetentionQuery{Network: "Commercial", StratifyBy: config.GroupByNetwork, AsOf: date(2023, 12, 31)})
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.Strata[0].Memberships)
+	assert.Zero(t, report.Strata[0].Terminations)
+}
+
+func TestGetRetentionInvalidQueries(t *testing.T) {
+	service := NewAnalyticsService(newMembershipRepository())
+
+	_, err := service.GetRetention(models.RetentionQuery{StratifyBy: "zip", AsOf: testAsOf})
+	var validationErr *ValidationError
+	require.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, "stratify_by", validationErr.Field)
+
+	_, err = service.GetRetention(models.RetentionQuery{Network: "Medicaid", StratifyBy: config.GroupByNetwork, AsOf: testAsOf})
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
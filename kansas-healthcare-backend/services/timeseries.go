@@ -0,0 +1,135 @@
+package services
+
+import (
+	"fmt"
+	"kansas-healthcare-api/config"
+	"kansas-healthcare-api/models"
+	"sort"
+	"time"
+)
+
+// periodStart returns the first day of the month, quarter or year containing date
+func periodStart(date time.Time, interval string) time.Time {
+	year, month, _ := date.Date()
+	switch interval {
+	case config.IntervalQuarter:
+		month = (month-1)/3*3 + 1
+	case config.IntervalYear:
+		month = time.January
+	}
+	return time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// nextPeriod returns the first day of the period after the one starting at start
+func nextPeriod(start time.Time, interval string) time.Time {
+	switch interval {
+	case config.IntervalQuarter:
+		return start.AddDate(0, 3, 0)
+	case config.IntervalYear:
+		return start.AddDate(1, 0, 0)
+	}
+	return start.AddDate(0, 1, 0)
+}
+
+// GetNetworkTimeSeries charts a network's membership period by period from its affiliation
+// history: the providers in force at the end of each period and those joining and leaving during
+// it. The last period ends at query.To rather than running into the future. Renewals that follow
+// on without a gap are not counted as a departure and a return.
+//
+// Split by county or specialty, a provider's membership counts toward its current roster county
+// or specialty throughout, and groups without any membership over the range are left out.
+func (s *AnalyticsService) GetNetworkTimeSeries(query models.TimeSeriesQuery) (*models.TimeSeriesReport, error) {
+	switch query.Interval {
+	case config.IntervalMonth, config.IntervalQuarter, config.IntervalYear:
+	default:
+		return nil, &ValidationError{Field: "interval", Message: fmt.Sprintf("must be %s, %s or %s", config.IntervalMonth, config.IntervalQuarter, config.IntervalYear)}
+	}
+	switch query.GroupBy {
+	case "", config.GroupByCounty, config.GroupBySpecialty:
+	default:
+		return nil, &ValidationError{Field: "group_by", Message: fmt.Sprintf("must be %s or %s", config.GroupByCounty, config.GroupBySpecialty)}
+	}
+	if query.From.After(query.To) {
+		return nil, &ValidationError{Field: "from", Message: "must not be after to"}
+	}
+
+	// Each period runs from its start to its last day, inclusive
+	var starts, ends []time.Time
+	for start := periodStart(query.From, query.Interval); !start.After(query.To); start = nextPeriod(start, query.Interval) {
+		if len(starts) == config.MaxTimeSeriesPeriods {
+			return nil, &ValidationError{Field: "from", Message: fmt.Sprintf("covers more than %d periods; use a longer interval or a shorter range", config.MaxTimeSeriesPeriods)}
+		}
+		end := nextPeriod(start, query.Interval).AddDate(0, 0, -1)
+		if end.After(query.To) {
+			end = query.To
+		}
+		starts = append(starts, start)
+		ends = append(ends, end)
+	}
+
+	spells, roster, err := s.networkMembership(query.Network)
+	if err != nil {
+		return nil, err
+	}
+
+	// A spell is in force at the end of every period from the one it began in up to, but not
+	// including, the one it ended in. Active counts are accumulated as differences between periods.
+	type counts struct{ activeDelta, joins, terminations []int }
+	groups := make(map[string]*counts)
+	for _, spell := range spells {
+		group := membershipGroup(roster, spell, query.GroupBy)
+		c := groups[group]
+		if c == nil {
+			c = &counts{make([]int, len(starts)+1), make([]int, len(starts)), make([]int, len(starts))}
+			groups[group] = c
+		}
+		first := sort.Search(len(ends), func(p int) bool { return !ends[p].Before(spell.start) })
+		last := sort.Search(len(ends), func(p int) bool { return !ends[p].Before(spell.end) })
+		c.activeDelta[first]++
+		c.activeDelta[last]--
+		if first < len(starts) && !spell.start.Before(starts[first]) {
+			c.joins[first]++
+		}
+		if last < len(starts) && !spell.end.Before(starts[last]) {
+			c.terminations[last]++
+		}
+	}
+
+	report := &models.TimeSeriesReport{
+		Network:  query.Network,
+		From:     starts[0].Format(config.AsOfDateFormat),
+		To:       query.To.Format(config.AsOfDateFormat),
+		Interval: query.Interval,
+		GroupBy:  query.GroupBy,
+		Series:   []models.TimeSeries{},
+	}
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if query.GroupBy == "" && len(names) == 0 {
+		names = []string{""}
+		groups[""] = &counts{make([]int, len(starts)+1), make([]int, len(starts)), make([]int, len(starts))}
+	}
+	for _, name := range names {
+		c := groups[name]
+		series := models.TimeSeries{Group: name, Points: make([]models.TimeSeriesPoint, len(starts))}
+		active, seen := 0, false
+		for p := range starts {
+			active += c.activeDelta[p]
+			series.Points[p] = models.TimeSeriesPoint{
+				PeriodStart:  starts[p].Format(config.AsOfDateFormat),
+				PeriodEnd:    ends[p].Format(config.AsOfDateFormat),
+				Active:       active,
+				Joins:        c.joins[p],
+				Terminations: c.terminations[p],
+			}
+			seen = seen || active > 0 || c.joins[p] > 0 || c.terminations[p] > 0
+		}
+		if seen || query.GroupBy == "" {
+			report.Series = append(report.Series, series)
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"errors"
+	"kansas-healthcare-api/config"
+	"kansas-healthcare-api/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// newMembershipRepository mocks the Commercial history of a Wichita cardiologist who joined in
+// June 2023, a Wichita family doctor who left in February 2024, a Johnson County cardiologist
+// whose contract was renewed in January 2024 and a provider missing from the roster who came and
+// went in March 2024
+func newMembershipRepository() *MockRepository {
+	open := date(9999, time.December, 31)
+	mockRepo := new(MockRepository)
+	mockRepo.On("GetProviderNetworks").Return([]models.ProviderNetwork{
+		{ProviderID: "P1", NetworkID: "Commercial", EffectiveDate: date(2023, time.June, 15), TerminationDate: open},
+		{ProviderID: "P2", NetworkID: "Commercial", EffectiveDate: date(2020, time.January, 1), TerminationDate: date(2024, time.February, 1)},
+		{ProviderID: "P3", NetworkID: "Commercial", EffectiveDate: date(2024, time.January, 1), TerminationDate: open},
+		{ProviderID: "P3", NetworkID: "Commercial", EffectiveDate: date(2022, time.January, 1), TerminationDate: date(2024, time.January, 1)},
+		{ProviderID: "P4", NetworkID: "Commercial", EffectiveDate: date(2024, time.March, 10), TerminationDate: date(2024, time.March, 20)},
+		{ProviderID: "P5", NetworkID: "Medicare", EffectiveDate: date(2024, time.January, 1), TerminationDate: open},
+		// Ends the day it begins, so it was never in force
+		{ProviderID: "P6", NetworkID: "Commercial", EffectiveDate: date(2024, time.January, 1), TerminationDate: date(2024, time.January, 1)},
+	}, nil)
+	mockRepo.On("GetProviders").Return([]models.Provider{
+		{ProviderID: "P1", ProviderType: "Cardiology", County: "Sedgwick"},
+		{ProviderID: "P2", ProviderType: "Primary Care", County: "Sedgwick"},
+		{ProviderID: "P3", ProviderType: "Cardiology", County: "Johnson"},
+		{ProviderID: "P5", ProviderType: "Cardiology", County: "Johnson"},
+		{ProviderID: "P6", ProviderType: "Dermatology", County: "Ford"},
+	}, nil)
+	return mockRepo
+}
+
+func TestGetNetworkTimeSeries(t *testing.T) {
+	service := NewAnalyticsService(newMembershipRepository())
+
+	report, err := service.GetNetworkTimeSeries(models.TimeSeriesQuery{
+		Network: "Commercial", From: date(2024, time.January, 1), To: date(2024, time.March, 31), Interval: config.IntervalMonth,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "2024-01-01", report.From)
+	assert.Equal(t, "2024-03-31", report.To)
+	assert.Equal(t, []models.TimeSeries{{Points: []models.TimeSeriesPoint{
+		{PeriodStart: "2024-01-01", PeriodEnd: "2024-01-31", Active: 3},
+		{PeriodStart: "2024-02-01", PeriodEnd: "2024-02-29", Active: 2, Terminations: 1},
+		{PeriodStart: "2024-03-01", PeriodEnd: "2024-03-31", Active: 2, Joins: 1, Terminations: 1},
+	}}}, report.Series)
+
+	report, err = service.GetNetworkTimeSeries(models.TimeSeriesQuery{
+		Network: "Commercial", From: date(2024, time.January, 1), To: date(2024, time.March, 31), Interval: config.IntervalMonth, GroupBy: config.GroupByCounty,
+	})
+	require.NoError(t, err)
+	require.Len(t, report.Series, 3)
+	assert.Equal(t, "Johnson", report.Series[0].Group)
+	assert.Equal(t, []int{1, 1, 1}, activeCounts(report.Series[0]))
+	assert.Equal(t, "Sedgwick", report.Series[1].Group)
+	assert.Equal(t, []int{2, 1, 1}, activeCounts(report.Series[1]))
+	assert.Equal(t, 1, report.Series[1].Points[1].Terminations)
+	assert.Equal(t, "Unknown", report.Series[2].Group)
+	assert.Equal(t, []int{0, 0, 0}, activeCounts(report.Series[2]))
+	assert.Equal(t, 1, report.Series[2].Points[2].Joins)
+
+	report, err = service.GetNetworkTimeSeries(models.TimeSeriesQuery{
+		Network: "Commercial", From: date(2024, time.January, 1), To: date(2024, time.March, 31), Interval: config.IntervalMonth, GroupBy: config.GroupBySpecialty,
+	})
+	require.NoError(t, err)
+	require.Len(t, report.Series, 3)
+	assert.Equal(t, "Cardiology", report.Series[0].Group)
+	assert.Equal(t, []int{2, 2, 2}, activeCounts(report.Series[0]))
+	assert.Equal(t, "Primary Care", report.Series[1].Group)
+	assert.Equal(t, []int{1, 0, 0}, activeCounts(report.Series[1]))
+}
+
+func TestGetNetworkTimeSeriesPeriods(t *testing.T) {
+	service := NewAnalyticsService(newMembershipRepository())
+
+	// Periods are whole quarters, starting with the one containing from
+	report, err := service.GetNetworkTimeSeries(models.TimeSeriesQuery{
+		Network: "Commercial", From: date(2023, time.May, 10), To: date(2024, time.March, 31), Interval: config.IntervalQuarter,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "2023-04-01", report.From)
+	assert.Equal(t, []models.TimeSeriesPoint{
+		{PeriodStart: "2023-04-01", PeriodEnd: "2023-06-30", Active: 3, Joins: 1},
+		{PeriodStart: "2023-07-01", PeriodEnd: "2023-09-30", Active: 3},
+		{PeriodStart: "2023-10-01", PeriodEnd: "2023-12-31", Active: 3},
+		{PeriodStart: "2024-01-01", PeriodEnd: "2024-03-31", Active: 2, Joins: 1, Terminations: 2},
+	}, report.Series[0].Points)
+
+	// Except the last, which stops at to
+	report, err = service.GetNetworkTimeSeries(models.TimeSeriesQuery{
+		Network: "Commercial", From: date(2022, time.March, 1), To: date(2024, time.February, 15), Interval: config.IntervalYear,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []models.TimeSeriesPoint{
+		{PeriodStart: "2022-01-01", PeriodEnd: "2022-12-31", Active: 2, Joins: 1},
+		{PeriodStart: "2023-01-01", PeriodEnd: "2023-12-31", Active: 3, Joins: 1},
+		{PeriodStart: "2024-01-01", PeriodEnd: "2024-02-15", Active: 2, Terminations: 1},
+	}, report.Series[0].Points)
+}
+
+func TestGetNetworkTimeSeriesInvalidQueries(t *testing.T) {
+	service := NewAnalyticsService(newMembershipRepository())
+	valid := models.TimeSeriesQuery{Network: "Commercial", From: date(2024, time.January, 1), To: date(2024, time.March, 31), Interval: config.IntervalMonth}
+
+	for _, test := range []struct {
+		change func(*models.TimeSeriesQuery)
+		field  string
+	}{
+		{func(q *models.TimeSeriesQuery) { q.Interval = "week" }, "interval"},
+		{func(q *models.TimeSeriesQuery) { q.GroupBy = "network" }, "group_by"},
+		{func(q *models.TimeSeriesQuery) { q.From = date(2024, time.April, 1) }, "from"},
+		{func(q *models.TimeSeriesQuery) { q.From = date(1900, time.January, 1) }, "from"},
+	} {
+		query := valid
+		test.change(&query)
+		_, err := service.GetNetworkTimeSeries(query)
+		var validationErr *ValidationError
+		require.True(t, errors.As(err, &validationErr), test.field)
+		assert.Equal(t, test.field, validationErr.Field)
+	}
+
+	valid.Network = "Medicaid"
+	_, err := service.GetNetworkTimeSeries(valid)
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
+
+func activeCounts(series models.TimeSeries) []int {
+	counts := make([]int, len(series.Points))
+	for i, point := range series.Points {
+		counts[i] = point.Active
+	}
+	return counts
+}
@@ -4,27 +4,37 @@ package main
 
 import (
 	"fmt"
+	"kansas-healthcare-api/config"
 	"kansas-healthcare-api/data"
+	"kansas-healthcare-api/models"
+	"kansas-healthcare-api/services"
 )
 
 func main() {
 	fmt.Println("Testing Terminated Network Analysis...")
 	
-	repo := data.NewJSONRepository()
+	repo, err := data.NewJSONRepository(data.LoadOptions{})
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
 	
 	// Test the new county terminated network analysis
-	totProviders, termCount, err := repo.GetCountyTerminatedNetworkCount("Sedgwick", "Commercial")
+	from, to := config.TerminatedAnalysisTimeRange(config.Today())
+	criteria := models.TerminationCriteria{AsOf: config.Today(), From: from, To: to, Reasons: []string{config.LeftNetworkReason}}
+	result, err := services.NewAnalyticsService(repo).GetCountyTerminatedNetworkAnalysis("Sedgwick", "Commercial", criteria)
 	if err != nil {
 		fmt.Printf("Error: %v\n", err)
 		return
 	}
 	
 	fmt.Printf("County: Sedgwick\n")
-	fmt.Printf("Total Active Providers: %d\n", totProviders)
-	fmt.Printf("Terminated Network Count: %d\n", termCount)
+	fmt.Printf("Total Active Providers: %d\n", result.TotalActiveProviders)
+	fmt.Printf("Terminated Network Count: %d\n", result.TermNetworkCount)
 	
-	// Calculate percentage as per your formula: (TotProvbyCounty - (TotProvbyCounty - TermNetworkCount))/100
-	// This simplifies to: TermNetworkCount/100
-	percentage := float64(termCount) / 100.0
-	fmt.Printf("Percentage Terminated: %.2f%%\n", percentage)
-}
\ No newline at end of file
+	// Numerator / Denominator * 100 over the providers the denominator basis names
+	fmt.Printf("Numerator: %d\n", result.Numerator)
+	fmt.Printf("Denominator (%s): %d\n", result.DenominatorBasis, result.Denominator)
+	fmt.Printf("Percentage Terminated: %.2f%%\n", result.PercentageTerminated)
+	fmt.Printf("By Reason: %v\n", result.ByReason)
+}